@@ -0,0 +1,177 @@
+// Command apikeys creates, lists and revokes API keys and shows their usage.
+//
+//	apikeys create -name NAME [-scopes news,summarize] [-rate N] [-daily N] [-summarize-rate N] [-summarize-daily N]
+//	apikeys list
+//	apikeys revoke ID
+//	apikeys usage [-days N] ID
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/auth"
+	"github.com/akhlexe/stocknews-api/internal/storage"
+	"github.com/joho/godotenv"
+	"github.com/rs/zerolog"
+)
+
+const usage = `usage:
+  apikeys create -name NAME [-scopes news,summarize] [-rate N] [-daily N] [-summarize-rate N] [-summarize-daily N]
+  apikeys list
+  apikeys revoke ID
+  apikeys usage [-days N] ID`
+
+func main() {
+	_ = godotenv.Load()
+	zerolog.SetGlobalLevel(zerolog.WarnLevel)
+
+	if len(os.Args) < 2 {
+		fail(usage)
+	}
+
+	store, err := storage.NewPostgresStorage(connectionString())
+	if err != nil {
+		fail("Failed to connect to Postgres: %v", err)
+	}
+	defer store.Close()
+
+	service := auth.NewService(store, auth.Options{})
+	ctx := context.Background()
+
+	switch os.Args[1] {
+	case "create":
+		create(ctx, service, os.Args[2:])
+	case "list":
+		list(ctx, service)
+	case "revoke":
+		revoke(ctx, service, os.Args[2:])
+	case "usage":
+		showUsage(ctx, service, os.Args[2:])
+	default:
+		fail(usage)
+	}
+}
+
+func create(ctx context.Context, service *auth.Service, args []string) {
+	flags := flag.NewFlagSet("create", flag.ExitOnError)
+	name := flags.String("name", "", "who the key is for")
+	scopeList := flags.String("scopes", auth.ScopeNews, "comma-separated scopes: news, summarize")
+	rate := flags.Int("rate", 60, "news requests per minute (0 for unlimited)")
+	daily := flags.Int("daily", 10000, "news requests per day (0 for unlimited)")
+	summarizeRate := flags.Int("summarize-rate", 5, "summarization requests per minute (0 for unlimited)")
+	summarizeDaily := flags.Int("summarize-daily", 200, "summarization requests per day (0 for unlimited)")
+	flags.Parse(args)
+
+	if strings.TrimSpace(*name) == "" {
+		fail("-name is required")
+	}
+	names, err := auth.ParseScopes(*scopeList)
+	if err != nil {
+		fail("%v", err)
+	}
+
+	scopes := make(map[string]auth.Limit)
+	for _, scope := range names {
+		switch scope {
+		case auth.ScopeNews:
+			scopes[scope] = auth.Limit{PerMinute: *rate, Daily: *daily}
+		case auth.ScopeSummarize:
+			scopes[scope] = auth.Limit{PerMinute: *summarizeRate, Daily: *summarizeDaily}
+		}
+	}
+
+	key, secret, err := service.Create(ctx, strings.TrimSpace(*name), scopes)
+	if err != nil {
+		fail("Failed to create API key: %v", err)
+	}
+
+	fmt.Printf("Created API key %s for %s\n", key.ID, key.Name)
+	fmt.Printf("Secret (shown once): %s\n", secret)
+}
+
+func list(ctx context.Context, service *auth.Service) {
+	keys, err := service.List(ctx)
+	if err != nil {
+		fail("Failed to list API keys: %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tSCOPES\tCREATED\tREVOKED")
+	for _, key := range keys {
+		var scopes []string
+		for _, name := range key.ScopeNames() {
+			limit := key.Scopes[name]
+			scopes = append(scopes, fmt.Sprintf("%s(%d/min, %d/day)", name, limit.PerMinute, limit.Daily))
+		}
+		revoked := "-"
+		if key.Revoked() {
+			revoked = key.RevokedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", key.ID, key.Name, strings.Join(scopes, " "), key.CreatedAt.Format(time.RFC3339), revoked)
+	}
+	w.Flush()
+}
+
+func revoke(ctx context.Context, service *auth.Service, args []string) {
+	if len(args) != 1 {
+		fail(usage)
+	}
+
+	revoked, err := service.Revoke(ctx, args[0])
+	if err != nil {
+		fail("Failed to revoke API key: %v", err)
+	}
+	if !revoked {
+		fail("No active API key %s", args[0])
+	}
+	fmt.Printf("Revoked API key %s\n", args[0])
+}
+
+func showUsage(ctx context.Context, service *auth.Service, args []string) {
+	flags := flag.NewFlagSet("usage", flag.ExitOnError)
+	days := flags.Int("days", 30, "number of days to show")
+	flags.Parse(args)
+	if flags.NArg() != 1 || *days < 1 {
+		fail(usage)
+	}
+
+	since := time.Now().UTC().AddDate(0, 0, -(*days - 1))
+	counts, err := service.Usage(ctx, flags.Arg(0), since)
+	if err != nil {
+		fail("Failed to retrieve usage: %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DAY\tSCOPE\tENDPOINT\tREQUESTS")
+	for _, u := range counts {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", u.Day.Format("2006-01-02"), u.Scope, u.Endpoint, u.Requests)
+	}
+	w.Flush()
+}
+
+func connectionString() string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		getEnvOrDefault("POSTGRES_HOST", "localhost"),
+		getEnvOrDefault("POSTGRES_PORT", "5434"),
+		getEnvOrDefault("POSTGRES_USER", "postgres"),
+		getEnvOrDefault("POSTGRES_PASSWORD", "postgres"),
+		getEnvOrDefault("POSTGRES_DB", "stocknews"))
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func fail(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
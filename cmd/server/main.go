@@ -1,14 +1,31 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"net/smtp"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/akhlexe/stocknews-api/internal/ai"
+	"github.com/akhlexe/stocknews-api/internal/alerts"
 	"github.com/akhlexe/stocknews-api/internal/api"
+	"github.com/akhlexe/stocknews-api/internal/auth"
 	"github.com/akhlexe/stocknews-api/internal/cache"
+	"github.com/akhlexe/stocknews-api/internal/entities"
+	"github.com/akhlexe/stocknews-api/internal/jobs"
 	"github.com/akhlexe/stocknews-api/internal/news"
+	"github.com/akhlexe/stocknews-api/internal/ratelimit"
+	"github.com/akhlexe/stocknews-api/internal/safehttp"
+	"github.com/akhlexe/stocknews-api/internal/semantic"
+	"github.com/akhlexe/stocknews-api/internal/sentiment"
 	"github.com/akhlexe/stocknews-api/internal/storage"
+	"github.com/akhlexe/stocknews-api/internal/stream"
+	"github.com/akhlexe/stocknews-api/internal/watchlists"
+	"github.com/akhlexe/stocknews-api/internal/webhooks"
 	"github.com/joho/godotenv"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/rs/zerolog"
@@ -33,12 +50,142 @@ func main() {
 	}
 	defer postgresStorage.Close()
 
-	cache := cache.NewPersistentCache(postgresStorage, 10*time.Minute)
+	articleCache := cache.NewPersistentCache(postgresStorage, 10*time.Minute)
 	apiKey := os.Getenv("ALPHAVANTAGE_API_KEY")
-	fetcher := news.NewAlphaVantageFetcher(apiKey, cache)
+	fetcher := news.NewAlphaVantageFetcher(apiKey, articleCache)
 	multiFetcher := news.NewMultiFetcher(fetcher)
 
-	server := api.NewServer(multiFetcher)
+	symbols := entities.DefaultDirectory()
+	if path := os.Getenv("SYMBOL_DIRECTORY"); path != "" {
+		symbols, err = entities.LoadDirectory(path)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to load symbol directory")
+		}
+	}
+	multiFetcher.Enrichers = []news.Enricher{entities.NewExtractor(symbols), sentiment.NewAnalyzer()}
+
+	var summaries *ai.SummaryService
+	// Summary jobs and inline summaries share the same SUMMARY_WORKERS model slots.
+	workers, err := strconv.Atoi(getEnvOrDefault("SUMMARY_WORKERS", strconv.Itoa(jobs.DefaultWorkers)))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid SUMMARY_WORKERS")
+	}
+	summarizer, err := CreateSummarizer()
+	if err != nil {
+		log.Warn().Err(err).Msg("AI summarization disabled")
+	} else {
+		summaryTTL, err := time.ParseDuration(getEnvOrDefault("SUMMARY_CACHE_TTL", "1h"))
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid SUMMARY_CACHE_TTL")
+		}
+		budgets, err := ai.ParseTokenBudgets(os.Getenv("AI_CONTEXT_TOKENS"))
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid AI_CONTEXT_TOKENS")
+		}
+		maxChunks, err := strconv.Atoi(getEnvOrDefault("AI_MAX_CHUNKS", strconv.Itoa(ai.DefaultMaxChunks)))
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid AI_MAX_CHUNKS")
+		}
+
+		pins, err := ai.ParsePromptPins(os.Getenv("AI_PROMPT_VERSIONS"))
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid AI_PROMPT_VERSIONS")
+		}
+		prompts, err := ai.LoadPromptLibrary(os.Getenv("AI_PROMPT_DIR"), pins)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to load prompt templates")
+		}
+
+		summaries = ai.NewSummaryService(summarizer, cache.NewSummaryCache(postgresStorage, summaryTTL), ai.SummaryOptions{
+			ContextTokens: ai.ContextTokensForModel(summarizer.Model(), budgets),
+			MaxChunks:     maxChunks,
+			Prompts:       prompts,
+			Concurrency:   workers,
+		})
+	}
+
+	server := api.NewServer(multiFetcher, summaries)
+
+	if os.Getenv("AUTH_DISABLED") == "true" {
+		log.Warn().Msg("API key authentication disabled: every route is public")
+	} else {
+		keys := auth.NewService(postgresStorage, auth.Options{})
+		go keys.Run(context.Background())
+		server.Auth = keys
+	}
+
+	server.RateLimits = createRateLimits(postgresStorage)
+
+	if proxies := os.Getenv("TRUSTED_PROXIES"); proxies != "" {
+		server.TrustedProxies = strings.Split(proxies, ",")
+		log.Info().Strs("proxies", server.TrustedProxies).Msg("Trusting X-Forwarded-For from proxies")
+	}
+
+	if summaries != nil {
+		queueSize, err := strconv.Atoi(getEnvOrDefault("SUMMARY_QUEUE_SIZE", strconv.Itoa(jobs.DefaultQueueSize)))
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid SUMMARY_QUEUE_SIZE")
+		}
+
+		queue := jobs.NewQueue(multiFetcher, summaries, jobs.Options{Workers: workers, QueueSize: queueSize})
+		queue.Start(context.Background())
+		server.Jobs = queue
+	}
+
+	if embeddingURL := getEnvOrDefault("EMBEDDING_URL", os.Getenv("OLLAMA_URL")); embeddingURL != "" {
+		embedder, err := ai.NewOllamaEmbedder(ai.Config{
+			BaseURL: embeddingURL,
+			Model:   getEnvOrDefault("EMBEDDING_MODEL", ai.DefaultEmbeddingModel),
+		})
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to configure embeddings")
+		}
+
+		search := semantic.NewService(embedder, postgresStorage, semantic.DefaultQueueSize)
+		if err := search.Load(context.Background()); err != nil {
+			log.Error().Err(err).Msg("Failed to load stored embeddings")
+		}
+		search.Start(context.Background())
+
+		multiFetcher.Enrichers = append(multiFetcher.Enrichers, search)
+		server.Semantic = search
+	} else {
+		log.Warn().Msg("Semantic search disabled: set EMBEDDING_URL or OLLAMA_URL")
+	}
+
+	refreshInterval, err := time.ParseDuration(getEnvOrDefault("STREAM_REFRESH_INTERVAL", stream.DefaultRefreshInterval.String()))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid STREAM_REFRESH_INTERVAL")
+	}
+	hub := stream.NewHub(stream.Options{RefreshInterval: refreshInterval})
+	multiFetcher.Observers = append(multiFetcher.Observers, hub)
+	go hub.Run(context.Background(), multiFetcher)
+	server.Stream = hub
+	if origins := os.Getenv("STREAM_ALLOWED_ORIGINS"); origins != "" {
+		server.StreamOrigins = strings.Split(origins, ",")
+	}
+
+	webhookService := webhooks.NewService(postgresStorage, hub, webhooks.Options{})
+	if err := webhookService.Load(context.Background()); err != nil {
+		log.Error().Err(err).Msg("Failed to load webhook subscriptions")
+	}
+	go webhookService.Run(context.Background())
+	server.Webhooks = webhookService
+
+	alertEngine := alerts.NewEngine(postgresStorage, hub, createNotifiers(), alerts.Options{})
+	if err := alertEngine.Load(context.Background()); err != nil {
+		log.Error().Err(err).Msg("Failed to load alert rules")
+	}
+	go alertEngine.Run(context.Background())
+	server.Alerts = alertEngine
+
+	watchlistService := watchlists.NewService(postgresStorage, hub)
+	if err := watchlistService.Load(context.Background()); err != nil {
+		log.Error().Err(err).Msg("Failed to load watchlists")
+	}
+	go watchlistService.Run(context.Background())
+	server.Watchlists = watchlistService
+
 	server.Run()
 }
 
@@ -69,6 +216,104 @@ func CreatePostgresStorage() (*storage.PostgresStorage, error) {
 	return storage.NewPostgresStorage(connStr)
 }
 
+func CreateSummarizer() (ai.Summarizer, error) {
+	temperature, err := strconv.ParseFloat(getEnvOrDefault("AI_TEMPERATURE", "0.2"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AI_TEMPERATURE: %w", err)
+	}
+
+	timeout, err := time.ParseDuration(getEnvOrDefault("AI_TIMEOUT", ai.DefaultTimeout.String()))
+	if err != nil {
+		return nil, fmt.Errorf("invalid AI_TIMEOUT: %w", err)
+	}
+
+	cfg := ai.Config{
+		Provider:    getEnvOrDefault("AI_PROVIDER", ai.ProviderOllama),
+		BaseURL:     getEnvOrDefault("AI_BASE_URL", os.Getenv("OLLAMA_URL")),
+		Model:       getEnvOrDefault("AI_MODEL", ai.DefaultModel),
+		APIKey:      os.Getenv("AI_API_KEY"),
+		Temperature: temperature,
+		Timeout:     timeout,
+	}
+
+	log.Info().
+		Str("provider", cfg.Provider).
+		Str("base_url", cfg.BaseURL).
+		Str("model", cfg.Model).
+		Dur("timeout", cfg.Timeout).
+		Msg("Configuring AI summarizer")
+
+	return ai.NewSummarizer(cfg)
+}
+
+// createRateLimits configures rate limiting from RATE_LIMIT_* settings, kept in
+// memory unless RATE_LIMIT_BACKEND is postgres.
+func createRateLimits(store *storage.PostgresStorage) *api.RateLimits {
+	parse := func(key, defaultValue string) ratelimit.Limit {
+		limit, err := ratelimit.ParseLimit(getEnvOrDefault(key, defaultValue))
+		if err != nil {
+			log.Fatal().Err(err).Msgf("Invalid %s", key)
+		}
+		return limit
+	}
+
+	limits := &api.RateLimits{
+		News:      parse("RATE_LIMIT_NEWS", "60/1m"),
+		Summarize: parse("RATE_LIMIT_SUMMARIZE", "10/1m"),
+		Search:    parse("RATE_LIMIT_SEARCH", "30/1m"),
+		Default:   parse("RATE_LIMIT_DEFAULT", "120/1m"),
+	}
+
+	switch backend := getEnvOrDefault("RATE_LIMIT_BACKEND", "memory"); backend {
+	case "memory":
+		limits.Limiter = ratelimit.NewLimiter(ratelimit.NewMemoryStore())
+	case "postgres":
+		limits.Limiter = ratelimit.NewLimiter(store)
+	default:
+		log.Fatal().Str("backend", backend).Msg("Invalid RATE_LIMIT_BACKEND")
+	}
+
+	log.Info().
+		Stringer("news", limits.News).
+		Stringer("summarize", limits.Summarize).
+		Stringer("search", limits.Search).
+		Stringer("default", limits.Default).
+		Msg("Rate limiting enabled")
+	return limits
+}
+
+// createNotifiers returns the alert channels. Email is only available when
+// SMTP_ADDR is set.
+func createNotifiers() map[string]alerts.Notifier {
+	client := safehttp.NewClient(10 * time.Second)
+	notifiers := map[string]alerts.Notifier{
+		alerts.ChannelWebhook: &alerts.WebhookNotifier{Client: client},
+		alerts.ChannelSlack:   &alerts.SlackNotifier{Client: client},
+	}
+
+	addr := os.Getenv("SMTP_ADDR")
+	if addr == "" {
+		log.Warn().Msg("Email alerts disabled: set SMTP_ADDR")
+		return notifiers
+	}
+
+	var auth smtp.Auth
+	if username := os.Getenv("SMTP_USERNAME"); username != "" {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid SMTP_ADDR")
+		}
+		auth = smtp.PlainAuth("", username, os.Getenv("SMTP_PASSWORD"), host)
+	}
+
+	notifiers[alerts.ChannelEmail] = &alerts.EmailNotifier{
+		Addr: addr,
+		From: getEnvOrDefault("SMTP_FROM", "alerts@localhost"),
+		Auth: auth,
+	}
+	return notifiers
+}
+
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -0,0 +1,123 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/akhlexe/stocknews-api/internal/apperrors"
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// MaxQuestionChars caps the length of a question.
+	MaxQuestionChars = 500
+
+	// noAnswerMarker is what the model is told to reply when the articles
+	// don't answer the question.
+	noAnswerMarker = "NO_ANSWER"
+
+	// NoAnswerText replaces noAnswerMarker in answers sent to clients.
+	NoAnswerText = "The available articles do not answer this question."
+)
+
+var citationPattern = regexp.MustCompile(`\[(\d+)\]`)
+
+// AnswerRequest is a question about a ticker and the articles retrieved for it,
+// most relevant first.
+type AnswerRequest struct {
+	Ticker   string
+	Question string
+	Articles []models.Article
+}
+
+// Answer is the model's answer. Answered is false when the articles did not
+// contain the answer; Text then holds NoAnswerText.
+type Answer struct {
+	Text             string
+	Answered         bool
+	Citations        []Citation
+	PromptVersion    string
+	ArticlesIncluded int
+	Warnings         []string
+}
+
+// Citation is an article the answer refers to. Index is the 1-based number
+// the model used.
+type Citation struct {
+	Index   int
+	Article models.Article
+}
+
+// Answer asks the model to answer a question from the given articles only.
+// Articles that don't fit in a single prompt are left out, least relevant
+// first.
+func (s *SummaryService) Answer(ctx context.Context, req AnswerRequest) (Answer, error) {
+	prompt, ok := s.opts.Prompts.Get(PromptAnswer)
+	if !ok {
+		return Answer{}, fmt.Errorf("%w: no answer prompt", apperrors.ErrConfiguration)
+	}
+
+	question := sanitizeText(req.Question, MaxQuestionChars)
+
+	budget := s.promptBudget(SummaryRequest{Ticker: req.Ticker}, prompt) - EstimateTokens(question)
+	chunks := chunkArticles(sanitizeArticles(req.Articles), budget, 1)
+
+	var included []models.Article
+	if len(chunks) > 0 {
+		included = chunks[0]
+	}
+
+	result := Answer{PromptVersion: prompt.ID(), ArticlesIncluded: len(included)}
+	if len(included) == 0 {
+		result.Text = NoAnswerText
+		return result, nil
+	}
+
+	data := s.promptData(SummaryRequest{Ticker: req.Ticker}, included, articleIDs(req.Articles), nil)
+	data.Question = question
+	data.NoAnswer = noAnswerMarker
+
+	text, err := s.generateFromTemplate(ctx, prompt, data, nil)
+	if err != nil {
+		return Answer{}, err
+	}
+	text = strings.TrimSpace(text)
+
+	if text == "" || strings.Contains(text, noAnswerMarker) {
+		result.Text = NoAnswerText
+		return result, nil
+	}
+
+	result.Text = text
+	result.Answered = true
+	// Citations point into the original articles, not the sanitized copies.
+	result.Citations = parseCitations(text, req.Articles[:len(included)])
+	result.Warnings = CheckSummary(text, req.Ticker, included)
+	if len(result.Warnings) > 0 {
+		log.Warn().Strs("warnings", result.Warnings).Str("ticker", req.Ticker).Msg("Answer mentions content not found in articles")
+	}
+
+	return result, nil
+}
+
+// parseCitations returns the articles cited as [n] in text, in order of first
+// citation. References to articles that don't exist are ignored.
+func parseCitations(text string, articles []models.Article) []Citation {
+	var citations []Citation
+	seen := make(map[int]bool)
+
+	for _, m := range citationPattern.FindAllStringSubmatch(text, -1) {
+		index, err := strconv.Atoi(m[1])
+		if err != nil || index < 1 || index > len(articles) || seen[index] {
+			continue
+		}
+		seen[index] = true
+		citations = append(citations, Citation{Index: index, Article: articles[index-1]})
+	}
+
+	return citations
+}
@@ -0,0 +1,55 @@
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnswer(t *testing.T) {
+	summarizer := &scriptedSummarizer{responses: []string{"The recall covers brakes [2], and revenue beat estimates [1][2][7]."}}
+	service := NewSummaryService(summarizer, nil, SummaryOptions{})
+
+	answer, err := service.Answer(context.Background(), AnswerRequest{
+		Ticker:   "TEST",
+		Question: "What is the recall about?",
+		Articles: structuredArticles,
+	})
+	assert.NoError(t, err)
+
+	assert.True(t, answer.Answered)
+	assert.Equal(t, "answer@v1", answer.PromptVersion)
+	assert.Equal(t, 2, answer.ArticlesIncluded)
+	assert.Equal(t, []Citation{{Index: 2, Article: structuredArticles[1]}, {Index: 1, Article: structuredArticles[0]}}, answer.Citations)
+
+	prompt := summarizer.prompts[0]
+	assert.Contains(t, prompt, "<question>\nWhat is the recall about?\n</question>")
+	assert.Contains(t, prompt, "<article id=\"2\">\nRecall announced: A product recall was announced.\n</article>")
+	assert.Contains(t, prompt, noAnswerMarker)
+}
+
+func TestAnswerNotInArticles(t *testing.T) {
+	summarizer := &scriptedSummarizer{responses: []string{" NO_ANSWER\n"}}
+	service := NewSummaryService(summarizer, nil, SummaryOptions{})
+
+	answer, err := service.Answer(context.Background(), AnswerRequest{Ticker: "TEST", Question: "Who is the CEO?", Articles: structuredArticles})
+	assert.NoError(t, err)
+
+	assert.False(t, answer.Answered)
+	assert.Equal(t, NoAnswerText, answer.Text)
+	assert.Empty(t, answer.Citations)
+}
+
+func TestAnswerWithoutArticles(t *testing.T) {
+	summarizer := &scriptedSummarizer{}
+	service := NewSummaryService(summarizer, nil, SummaryOptions{})
+
+	answer, err := service.Answer(context.Background(), AnswerRequest{Ticker: "TEST", Question: "Why?", Articles: []models.Article{}})
+	assert.NoError(t, err)
+
+	assert.False(t, answer.Answered)
+	assert.Equal(t, NoAnswerText, answer.Text)
+	assert.Empty(t, summarizer.prompts, "the model is not called")
+}
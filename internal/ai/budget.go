@@ -0,0 +1,151 @@
+package ai
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/akhlexe/stocknews-api/internal/apperrors"
+	"github.com/akhlexe/stocknews-api/internal/models"
+)
+
+const (
+	// DefaultContextTokens is used for models without a known context size.
+	DefaultContextTokens = 4096
+
+	// DefaultMaxChunks caps the number of map calls per summary. Articles that
+	// don't fit are left out and reported as not included.
+	DefaultMaxChunks = 8
+
+	// charsPerToken is a rough average for English text with llama-style tokenizers.
+	charsPerToken = 4
+)
+
+// knownContextTokens lists context window sizes for common local models.
+var knownContextTokens = map[string]int{
+	"llama3":   8192,
+	"llama3.1": 8192,
+	"mistral":  8192,
+	"gemma":    8192,
+	"phi3":     4096,
+	"qwen2":    8192,
+}
+
+// EstimateTokens approximates the number of tokens in text without a tokenizer.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
+
+// ParseTokenBudgets parses a "model=tokens,model=tokens" list, as used by the
+// AI_CONTEXT_TOKENS environment variable.
+func ParseTokenBudgets(value string) (map[string]int, error) {
+	budgets := make(map[string]int)
+	if strings.TrimSpace(value) == "" {
+		return budgets, nil
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		model, tokens, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid token budget %q", apperrors.ErrConfiguration, entry)
+		}
+
+		n, err := strconv.Atoi(strings.TrimSpace(tokens))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("%w: invalid token budget %q", apperrors.ErrConfiguration, entry)
+		}
+		budgets[strings.TrimSpace(model)] = n
+	}
+
+	return budgets, nil
+}
+
+// ContextTokensForModel returns the context size for model, preferring
+// overrides, then known defaults. Tags such as "llama3:8b" match their base name.
+func ContextTokensForModel(model string, overrides map[string]int) int {
+	base, _, _ := strings.Cut(model, ":")
+
+	for _, name := range []string{model, base} {
+		if n, ok := overrides[name]; ok {
+			return n
+		}
+	}
+	for _, name := range []string{model, base} {
+		if n, ok := knownContextTokens[name]; ok {
+			return n
+		}
+	}
+
+	return DefaultContextTokens
+}
+
+// chunkArticles splits articles into groups whose combined text fits in
+// budget tokens, keeping upstream order. Articles longer than the budget are
+// truncated. At most maxChunks groups are returned; the rest are dropped.
+func chunkArticles(articles []models.Article, budget, maxChunks int) [][]models.Article {
+	var chunks [][]models.Article
+	var current []models.Article
+	used := 0
+
+	for _, a := range articles {
+		a = truncateArticle(a, budget)
+		cost := EstimateTokens(CombineArticles([]models.Article{a}))
+
+		if len(current) > 0 && used+cost > budget {
+			chunks = append(chunks, current)
+			if len(chunks) == maxChunks {
+				return chunks
+			}
+			current, used = nil, 0
+		}
+
+		current = append(current, a)
+		used += cost
+	}
+
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+
+	return chunks
+}
+
+// truncateArticle shortens the summary so the article fits in budget tokens.
+func truncateArticle(a models.Article, budget int) models.Article {
+	overflow := EstimateTokens(CombineArticles([]models.Article{a})) - budget
+	if overflow <= 0 {
+		return a
+	}
+
+	runes := []rune(a.Summary)
+	keep := len(runes) - overflow*charsPerToken
+	if keep < 0 {
+		keep = 0
+	}
+	a.Summary = string(runes[:keep])
+	return a
+}
+
+// chunkTexts groups partial summaries for the reduce step, like chunkArticles.
+func chunkTexts(texts []string, budget int) [][]string {
+	var chunks [][]string
+	var current []string
+	used := 0
+
+	for _, t := range texts {
+		cost := EstimateTokens(t)
+		if len(current) > 0 && used+cost > budget {
+			chunks = append(chunks, current)
+			current, used = nil, 0
+		}
+		current = append(current, t)
+		used += cost
+	}
+
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+
+	return chunks
+}
@@ -0,0 +1,104 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/akhlexe/stocknews-api/internal/apperrors"
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultEmbeddingModel is a small embedding model available in Ollama.
+const DefaultEmbeddingModel = "nomic-embed-text"
+
+// Embedder turns text into a vector whose distance to other vectors reflects
+// similarity in meaning.
+type Embedder interface {
+	Embed(ctx context.Context, text string) ([]float32, error)
+
+	// Model returns the name of the embedding model. Vectors from different
+	// models are not comparable.
+	Model() string
+}
+
+type OllamaEmbeddingRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+}
+
+type OllamaEmbeddingResponse struct {
+	Embedding []float32 `json:"embedding"`
+}
+
+// OllamaEmbedder talks to Ollama's /api/embeddings endpoint. Only BaseURL,
+// Model and Timeout of its Config are used.
+type OllamaEmbedder struct {
+	cfg    Config
+	client *http.Client
+}
+
+func NewOllamaEmbedder(cfg Config) (*OllamaEmbedder, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("%w: missing embedding base URL", apperrors.ErrConfiguration)
+	}
+	if cfg.Model == "" {
+		cfg.Model = DefaultEmbeddingModel
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+
+	return &OllamaEmbedder{
+		cfg:    cfg,
+		client: &http.Client{},
+	}, nil
+}
+
+func (o *OllamaEmbedder) Model() string {
+	return o.cfg.Model
+}
+
+func (o *OllamaEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	ctx, cancel := context.WithTimeout(ctx, o.cfg.Timeout)
+	defer cancel()
+
+	body, err := json.Marshal(OllamaEmbeddingRequest{Model: o.cfg.Model, Prompt: text})
+	if err != nil {
+		return nil, fmt.Errorf("%w: error encoding Ollama request: %v", apperrors.ErrInternal, err)
+	}
+
+	url := strings.TrimRight(o.cfg.BaseURL, "/") + "/api/embeddings"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: error creating Ollama request: %v", apperrors.ErrInternal, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	log.Debug().Str("model", o.cfg.Model).Msg("Calling Ollama embeddings")
+
+	resp, err := doRequest(ctx, o.client, req, "Ollama")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result OllamaEmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("%w: error decoding Ollama response: %v", apperrors.ErrInternal, err)
+	}
+	if len(result.Embedding) == 0 {
+		return nil, fmt.Errorf("%w: Ollama returned an empty embedding", apperrors.ErrInternal)
+	}
+
+	return result.Embedding, nil
+}
+
+// EmbeddingText is the article text that is embedded for semantic search.
+func EmbeddingText(a models.Article) string {
+	return a.Title + "\n" + a.Summary
+}
@@ -4,17 +4,26 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
-	"os"
+	"strings"
+
+	"github.com/akhlexe/stocknews-api/internal/apperrors"
+	"github.com/rs/zerolog/log"
 )
 
 type OllamaRequest struct {
-	Model  string `json:"model"`
-	Prompt string `json:"prompt"`
-	Stream bool   `json:"stream"`
+	Model   string          `json:"model"`
+	Prompt  string          `json:"prompt"`
+	Stream  bool            `json:"stream"`
+	Format  json.RawMessage `json:"format,omitempty"`
+	Options OllamaOptions   `json:"options"`
+}
+
+type OllamaOptions struct {
+	Temperature float64 `json:"temperature"`
 }
 
 type OllamaResponse struct {
@@ -22,72 +31,113 @@ type OllamaResponse struct {
 	Done     bool   `json:"done"`
 }
 
-func GenerateSummary(prompt string) (string, error) {
+// OllamaSummarizer talks to Ollama's /api/generate endpoint.
+type OllamaSummarizer struct {
+	cfg    Config
+	client *http.Client
+}
 
-	ollamaUrl := GetOllamaUrl()
+func NewOllamaSummarizer(cfg Config) *OllamaSummarizer {
+	return &OllamaSummarizer{
+		cfg:    cfg,
+		client: &http.Client{},
+	}
+}
 
-	log.Println("🧠 Calling Ollama for summary...")
+func (o *OllamaSummarizer) Model() string {
+	return o.cfg.Model
+}
 
-	payload := OllamaRequest{
-		Model:  "llama3",
-		Prompt: prompt,
-		Stream: false,
-	}
+func (o *OllamaSummarizer) Generate(ctx context.Context, prompt string) (string, error) {
+	return o.generate(ctx, prompt, nil)
+}
+
+// GenerateJSON passes schema as Ollama's format option, which constrains the
+// output to JSON matching the schema.
+func (o *OllamaSummarizer) GenerateJSON(ctx context.Context, prompt string, schema json.RawMessage) (string, error) {
+	return o.generate(ctx, prompt, schema)
+}
 
-	body, _ := json.Marshal(payload)
-	log.Printf("📤 Prompt: %s\n", prompt)
+func (o *OllamaSummarizer) generate(ctx context.Context, prompt string, format json.RawMessage) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, o.cfg.Timeout)
+	defer cancel()
 
-	resp, err := http.Post(ollamaUrl+"/api/generate", "application/json", bytes.NewReader(body))
+	resp, err := o.post(ctx, prompt, false, format)
 	if err != nil {
-		log.Println("❌ Ollama request failed:", err)
 		return "", err
 	}
-
 	defer resp.Body.Close()
 
-	raw, _ := io.ReadAll(resp.Body)
-	log.Println("✅ Ollama responded")
+	var result OllamaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("%w: error decoding Ollama response: %v", apperrors.ErrInternal, err)
+	}
 
-	var res OllamaResponse
-	err = json.Unmarshal(raw, &res)
+	return result.Response, nil
+}
+
+// GenerateStream uses Ollama's streaming mode, which answers with one JSON
+// object per line until an object with done set to true.
+func (o *OllamaSummarizer) GenerateStream(ctx context.Context, prompt string, onToken func(token string) error) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, o.cfg.Timeout)
+	defer cancel()
 
+	resp, err := o.post(ctx, prompt, true, nil)
 	if err != nil {
-		log.Printf("⚠️ Failed to parse Ollama response: %v\nRaw: %s\n", err, string(raw))
-		return "", fmt.Errorf("ollama error: %v - raw: %s", err, raw)
+		return "", err
 	}
+	defer resp.Body.Close()
 
-	log.Println("📝 AI Summary done")
-	return res.Response, nil
+	var full strings.Builder
+	decoder := json.NewDecoder(resp.Body)
+
+	for {
+		var chunk OllamaResponse
+		if err := decoder.Decode(&chunk); err != nil {
+			if ctx.Err() != nil {
+				return full.String(), ctx.Err()
+			}
+			if errors.Is(err, io.EOF) {
+				return full.String(), fmt.Errorf("%w: Ollama stream ended before completion", apperrors.ErrServiceUnavailable)
+			}
+			return full.String(), fmt.Errorf("%w: error decoding Ollama stream: %v", apperrors.ErrInternal, err)
+		}
+
+		if chunk.Response != "" {
+			full.WriteString(chunk.Response)
+			if err := onToken(chunk.Response); err != nil {
+				return full.String(), err
+			}
+		}
+
+		if chunk.Done {
+			return full.String(), nil
+		}
+	}
 }
 
-func SummarizeArticles(ctx context.Context, combined string) (string, error) {
-	ollamaUrl := GetOllamaUrl()
-
+func (o *OllamaSummarizer) post(ctx context.Context, prompt string, stream bool, format json.RawMessage) (*http.Response, error) {
 	payload := OllamaRequest{
-		Model:  "llama3",
-		Prompt: fmt.Sprintf("Summarize the following stock market news: \n\n%s", combined),
-		Stream: false,
+		Model:   o.cfg.Model,
+		Prompt:  prompt,
+		Stream:  stream,
+		Format:  format,
+		Options: OllamaOptions{Temperature: o.cfg.Temperature},
 	}
 
-	data, _ := json.Marshal(payload)
-
-	resp, err := http.Post(ollamaUrl+"/api/generate", "application/json", bytes.NewBuffer(data))
-
+	body, err := json.Marshal(payload)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("%w: error encoding Ollama request: %v", apperrors.ErrInternal, err)
 	}
 
-	defer resp.Body.Close()
-
-	var result OllamaResponse
-	err = json.NewDecoder(resp.Body).Decode(&result)
+	url := strings.TrimRight(o.cfg.BaseURL, "/") + "/api/generate"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("%w: error creating Ollama request: %v", apperrors.ErrInternal, err)
 	}
+	req.Header.Set("Content-Type", "application/json")
 
-	return result.Response, nil
-}
+	log.Debug().Str("model", o.cfg.Model).Bool("stream", stream).Msg("Calling Ollama")
 
-func GetOllamaUrl() string {
-	return os.Getenv("OLLAMA_URL")
+	return doRequest(ctx, o.client, req, "Ollama")
 }
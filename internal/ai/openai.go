@@ -0,0 +1,131 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/akhlexe/stocknews-api/internal/apperrors"
+	"github.com/rs/zerolog/log"
+)
+
+type ChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ChatCompletionRequest struct {
+	Model          string          `json:"model"`
+	Messages       []ChatMessage   `json:"messages"`
+	Temperature    float64         `json:"temperature"`
+	Stream         bool            `json:"stream"`
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+}
+
+type ResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *JSONSchemaFormat `json:"json_schema,omitempty"`
+}
+
+type JSONSchemaFormat struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+	Strict bool            `json:"strict"`
+}
+
+type ChatCompletionResponse struct {
+	Choices []struct {
+		Message ChatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// OpenAISummarizer talks to any OpenAI-compatible /chat/completions endpoint,
+// such as vLLM or LM Studio. BaseURL should include the API version, e.g.
+// http://localhost:1234/v1.
+type OpenAISummarizer struct {
+	cfg    Config
+	client *http.Client
+}
+
+func NewOpenAISummarizer(cfg Config) *OpenAISummarizer {
+	return &OpenAISummarizer{
+		cfg:    cfg,
+		client: &http.Client{},
+	}
+}
+
+func (o *OpenAISummarizer) Model() string {
+	return o.cfg.Model
+}
+
+func (o *OpenAISummarizer) Generate(ctx context.Context, prompt string) (string, error) {
+	return o.complete(ctx, prompt, nil)
+}
+
+// GenerateJSON requests a json_schema response format, which vLLM and LM
+// Studio enforce with guided decoding.
+func (o *OpenAISummarizer) GenerateJSON(ctx context.Context, prompt string, schema json.RawMessage) (string, error) {
+	return o.complete(ctx, prompt, &ResponseFormat{
+		Type:       "json_schema",
+		JSONSchema: &JSONSchemaFormat{Name: "response", Schema: schema, Strict: true},
+	})
+}
+
+func (o *OpenAISummarizer) complete(ctx context.Context, prompt string, format *ResponseFormat) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, o.cfg.Timeout)
+	defer cancel()
+
+	payload := ChatCompletionRequest{
+		Model:          o.cfg.Model,
+		Messages:       []ChatMessage{{Role: "user", Content: prompt}},
+		Temperature:    o.cfg.Temperature,
+		ResponseFormat: format,
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("%w: error encoding chat completion request: %v", apperrors.ErrInternal, err)
+	}
+
+	req, err := o.newRequest(ctx, body)
+	if err != nil {
+		return "", err
+	}
+
+	log.Debug().Str("model", o.cfg.Model).Msg("Calling OpenAI-compatible backend")
+
+	resp, err := doRequest(ctx, o.client, req, "OpenAI-compatible backend")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result ChatCompletionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("%w: error decoding chat completion response: %v", apperrors.ErrInternal, err)
+	}
+
+	if len(result.Choices) == 0 {
+		return "", fmt.Errorf("%w: chat completion returned no choices", apperrors.ErrInternal)
+	}
+
+	return result.Choices[0].Message.Content, nil
+}
+
+func (o *OpenAISummarizer) newRequest(ctx context.Context, body []byte) (*http.Request, error) {
+	url := strings.TrimRight(o.cfg.BaseURL, "/") + "/chat/completions"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: error creating chat completion request: %v", apperrors.ErrInternal, err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if o.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.cfg.APIKey)
+	}
+
+	return req, nil
+}
@@ -0,0 +1,235 @@
+package ai
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/akhlexe/stocknews-api/internal/apperrors"
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+// Prompt template names. Summary templates are selected by style.
+const (
+	PromptReduce     = "reduce"
+	PromptStructured = "structured"
+	PromptAnswer     = "answer"
+
+	StyleBrief     = "brief"
+	StyleDetailed  = "detailed"
+	StyleExecutive = "executive"
+
+	DefaultStyle = StyleDetailed
+)
+
+// Styles lists the summary styles accepted by the API.
+var Styles = []string{StyleBrief, StyleDetailed, StyleExecutive}
+
+//go:embed prompts
+var embeddedPrompts embed.FS
+
+// PromptData is passed to every prompt template.
+type PromptData struct {
+	Ticker   string
+	Style    string
+	Articles []PromptArticle
+	Partials []string
+	Schema   string
+	Feedback string
+
+	// Question and NoAnswer are used by the answer template.
+	Question string
+	NoAnswer string
+}
+
+// PromptArticle is an article as seen by a template. Index is 1-based so
+// models can cite articles by number.
+type PromptArticle struct {
+	Index   int
+	ID      string
+	Title   string
+	Summary string
+}
+
+// Prompt is one version of a named template.
+type Prompt struct {
+	Name    string
+	Version string
+	tmpl    *template.Template
+}
+
+// ID identifies the template version, e.g. "summary-brief@v2". It is part of
+// the summary cache key and is reported to clients.
+func (p *Prompt) ID() string {
+	return p.Name + "@" + p.Version
+}
+
+func (p *Prompt) Render(data PromptData) (string, error) {
+	var b strings.Builder
+	if err := p.tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("%w: error rendering prompt %s: %v", apperrors.ErrInternal, p.ID(), err)
+	}
+	return b.String(), nil
+}
+
+// PromptLibrary holds every version of every prompt template. Templates are
+// laid out as <name>/<version>.tmpl, e.g. summary-brief/v1.tmpl.
+type PromptLibrary struct {
+	prompts map[string]map[string]*Prompt
+	active  map[string]*Prompt
+}
+
+// DefaultPromptLibrary returns the embedded templates with the latest versions active.
+func DefaultPromptLibrary() *PromptLibrary {
+	library, err := LoadPromptLibrary("", nil)
+	if err != nil {
+		panic(err)
+	}
+	return library
+}
+
+// LoadPromptLibrary loads the embedded templates, then templates from dir,
+// which may add new versions or replace embedded ones. For every name the
+// highest version is active unless pins selects another one.
+func LoadPromptLibrary(dir string, pins map[string]string) (*PromptLibrary, error) {
+	library := &PromptLibrary{
+		prompts: make(map[string]map[string]*Prompt),
+		active:  make(map[string]*Prompt),
+	}
+
+	embedded, err := fs.Sub(embeddedPrompts, "prompts")
+	if err != nil {
+		return nil, err
+	}
+	if err := library.load(embedded); err != nil {
+		return nil, err
+	}
+
+	if dir != "" {
+		if err := library.load(os.DirFS(dir)); err != nil {
+			return nil, err
+		}
+		log.Info().Str("dir", dir).Msg("Loaded prompt templates")
+	}
+
+	for name, versions := range library.prompts {
+		library.active[name] = versions[latestVersion(versions)]
+	}
+
+	for name, version := range pins {
+		prompt, ok := library.prompts[name][version]
+		if !ok {
+			return nil, fmt.Errorf("%w: pinned prompt %s@%s does not exist", apperrors.ErrConfiguration, name, version)
+		}
+		library.active[name] = prompt
+	}
+
+	return library, nil
+}
+
+func (l *PromptLibrary) load(fsys fs.FS) error {
+	files, err := fs.Glob(fsys, "*/*.tmpl")
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+
+		name := path.Dir(file)
+		version := strings.TrimSuffix(path.Base(file), ".tmpl")
+
+		tmpl, err := template.New(file).Option("missingkey=error").Parse(string(data))
+		if err != nil {
+			return fmt.Errorf("%w: invalid prompt template %s: %v", apperrors.ErrConfiguration, file, err)
+		}
+
+		if l.prompts[name] == nil {
+			l.prompts[name] = make(map[string]*Prompt)
+		}
+		l.prompts[name][version] = &Prompt{Name: name, Version: version, tmpl: tmpl}
+	}
+
+	return nil
+}
+
+// Get returns the active version of the named prompt.
+func (l *PromptLibrary) Get(name string) (*Prompt, bool) {
+	prompt, ok := l.active[name]
+	return prompt, ok
+}
+
+// Summary returns the active summary prompt for style.
+func (l *PromptLibrary) Summary(style string) (*Prompt, bool) {
+	return l.Get("summary-" + style)
+}
+
+// ParsePromptPins parses a "name=version,name=version" list, as used by the
+// AI_PROMPT_VERSIONS environment variable.
+func ParsePromptPins(value string) (map[string]string, error) {
+	pins := make(map[string]string)
+	if strings.TrimSpace(value) == "" {
+		return pins, nil
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		name, version, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || name == "" || version == "" {
+			return nil, fmt.Errorf("%w: invalid prompt pin %q", apperrors.ErrConfiguration, entry)
+		}
+		pins[strings.TrimSpace(name)] = strings.TrimSpace(version)
+	}
+
+	return pins, nil
+}
+
+// IsValidStyle reports whether style is one of Styles.
+func IsValidStyle(style string) bool {
+	for _, s := range Styles {
+		if s == style {
+			return true
+		}
+	}
+	return false
+}
+
+// promptArticles sanitizes articles for use in a template. ids[i] is the ID
+// of the original of articles[i].
+func promptArticles(articles []models.Article, ids []string) []PromptArticle {
+	result := make([]PromptArticle, 0, len(articles))
+	for i, a := range articles {
+		a = SanitizeArticle(a)
+		result = append(result, PromptArticle{Index: i + 1, ID: ids[i], Title: a.Title, Summary: a.Summary})
+	}
+	return result
+}
+
+// latestVersion picks the highest version, comparing "v<number>" numerically
+// and anything else lexically.
+func latestVersion(versions map[string]*Prompt) string {
+	names := make([]string, 0, len(versions))
+	for v := range versions {
+		names = append(names, v)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		a, aErr := strconv.Atoi(strings.TrimPrefix(names[i], "v"))
+		b, bErr := strconv.Atoi(strings.TrimPrefix(names[j], "v"))
+		if aErr == nil && bErr == nil {
+			return a < b
+		}
+		return names[i] < names[j]
+	})
+
+	return names[len(names)-1]
+}
@@ -0,0 +1,73 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akhlexe/stocknews-api/internal/apperrors"
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultPromptLibrary(t *testing.T) {
+	library := DefaultPromptLibrary()
+
+	for _, style := range Styles {
+		prompt, ok := library.Summary(style)
+		assert.True(t, ok, style)
+
+		text, err := prompt.Render(PromptData{
+			Ticker:   "AAPL",
+			Style:    style,
+			Articles: promptArticles([]models.Article{{Title: "Title", Summary: "Summary"}}, []string{"id"}),
+		})
+		assert.NoError(t, err)
+		assert.Contains(t, text, "AAPL")
+		assert.Contains(t, text, "<article id=\"1\">\nTitle: Summary\n</article>")
+	}
+
+	for _, name := range []string{PromptReduce, PromptStructured} {
+		_, ok := library.Get(name)
+		assert.True(t, ok, name)
+	}
+}
+
+func TestLoadPromptLibraryFromDir(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "summary-brief"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "summary-brief", "v2.tmpl"),
+		[]byte("One sentence on {{.Ticker}}: {{range .Articles}}{{.Title}} {{end}}"), 0o644))
+
+	library, err := LoadPromptLibrary(dir, nil)
+	assert.NoError(t, err)
+
+	prompt, _ := library.Summary(StyleBrief)
+	assert.Equal(t, "summary-brief@v2", prompt.ID())
+
+	text, err := prompt.Render(PromptData{Ticker: "TSLA", Articles: []PromptArticle{{Title: "Recall"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, "One sentence on TSLA: Recall ", text)
+
+	pinned, err := LoadPromptLibrary(dir, map[string]string{"summary-brief": "v1"})
+	assert.NoError(t, err)
+	prompt, _ = pinned.Summary(StyleBrief)
+	assert.Equal(t, "summary-brief@v1", prompt.ID())
+
+	_, err = LoadPromptLibrary(dir, map[string]string{"summary-brief": "v9"})
+	assert.ErrorIs(t, err, apperrors.ErrConfiguration)
+}
+
+func TestLoadPromptLibraryInvalidTemplate(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "reduce"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "reduce", "v2.tmpl"), []byte("{{.Partials"), 0o644))
+
+	_, err := LoadPromptLibrary(dir, nil)
+	assert.ErrorIs(t, err, apperrors.ErrConfiguration)
+}
+
+func TestLatestVersion(t *testing.T) {
+	versions := map[string]*Prompt{"v2": nil, "v10": nil, "v9": nil}
+	assert.Equal(t, "v10", latestVersion(versions))
+}
@@ -0,0 +1,131 @@
+package ai
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+)
+
+// Article text is third-party content. Before it reaches a prompt it is
+// sanitized, and templates fence it in <article> tags that the model is told
+// to treat as data.
+const (
+	MaxTitleChars   = 300
+	MaxSummaryChars = 1500
+
+	removedMarker = "[removed]"
+)
+
+// injectionPatterns match phrases that try to address the model instead of
+// describing the news.
+var injectionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\b[^.!?\n]{0,40}\b(previous|prior|above|earlier|all|any|your)\b[^.!?\n]{0,20}\b(instructions?|prompts?|rules|directions|context)\b`),
+	regexp.MustCompile(`(?i)\byou\s+are\s+now\b`),
+	regexp.MustCompile(`(?i)\b(new|updated|real)\s+instructions?\s*:`),
+	regexp.MustCompile(`(?i)\bsystem\s*prompt\b`),
+	regexp.MustCompile(`(?i)(^|\s)(system|assistant|user)\s*:`),
+	regexp.MustCompile(`(?i)\[/?(inst|sys)\]|<<\s*/?sys\s*>>|<\|[a-z_]+\|>`),
+	regexp.MustCompile(`(?m)^\s*#{2,}\s*(instruction|system|response)s?\b.*$`),
+}
+
+// delimiterPattern matches sequences that could close or open a fence.
+var delimiterPattern = regexp.MustCompile("(?i)</?\\s*(article|partial)\\b[^>]*>|```|\"\"\"")
+
+// SanitizeArticle returns a copy of a safe to place inside a prompt fence.
+func SanitizeArticle(a models.Article) models.Article {
+	a.Title = sanitizeText(a.Title, MaxTitleChars)
+	a.Summary = sanitizeText(a.Summary, MaxSummaryChars)
+	return a
+}
+
+func sanitizeText(text string, maxChars int) string {
+	text = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' || r == '\r' {
+			return ' '
+		}
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		return r
+	}, text)
+
+	text = delimiterPattern.ReplaceAllString(text, " ")
+	for _, pattern := range injectionPatterns {
+		text = pattern.ReplaceAllString(text, " "+removedMarker+" ")
+	}
+	text = strings.Join(strings.Fields(text), " ")
+
+	if runes := []rune(text); len(runes) > maxChars {
+		text = string(runes[:maxChars]) + "…"
+	}
+
+	return text
+}
+
+var (
+	tickerMention = regexp.MustCompile(`\$?\b[A-Z]{2,5}\b`)
+	numberMention = regexp.MustCompile(`\d[\d,]*(\.\d+)?`)
+)
+
+// commonAcronyms are uppercase words that are not tickers.
+var commonAcronyms = map[string]bool{
+	"AI": true, "API": true, "CEO": true, "CFO": true, "COO": true, "CTO": true,
+	"EPS": true, "ETF": true, "EU": true, "EV": true, "FDA": true, "FED": true,
+	"GDP": true, "IPO": true, "NYSE": true, "SEC": true, "UK": true, "US": true,
+	"USA": true, "USD": true, "YOY": true, "QOQ": true, "AND": true, "THE": true,
+}
+
+// CheckSummary flags tickers and numbers in a model's output that don't appear
+// anywhere in its inputs, which usually means the model made them up or
+// followed injected text. Single-digit numbers are ignored.
+func CheckSummary(summary string, ticker string, articles []models.Article) []string {
+	var input strings.Builder
+	input.WriteString(ticker + " ")
+	for _, a := range articles {
+		input.WriteString(a.Title + " " + a.Summary + " " + strings.Join(a.Tickers, " ") + " ")
+	}
+
+	knownTickers := make(map[string]bool)
+	for _, m := range tickerMention.FindAllString(input.String(), -1) {
+		knownTickers[strings.TrimPrefix(m, "$")] = true
+	}
+
+	knownNumbers := make(map[string]bool)
+	for _, m := range numberMention.FindAllString(input.String(), -1) {
+		knownNumbers[normalizeNumber(m)] = true
+	}
+
+	var warnings []string
+	seen := make(map[string]bool)
+
+	for _, m := range tickerMention.FindAllString(summary, -1) {
+		symbol := strings.TrimPrefix(m, "$")
+		if knownTickers[symbol] || commonAcronyms[symbol] || seen["t"+symbol] {
+			continue
+		}
+		seen["t"+symbol] = true
+		warnings = append(warnings, fmt.Sprintf("ticker %s is not mentioned in the source articles", symbol))
+	}
+
+	for _, m := range numberMention.FindAllString(summary, -1) {
+		number := normalizeNumber(m)
+		if len(number) < 2 || knownNumbers[number] || seen["n"+number] {
+			continue
+		}
+		seen["n"+number] = true
+		warnings = append(warnings, fmt.Sprintf("number %s is not mentioned in the source articles", m))
+	}
+
+	return warnings
+}
+
+func normalizeNumber(n string) string {
+	n = strings.ReplaceAll(n, ",", "")
+	if strings.Contains(n, ".") {
+		n = strings.TrimRight(strings.TrimRight(n, "0"), ".")
+	}
+	return n
+}
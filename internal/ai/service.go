@@ -0,0 +1,370 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/apperrors"
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+// SummaryStore caches generated summaries by fingerprint.
+type SummaryStore interface {
+	GetSummary(ctx context.Context, key string) (string, bool)
+	SetSummary(ctx context.Context, key string, summary string)
+}
+
+// SummaryRequest describes the articles to summarize and how.
+type SummaryRequest struct {
+	Ticker   string
+	Articles []models.Article
+
+	// Style selects the summary prompt; empty means DefaultStyle.
+	Style string
+}
+
+// Summary is the result of summarizing a set of articles.
+type Summary struct {
+	Text        string
+	Fingerprint string
+	Cached      bool
+
+	// PromptVersion identifies the template that produced the summary.
+	PromptVersion string
+
+	// ArticlesIncluded counts the leading articles that fit in the token
+	// budget; the remaining ones were not shown to the model.
+	ArticlesIncluded int
+	Chunks           int
+
+	// Structured is set by SummarizeStructured; Text then holds its raw JSON.
+	Structured *StructuredSummary
+
+	// Warnings flags content in the summary that is not backed by the
+	// articles, such as tickers or figures the model may have invented.
+	Warnings []string
+}
+
+// DefaultConcurrency matches a local Ollama instance, which by default
+// generates one response at a time.
+const DefaultConcurrency = 1
+
+// ErrBusy is returned when no model slot frees up within the wait set with
+// WithSlotWait.
+var ErrBusy = errors.New("every model slot is busy")
+
+type slotWaitKey struct{}
+
+// WithSlotWait bounds how long calls made with the returned context wait for a
+// free model slot, for callers that would rather fail with ErrBusy than queue
+// behind other work. Without it a call waits until ctx is done.
+func WithSlotWait(ctx context.Context, wait time.Duration) context.Context {
+	return context.WithValue(ctx, slotWaitKey{}, wait)
+}
+
+// SummaryOptions sizes the map-reduce pipeline for the configured model.
+type SummaryOptions struct {
+	// ContextTokens is the model's context window. A quarter of it is left
+	// free for the model's answer.
+	ContextTokens int
+
+	// MaxChunks caps the number of map calls per summary.
+	MaxChunks int
+
+	// Prompts supplies the prompt templates; nil uses the embedded defaults.
+	Prompts *PromptLibrary
+
+	// Concurrency caps the model calls in flight across every caller, be it
+	// a request handler or a summary job worker.
+	Concurrency int
+}
+
+// SummaryService summarizes article sets, reusing a cached summary while the
+// set of articles, the model and the prompt are unchanged. Article sets that
+// don't fit in the model context are summarized in chunks whose partial
+// summaries are then combined.
+type SummaryService struct {
+	summarizer Summarizer
+	store      SummaryStore
+	opts       SummaryOptions
+	slots      chan struct{}
+}
+
+// NewSummaryService creates a SummaryService. store may be nil to disable caching.
+func NewSummaryService(summarizer Summarizer, store SummaryStore, opts SummaryOptions) *SummaryService {
+	if opts.ContextTokens <= 0 {
+		opts.ContextTokens = DefaultContextTokens
+	}
+	if opts.MaxChunks <= 0 {
+		opts.MaxChunks = DefaultMaxChunks
+	}
+	if opts.Prompts == nil {
+		opts.Prompts = DefaultPromptLibrary()
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+
+	return &SummaryService{
+		summarizer: summarizer,
+		store:      store,
+		opts:       opts,
+		slots:      make(chan struct{}, opts.Concurrency),
+	}
+}
+
+func (s *SummaryService) Summarize(ctx context.Context, req SummaryRequest) (Summary, error) {
+	return s.summarize(ctx, req, nil)
+}
+
+// SummarizeStream is like Summarize but passes generated text to onToken as it
+// arrives. Only the final step is streamed; a cached summary is delivered as a
+// single token.
+func (s *SummaryService) SummarizeStream(ctx context.Context, req SummaryRequest, onToken func(token string) error) (Summary, error) {
+	return s.summarize(ctx, req, onToken)
+}
+
+func (s *SummaryService) summarize(ctx context.Context, req SummaryRequest, onToken func(token string) error) (Summary, error) {
+	if req.Style == "" {
+		req.Style = DefaultStyle
+	}
+
+	prompt, ok := s.opts.Prompts.Summary(req.Style)
+	if !ok {
+		return Summary{}, fmt.Errorf("%w: no prompt for style %q", apperrors.ErrConfiguration, req.Style)
+	}
+	reducePrompt, ok := s.opts.Prompts.Get(PromptReduce)
+	if !ok {
+		return Summary{}, fmt.Errorf("%w: no reduce prompt", apperrors.ErrConfiguration)
+	}
+
+	budget := s.promptBudget(req, prompt, reducePrompt)
+	chunks := chunkArticles(sanitizeArticles(req.Articles), budget, s.opts.MaxChunks)
+	ids := articleIDs(req.Articles)
+
+	included := 0
+	for _, chunk := range chunks {
+		included += len(chunk)
+	}
+
+	result := Summary{
+		Fingerprint:      Fingerprint(req.Articles[:included], s.summarizer.Model(), prompt.ID()+"+"+reducePrompt.ID()),
+		PromptVersion:    prompt.ID(),
+		ArticlesIncluded: included,
+		Chunks:           len(chunks),
+	}
+
+	if s.store != nil {
+		if text, ok := s.store.GetSummary(ctx, result.Fingerprint); ok {
+			log.Debug().Str("fingerprint", result.Fingerprint).Msg("Summary cache hit")
+			if onToken != nil {
+				if err := onToken(text); err != nil {
+					return Summary{}, err
+				}
+			}
+			result.Text = text
+			result.Cached = true
+			result.Warnings = checkChunks(text, req.Ticker, chunks)
+			return result, nil
+		}
+	}
+
+	var text string
+	var err error
+	if len(chunks) <= 1 {
+		var chunk []models.Article
+		if len(chunks) == 1 {
+			chunk = chunks[0]
+		}
+		text, err = s.generateFromTemplate(ctx, prompt, s.promptData(req, chunk, ids, nil), onToken)
+	} else {
+		log.Debug().
+			Int("chunks", len(chunks)).
+			Int("articles_included", included).
+			Int("articles_total", len(req.Articles)).
+			Msg("Summarizing articles in chunks")
+
+		partials := make([]string, 0, len(chunks))
+		offset := 0
+		for _, chunk := range chunks {
+			partial, err := s.generateFromTemplate(ctx, prompt, s.promptData(req, chunk, ids[offset:], nil), nil)
+			if err != nil {
+				return Summary{}, err
+			}
+			partials = append(partials, partial)
+			offset += len(chunk)
+		}
+		text, err = s.reduce(ctx, req, reducePrompt, partials, budget, onToken)
+	}
+	if err != nil {
+		return Summary{}, err
+	}
+
+	if s.store != nil {
+		s.store.SetSummary(ctx, result.Fingerprint, text)
+	}
+
+	result.Text = text
+	result.Warnings = checkChunks(text, req.Ticker, chunks)
+	if len(result.Warnings) > 0 {
+		log.Warn().Strs("warnings", result.Warnings).Str("ticker", req.Ticker).Msg("Summary mentions content not found in articles")
+	}
+	return result, nil
+}
+
+// checkChunks runs CheckSummary against every article that was summarized.
+func checkChunks(text, ticker string, chunks [][]models.Article) []string {
+	var articles []models.Article
+	for _, chunk := range chunks {
+		articles = append(articles, chunk...)
+	}
+	return CheckSummary(text, ticker, articles)
+}
+
+// articleIDs lists the IDs of articles. Sanitizing can change the title, and
+// so the ID, of an article without a URL.
+func articleIDs(articles []models.Article) []string {
+	ids := make([]string, 0, len(articles))
+	for _, a := range articles {
+		ids = append(ids, a.ID())
+	}
+	return ids
+}
+
+func sanitizeArticles(articles []models.Article) []models.Article {
+	sanitized := make([]models.Article, 0, len(articles))
+	for _, a := range articles {
+		sanitized = append(sanitized, SanitizeArticle(a))
+	}
+	return sanitized
+}
+
+// reduce combines partial summaries, in several rounds if they don't fit in a
+// single prompt.
+func (s *SummaryService) reduce(ctx context.Context, req SummaryRequest, prompt *Prompt, partials []string, budget int, onToken func(token string) error) (string, error) {
+	for {
+		groups := chunkTexts(partials, budget)
+		// Partials that don't shrink any further are combined in one last step.
+		if len(groups) == 1 || len(groups) == len(partials) {
+			return s.generateFromTemplate(ctx, prompt, s.promptData(req, nil, nil, partials), onToken)
+		}
+
+		next := make([]string, 0, len(groups))
+		for _, group := range groups {
+			combined, err := s.generateFromTemplate(ctx, prompt, s.promptData(req, nil, nil, group), nil)
+			if err != nil {
+				return "", err
+			}
+			next = append(next, combined)
+		}
+		partials = next
+	}
+}
+
+func (s *SummaryService) generateFromTemplate(ctx context.Context, prompt *Prompt, data PromptData, onToken func(token string) error) (string, error) {
+	text, err := prompt.Render(data)
+	if err != nil {
+		return "", err
+	}
+	return s.generate(ctx, text, onToken)
+}
+
+// acquire waits for a free model slot, for as long as WithSlotWait allows.
+// The caller must call the returned release func once the model has answered.
+func (s *SummaryService) acquire(ctx context.Context) (func(), error) {
+	var timeout <-chan time.Time
+	if wait, ok := ctx.Value(slotWaitKey{}).(time.Duration); ok {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+		return func() { <-s.slots }, nil
+	case <-timeout:
+		return nil, ErrBusy
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// generate runs a prompt, streaming the output when onToken is set.
+func (s *SummaryService) generate(ctx context.Context, prompt string, onToken func(token string) error) (string, error) {
+	release, err := s.acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	if onToken == nil {
+		return s.summarizer.Generate(ctx, prompt)
+	}
+
+	if streamer, ok := s.summarizer.(StreamingSummarizer); ok {
+		return streamer.GenerateStream(ctx, prompt, onToken)
+	}
+
+	// Backends without streaming deliver the whole summary as one token.
+	text, err := s.summarizer.Generate(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	return text, onToken(text)
+}
+
+// promptData fills a template. articles are sanitized copies, so ids holds the
+// IDs of the original articles, in the same order; citations must match the
+// IDs clients see.
+func (s *SummaryService) promptData(req SummaryRequest, articles []models.Article, ids []string, partials []string) PromptData {
+	sanitized := make([]string, 0, len(partials))
+	for _, p := range partials {
+		sanitized = append(sanitized, sanitizeText(p, MaxSummaryChars*2))
+	}
+
+	return PromptData{
+		Ticker:   req.Ticker,
+		Style:    req.Style,
+		Articles: promptArticles(articles, ids),
+		Partials: sanitized,
+	}
+}
+
+// promptBudget is the number of tokens available for article text once the
+// largest of the given templates has been rendered.
+func (s *SummaryService) promptBudget(req SummaryRequest, prompts ...*Prompt) int {
+	overhead := 0
+	for _, prompt := range prompts {
+		data := s.promptData(req, nil, nil, nil)
+		data.Schema = string(StructuredSummarySchema)
+		if text, err := prompt.Render(data); err == nil && EstimateTokens(text) > overhead {
+			overhead = EstimateTokens(text)
+		}
+	}
+
+	budget := s.opts.ContextTokens*3/4 - overhead
+	if budget < 1 {
+		budget = 1
+	}
+	return budget
+}
+
+// Fingerprint identifies a summary by the set of articles it covers, the model
+// that wrote it and the prompt version. Article order does not matter.
+func Fingerprint(articles []models.Article, model, promptVersion string) string {
+	ids := make([]string, 0, len(articles))
+	for _, a := range articles {
+		ids = append(ids, a.ID())
+	}
+	sort.Strings(ids)
+
+	sum := sha256.Sum256([]byte(model + "\n" + promptVersion + "\n" + strings.Join(ids, ",")))
+	return hex.EncodeToString(sum[:])
+}
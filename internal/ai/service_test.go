@@ -0,0 +1,166 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingSummarizer struct {
+	calls int
+}
+
+func (s *countingSummarizer) Generate(ctx context.Context, prompt string) (string, error) {
+	s.calls++
+	return "summary", nil
+}
+
+func (s *countingSummarizer) Model() string {
+	return "test-model"
+}
+
+type memoryStore map[string]string
+
+func (m memoryStore) GetSummary(ctx context.Context, key string) (string, bool) {
+	summary, ok := m[key]
+	return summary, ok
+}
+
+func (m memoryStore) SetSummary(ctx context.Context, key string, summary string) {
+	m[key] = summary
+}
+
+func TestFingerprint(t *testing.T) {
+	a := models.Article{Title: "A", URL: "https://example.com/a"}
+	b := models.Article{Title: "B", URL: "https://example.com/b"}
+	c := models.Article{Title: "C", URL: "https://example.com/c"}
+
+	base := Fingerprint([]models.Article{a, b}, "llama3", "v1")
+
+	assert.Equal(t, base, Fingerprint([]models.Article{b, a}, "llama3", "v1"), "Order should not matter")
+	assert.NotEqual(t, base, Fingerprint([]models.Article{a, b, c}, "llama3", "v1"))
+	assert.NotEqual(t, base, Fingerprint([]models.Article{a, b}, "mistral", "v1"))
+	assert.NotEqual(t, base, Fingerprint([]models.Article{a, b}, "llama3", "v2"))
+}
+
+func TestSummaryServiceCaching(t *testing.T) {
+	summarizer := &countingSummarizer{}
+	service := NewSummaryService(summarizer, memoryStore{}, SummaryOptions{})
+	articles := []models.Article{{Title: "A", URL: "https://example.com/a"}}
+
+	first, err := service.Summarize(context.Background(), SummaryRequest{Articles: articles})
+	assert.NoError(t, err)
+	assert.False(t, first.Cached)
+
+	second, err := service.Summarize(context.Background(), SummaryRequest{Articles: articles})
+	assert.NoError(t, err)
+	assert.True(t, second.Cached)
+	assert.Equal(t, "summary", second.Text)
+	assert.Equal(t, 1, summarizer.calls)
+
+	articles = append(articles, models.Article{Title: "B", URL: "https://example.com/b"})
+	third, err := service.Summarize(context.Background(), SummaryRequest{Articles: articles})
+	assert.NoError(t, err)
+	assert.False(t, third.Cached)
+	assert.Equal(t, 2, summarizer.calls)
+}
+
+type promptRecorder struct {
+	prompts []string
+}
+
+func (r *promptRecorder) Generate(ctx context.Context, prompt string) (string, error) {
+	r.prompts = append(r.prompts, prompt)
+	return fmt.Sprintf("partial %d", len(r.prompts)), nil
+}
+
+func (r *promptRecorder) Model() string {
+	return "test-model"
+}
+
+func TestSummaryServiceMapReduce(t *testing.T) {
+	var articles []models.Article
+	for i := 0; i < 10; i++ {
+		articles = append(articles, models.Article{
+			Title:   fmt.Sprintf("Article %d", i),
+			URL:     fmt.Sprintf("https://example.com/%d", i),
+			Summary: strings.Repeat("word ", 100),
+		})
+	}
+
+	recorder := &promptRecorder{}
+	// Each article is ~130 tokens; a 512 token context leaves room for two per chunk.
+	service := NewSummaryService(recorder, nil, SummaryOptions{ContextTokens: 512, MaxChunks: 3})
+
+	summary, err := service.Summarize(context.Background(), SummaryRequest{Articles: articles})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 3, summary.Chunks)
+	assert.Equal(t, 6, summary.ArticlesIncluded)
+	assert.Len(t, recorder.prompts, 4, "Three map calls and one reduce call")
+	assert.True(t, strings.HasPrefix(recorder.prompts[3], "Combine the following partial summaries"))
+	assert.Contains(t, recorder.prompts[3], "<partial>\npartial 1\n</partial>\n<partial>\npartial 2\n</partial>")
+	assert.Equal(t, "partial 4", summary.Text)
+
+	for _, prompt := range recorder.prompts[:3] {
+		assert.LessOrEqual(t, EstimateTokens(prompt), 512*3/4)
+	}
+}
+
+func TestChunkArticlesTruncatesLongArticles(t *testing.T) {
+	long := models.Article{Title: "Long", Summary: strings.Repeat("x", 4000)}
+
+	chunks := chunkArticles([]models.Article{long}, 100, DefaultMaxChunks)
+
+	assert.Len(t, chunks, 1)
+	assert.LessOrEqual(t, EstimateTokens(CombineArticles(chunks[0])), 100)
+}
+
+func TestContextTokensForModel(t *testing.T) {
+	overrides, err := ParseTokenBudgets("llama3=16000, custom=2048")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 16000, ContextTokensForModel("llama3:8b", overrides))
+	assert.Equal(t, 2048, ContextTokensForModel("custom", overrides))
+	assert.Equal(t, 4096, ContextTokensForModel("phi3", overrides))
+	assert.Equal(t, DefaultContextTokens, ContextTokensForModel("unknown", overrides))
+
+	_, err = ParseTokenBudgets("llama3")
+	assert.Error(t, err)
+}
+
+func TestSummaryServiceWaitsForAModelSlot(t *testing.T) {
+	service := NewSummaryService(&countingSummarizer{}, nil, SummaryOptions{})
+	articles := []models.Article{{Title: "A", URL: "https://example.com/a"}}
+
+	// Another caller holds the only slot until the context runs out.
+	release, err := service.acquire(context.Background())
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = service.Summarize(ctx, SummaryRequest{Articles: articles})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	release()
+	_, err = service.Summarize(context.Background(), SummaryRequest{Articles: articles})
+	assert.NoError(t, err)
+}
+
+func TestSummaryServiceGivesUpWaitingForABusySlot(t *testing.T) {
+	service := NewSummaryService(&countingSummarizer{}, nil, SummaryOptions{})
+	articles := []models.Article{{Title: "A", URL: "https://example.com/a"}}
+
+	release, err := service.acquire(context.Background())
+	assert.NoError(t, err)
+	defer release()
+
+	ctx := WithSlotWait(context.Background(), 10*time.Millisecond)
+	_, err = service.Summarize(ctx, SummaryRequest{Articles: articles})
+	assert.ErrorIs(t, err, ErrBusy)
+}
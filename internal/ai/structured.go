@@ -0,0 +1,204 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/akhlexe/stocknews-api/internal/apperrors"
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+// structuredMaxAttempts bounds how often invalid model output is retried.
+const structuredMaxAttempts = 3
+
+const (
+	StanceBullish = "bullish"
+	StanceBearish = "bearish"
+	StanceNeutral = "neutral"
+	StanceMixed   = "mixed"
+)
+
+// StructuredSummary is the JSON summary format rendered directly by clients.
+type StructuredSummary struct {
+	KeyPoints []SummaryPoint `json:"key_points"`
+	Catalysts []SummaryPoint `json:"catalysts"`
+	Risks     []SummaryPoint `json:"risks"`
+	Stance    string         `json:"stance"`
+}
+
+// SummaryPoint is one bullet. Sources holds the 1-based indices of the
+// articles it came from, as numbered in the prompt; ArticleIDs holds the
+// matching article IDs and is filled in after validation.
+type SummaryPoint struct {
+	Text       string   `json:"text"`
+	Sources    []int    `json:"sources"`
+	ArticleIDs []string `json:"article_ids,omitempty"`
+}
+
+// StructuredSummarySchema is the JSON schema the model output must follow.
+// The point schema is inlined because not every backend resolves $ref.
+var StructuredSummarySchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "key_points": {"type": "array", "items": ` + pointSchema + `},
+    "catalysts": {"type": "array", "items": ` + pointSchema + `},
+    "risks": {"type": "array", "items": ` + pointSchema + `},
+    "stance": {"type": "string", "enum": ["bullish", "bearish", "neutral", "mixed"]}
+  },
+  "required": ["key_points", "catalysts", "risks", "stance"],
+  "additionalProperties": false
+}`)
+
+const pointSchema = `{
+      "type": "object",
+      "properties": {
+        "text": {"type": "string"},
+        "sources": {"type": "array", "items": {"type": "integer", "minimum": 1}, "minItems": 1}
+      },
+      "required": ["text", "sources"],
+      "additionalProperties": false
+    }`
+
+var errInvalidStructuredSummary = errors.New("invalid structured summary")
+
+// SummarizeStructured produces a StructuredSummary for the articles that fit in
+// a single prompt. Output that fails validation is sent back to the model with
+// the validation error, up to structuredMaxAttempts times.
+func (s *SummaryService) SummarizeStructured(ctx context.Context, req SummaryRequest) (Summary, error) {
+	prompt, ok := s.opts.Prompts.Get(PromptStructured)
+	if !ok {
+		return Summary{}, fmt.Errorf("%w: no structured prompt", apperrors.ErrConfiguration)
+	}
+
+	chunks := chunkArticles(sanitizeArticles(req.Articles), s.promptBudget(req, prompt), 1)
+
+	var included []models.Article
+	if len(chunks) > 0 {
+		included = chunks[0]
+	}
+	ids := articleIDs(req.Articles[:len(included)])
+
+	result := Summary{
+		Fingerprint:      Fingerprint(req.Articles[:len(included)], s.summarizer.Model(), prompt.ID()),
+		PromptVersion:    prompt.ID(),
+		ArticlesIncluded: len(included),
+		Chunks:           len(chunks),
+	}
+
+	if s.store != nil {
+		if text, ok := s.store.GetSummary(ctx, result.Fingerprint); ok {
+			if structured, err := parseStructuredSummary(text, ids); err == nil {
+				result.Text = text
+				result.Structured = structured
+				result.Cached = true
+				result.Warnings = CheckSummary(structured.pointText(), req.Ticker, included)
+				return result, nil
+			}
+		}
+	}
+
+	data := s.promptData(req, included, ids, nil)
+	data.Schema = string(StructuredSummarySchema)
+
+	var lastErr error
+	for attempt := 1; attempt <= structuredMaxAttempts; attempt++ {
+		text, err := prompt.Render(data)
+		if err != nil {
+			return Summary{}, err
+		}
+
+		text, err = s.generateJSON(ctx, text)
+		if err != nil {
+			return Summary{}, err
+		}
+
+		structured, err := parseStructuredSummary(text, ids)
+		if err == nil {
+			if s.store != nil {
+				s.store.SetSummary(ctx, result.Fingerprint, text)
+			}
+			result.Text = text
+			result.Structured = structured
+			result.Warnings = CheckSummary(structured.pointText(), req.Ticker, included)
+			return result, nil
+		}
+
+		lastErr = err
+		log.Warn().Err(err).Int("attempt", attempt).Msg("Model returned an invalid structured summary")
+		data.Feedback = err.Error()
+	}
+
+	return Summary{}, fmt.Errorf("%w: %v", apperrors.ErrInternal, lastErr)
+}
+
+func (s *SummaryService) generateJSON(ctx context.Context, prompt string) (string, error) {
+	release, err := s.acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	if generator, ok := s.summarizer.(JSONSummarizer); ok {
+		return generator.GenerateJSON(ctx, prompt, StructuredSummarySchema)
+	}
+	// The prompt carries the schema, so plain generation can still succeed.
+	return s.summarizer.Generate(ctx, prompt)
+}
+
+// parseStructuredSummary validates model output against the schema rules and
+// the number of articles, and resolves source indices to the article IDs in ids.
+func parseStructuredSummary(text string, ids []string) (*StructuredSummary, error) {
+	decoder := json.NewDecoder(strings.NewReader(strings.TrimSpace(text)))
+	decoder.DisallowUnknownFields()
+
+	var summary StructuredSummary
+	if err := decoder.Decode(&summary); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidStructuredSummary, err)
+	}
+
+	switch summary.Stance {
+	case StanceBullish, StanceBearish, StanceNeutral, StanceMixed:
+	default:
+		return nil, fmt.Errorf("%w: unknown stance %q", errInvalidStructuredSummary, summary.Stance)
+	}
+
+	if summary.KeyPoints == nil || summary.Catalysts == nil || summary.Risks == nil {
+		return nil, fmt.Errorf("%w: key_points, catalysts and risks are required", errInvalidStructuredSummary)
+	}
+
+	for _, points := range [][]SummaryPoint{summary.KeyPoints, summary.Catalysts, summary.Risks} {
+		for i := range points {
+			if strings.TrimSpace(points[i].Text) == "" {
+				return nil, fmt.Errorf("%w: point with empty text", errInvalidStructuredSummary)
+			}
+			if len(points[i].Sources) == 0 {
+				return nil, fmt.Errorf("%w: point %q has no sources", errInvalidStructuredSummary, points[i].Text)
+			}
+
+			points[i].ArticleIDs = nil
+			for _, source := range points[i].Sources {
+				if source < 1 || source > len(ids) {
+					return nil, fmt.Errorf("%w: source %d does not exist", errInvalidStructuredSummary, source)
+				}
+				points[i].ArticleIDs = append(points[i].ArticleIDs, ids[source-1])
+			}
+		}
+	}
+
+	return &summary, nil
+}
+
+// pointText joins the text of every point, for CheckSummary.
+func (s *StructuredSummary) pointText() string {
+	var texts []string
+	for _, points := range [][]SummaryPoint{s.KeyPoints, s.Catalysts, s.Risks} {
+		for _, p := range points {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,118 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/akhlexe/stocknews-api/internal/apperrors"
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// scriptedSummarizer returns its responses in order, one per call.
+type scriptedSummarizer struct {
+	responses []string
+	prompts   []string
+	schemas   []json.RawMessage
+}
+
+func (s *scriptedSummarizer) Generate(ctx context.Context, prompt string) (string, error) {
+	return s.GenerateJSON(ctx, prompt, nil)
+}
+
+func (s *scriptedSummarizer) GenerateJSON(ctx context.Context, prompt string, schema json.RawMessage) (string, error) {
+	s.prompts = append(s.prompts, prompt)
+	s.schemas = append(s.schemas, schema)
+	response := s.responses[0]
+	s.responses = s.responses[1:]
+	return response, nil
+}
+
+func (s *scriptedSummarizer) Model() string {
+	return "test-model"
+}
+
+var structuredArticles = []models.Article{
+	{Title: "Record revenue", URL: "https://example.com/revenue", Summary: "Revenue beat estimates."},
+	{Title: "Recall announced", URL: "https://example.com/recall", Summary: "A product recall was announced."},
+}
+
+const validStructured = `{
+	"key_points": [{"text": "Revenue hit a record", "sources": [1]}],
+	"catalysts": [{"text": "Strong demand", "sources": [1]}],
+	"risks": [{"text": "Recall costs", "sources": [2]}],
+	"stance": "mixed"
+}`
+
+func TestStructuredSummarySchemaIsValidJSON(t *testing.T) {
+	assert.True(t, json.Valid(StructuredSummarySchema))
+}
+
+func TestParseStructuredSummary(t *testing.T) {
+	summary, err := parseStructuredSummary(validStructured, articleIDs(structuredArticles))
+	assert.NoError(t, err)
+	assert.Equal(t, StanceMixed, summary.Stance)
+	assert.Equal(t, []string{structuredArticles[1].ID()}, summary.Risks[0].ArticleIDs)
+
+	invalid := map[string]string{
+		"not json":        "The stock went up.",
+		"unknown stance":  strings.Replace(validStructured, `"mixed"`, `"moon"`, 1),
+		"missing source":  strings.Replace(validStructured, `"sources": [2]`, `"sources": []`, 1),
+		"source too high": strings.Replace(validStructured, `"sources": [2]`, `"sources": [3]`, 1),
+		"unknown field":   strings.Replace(validStructured, `"stance"`, `"extra": 1, "stance"`, 1),
+		"missing risks":   `{"key_points": [], "catalysts": [], "stance": "neutral"}`,
+	}
+	for name, text := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseStructuredSummary(text, articleIDs(structuredArticles))
+			assert.ErrorIs(t, err, errInvalidStructuredSummary)
+		})
+	}
+}
+
+func TestSummarizeStructuredRetriesInvalidOutput(t *testing.T) {
+	summarizer := &scriptedSummarizer{responses: []string{`{"stance": "up"}`, validStructured}}
+	service := NewSummaryService(summarizer, memoryStore{}, SummaryOptions{})
+
+	summary, err := service.SummarizeStructured(context.Background(), SummaryRequest{Articles: structuredArticles})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, summary.ArticlesIncluded)
+	assert.Equal(t, "Revenue hit a record", summary.Structured.KeyPoints[0].Text)
+
+	assert.Len(t, summarizer.prompts, 2)
+	assert.Contains(t, summarizer.prompts[0], "<article id=\"2\">\nRecall announced: A product recall was announced.\n</article>")
+	assert.Contains(t, summarizer.prompts[1], "Your previous answer was rejected")
+	assert.Equal(t, StructuredSummarySchema, summarizer.schemas[0])
+
+	cached, err := service.SummarizeStructured(context.Background(), SummaryRequest{Articles: structuredArticles})
+	assert.NoError(t, err)
+	assert.True(t, cached.Cached)
+	assert.Len(t, summarizer.prompts, 2)
+}
+
+func TestSummarizeStructuredGivesUp(t *testing.T) {
+	summarizer := &scriptedSummarizer{responses: []string{"no", "still no", "nope"}}
+	service := NewSummaryService(summarizer, nil, SummaryOptions{})
+
+	_, err := service.SummarizeStructured(context.Background(), SummaryRequest{Articles: structuredArticles})
+	assert.ErrorIs(t, err, apperrors.ErrInternal)
+	assert.Len(t, summarizer.prompts, structuredMaxAttempts)
+}
+
+func TestSummarizeStructuredCitesOriginalArticleIDs(t *testing.T) {
+	// Without a URL the ID depends on the title, which sanitizing rewrites.
+	articles := []models.Article{
+		{Title: "Record\trevenue", PublishedAt: "20250102T150405", Summary: "Revenue beat estimates."},
+		{Title: "Recall\nannounced", PublishedAt: "20250102T160405", Summary: "A product recall was announced."},
+	}
+	summarizer := &scriptedSummarizer{responses: []string{validStructured}}
+	service := NewSummaryService(summarizer, nil, SummaryOptions{})
+
+	summary, err := service.SummarizeStructured(context.Background(), SummaryRequest{Articles: articles})
+	assert.NoError(t, err)
+	assert.NotEqual(t, articles[1].ID(), SanitizeArticle(articles[1]).ID())
+	assert.Equal(t, []string{articles[0].ID()}, summary.Structured.KeyPoints[0].ArticleIDs)
+	assert.Equal(t, []string{articles[1].ID()}, summary.Structured.Risks[0].ArticleIDs)
+}
@@ -0,0 +1,104 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/apperrors"
+	"github.com/akhlexe/stocknews-api/internal/models"
+)
+
+const (
+	ProviderOllama = "ollama"
+	ProviderOpenAI = "openai"
+
+	DefaultModel   = "llama3"
+	DefaultTimeout = 60 * time.Second
+)
+
+// Summarizer generates text from a prompt using a language model backend.
+type Summarizer interface {
+	Generate(ctx context.Context, prompt string) (string, error)
+
+	// Model returns the name of the model used for generation.
+	Model() string
+}
+
+// StreamingSummarizer is implemented by backends that can emit tokens as the
+// model produces them. onToken is called for every chunk; returning an error
+// from it aborts generation. The full text is returned once the model is done.
+type StreamingSummarizer interface {
+	Summarizer
+	GenerateStream(ctx context.Context, prompt string, onToken func(token string) error) (string, error)
+}
+
+// JSONSummarizer is implemented by backends that can constrain their output
+// to a JSON schema.
+type JSONSummarizer interface {
+	Summarizer
+	GenerateJSON(ctx context.Context, prompt string, schema json.RawMessage) (string, error)
+}
+
+// Config configures a Summarizer backend.
+type Config struct {
+	Provider    string
+	BaseURL     string
+	Model       string
+	APIKey      string
+	Temperature float64
+	Timeout     time.Duration
+}
+
+// NewSummarizer builds the backend selected by cfg.Provider.
+func NewSummarizer(cfg Config) (Summarizer, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("%w: missing AI base URL", apperrors.ErrConfiguration)
+	}
+	if cfg.Model == "" {
+		cfg.Model = DefaultModel
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+
+	switch cfg.Provider {
+	case "", ProviderOllama:
+		return NewOllamaSummarizer(cfg), nil
+	case ProviderOpenAI:
+		return NewOpenAISummarizer(cfg), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown AI provider %q", apperrors.ErrConfiguration, cfg.Provider)
+	}
+}
+
+// CombineArticles joins article titles and summaries into the text sent to the model.
+func CombineArticles(articles []models.Article) string {
+	var combined strings.Builder
+	for _, a := range articles {
+		combined.WriteString(a.Title + ":" + a.Summary + "\n")
+	}
+	return combined.String()
+}
+
+// doRequest sends req, translating transport failures into application errors.
+// Context errors are returned unwrapped so callers can map them to timeouts.
+func doRequest(ctx context.Context, client *http.Client, req *http.Request, backend string) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w: error requesting %s: %v", apperrors.ErrServiceUnavailable, backend, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s returned status code %d", apperrors.ErrServiceUnavailable, backend, resp.StatusCode)
+	}
+
+	return resp, nil
+}
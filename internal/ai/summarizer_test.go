@@ -0,0 +1,144 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/apperrors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOllamaSummarizer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/generate", r.URL.Path)
+
+		var req OllamaRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "mistral", req.Model)
+		assert.Equal(t, 0.3, req.Options.Temperature)
+		assert.False(t, req.Stream)
+
+		json.NewEncoder(w).Encode(OllamaResponse{Response: "summary", Done: true})
+	}))
+	defer server.Close()
+
+	summarizer, err := NewSummarizer(Config{BaseURL: server.URL, Model: "mistral", Temperature: 0.3})
+	assert.NoError(t, err)
+
+	result, err := summarizer.Generate(context.Background(), "prompt")
+	assert.NoError(t, err)
+	assert.Equal(t, "summary", result)
+}
+
+func TestOpenAISummarizer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		var req ChatCompletionRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, []ChatMessage{{Role: "user", Content: "prompt"}}, req.Messages)
+
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"summary"}}]}`))
+	}))
+	defer server.Close()
+
+	summarizer, err := NewSummarizer(Config{
+		Provider: ProviderOpenAI,
+		BaseURL:  server.URL + "/v1",
+		Model:    "local-model",
+		APIKey:   "secret",
+	})
+	assert.NoError(t, err)
+
+	result, err := summarizer.Generate(context.Background(), "prompt")
+	assert.NoError(t, err)
+	assert.Equal(t, "summary", result)
+}
+
+func TestSummarizerErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("slow") != "" {
+			time.Sleep(200 * time.Millisecond)
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	summarizer, _ := NewSummarizer(Config{BaseURL: server.URL})
+	_, err := summarizer.Generate(context.Background(), "prompt")
+	assert.ErrorIs(t, err, apperrors.ErrServiceUnavailable)
+
+	slow, _ := NewSummarizer(Config{BaseURL: server.URL + "/?slow=1", Timeout: 50 * time.Millisecond})
+	_, err = slow.Generate(context.Background(), "prompt")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = summarizer.Generate(ctx, "prompt")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = NewSummarizer(Config{BaseURL: server.URL, Provider: "unknown"})
+	assert.ErrorIs(t, err, apperrors.ErrConfiguration)
+}
+
+func TestOllamaGenerateStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.True(t, req.Stream)
+
+		w.Write([]byte(`{"response":"Shares ","done":false}` + "\n"))
+		w.Write([]byte(`{"response":"rose.","done":false}` + "\n"))
+		w.Write([]byte(`{"response":"","done":true}` + "\n"))
+	}))
+	defer server.Close()
+
+	summarizer := NewOllamaSummarizer(Config{BaseURL: server.URL, Model: DefaultModel, Timeout: time.Second})
+
+	var tokens []string
+	full, err := summarizer.GenerateStream(context.Background(), "prompt", func(token string) error {
+		tokens = append(tokens, token)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Shares rose.", full)
+	assert.Equal(t, []string{"Shares ", "rose."}, tokens)
+}
+
+func TestOllamaEmbedder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/embeddings", r.URL.Path)
+
+		var req OllamaEmbeddingRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, DefaultEmbeddingModel, req.Model)
+
+		if req.Prompt == "empty" {
+			w.Write([]byte(`{"embedding":[]}`))
+			return
+		}
+		w.Write([]byte(`{"embedding":[0.5,-1,2]}`))
+	}))
+	defer server.Close()
+
+	embedder, err := NewOllamaEmbedder(Config{BaseURL: server.URL})
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultEmbeddingModel, embedder.Model())
+
+	vector, err := embedder.Embed(context.Background(), "text")
+	assert.NoError(t, err)
+	assert.Equal(t, []float32{0.5, -1, 2}, vector)
+
+	_, err = embedder.Embed(context.Background(), "empty")
+	assert.ErrorIs(t, err, apperrors.ErrInternal)
+
+	_, err = NewOllamaEmbedder(Config{})
+	assert.ErrorIs(t, err, apperrors.ErrConfiguration)
+}
@@ -0,0 +1,480 @@
+package alerts
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/akhlexe/stocknews-api/internal/stream"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultCooldown       = 15 * time.Minute
+	DefaultDedupWindow    = 24 * time.Hour
+	DefaultHistory        = 200
+	DefaultWorkers        = 2
+	DefaultQueueSize      = 100
+	DefaultAttempts       = 3
+	DefaultRetryDelay     = 2 * time.Second
+	DefaultReloadInterval = time.Minute
+
+	notifyTimeout = 15 * time.Second
+
+	// trackerName identifies the alerted tickers to the stream hub.
+	trackerName = "alerts"
+)
+
+// Store persists alert rules.
+type Store interface {
+	SaveAlertRule(ctx context.Context, id string, rule []byte) error
+	GetAlertRules(ctx context.Context) ([][]byte, error)
+	GetAlertRule(ctx context.Context, id string) ([]byte, bool, error)
+	DeleteAlertRule(ctx context.Context, id string) (bool, error)
+}
+
+// Options tunes the engine. Zero values use the defaults.
+type Options struct {
+	// Cooldown applies to rules that don't set their own.
+	Cooldown time.Duration
+
+	// DedupWindow is how long an article that triggered a rule is remembered,
+	// so the same article seen again, or for another ticker, doesn't alert
+	// twice.
+	DedupWindow time.Duration
+
+	// History is the number of recent alerts kept for listing.
+	History int
+
+	// Workers and QueueSize size the pool sending notifications.
+	Workers   int
+	QueueSize int
+
+	// Attempts is the number of tries per channel, RetryDelay apart.
+	Attempts   int
+	RetryDelay time.Duration
+
+	// ReloadInterval is how often rules are reread from the store, to pick up
+	// those created or deleted by other processes.
+	ReloadInterval time.Duration
+}
+
+// Alert is an article that triggered a rule. KeyID is the rule's.
+type Alert struct {
+	ID          string         `json:"id"`
+	KeyID       string         `json:"key_id,omitempty"`
+	RuleID      string         `json:"rule_id"`
+	RuleName    string         `json:"rule_name"`
+	Ticker      string         `json:"ticker"`
+	Article     models.Article `json:"article"`
+	TriggeredAt time.Time      `json:"triggered_at"`
+}
+
+// notification is an alert waiting to be sent over one channel.
+type notification struct {
+	channel Channel
+	alert   Alert
+}
+
+// Engine evaluates every new article from the stream hub against the alert
+// rules and sends the resulting alerts.
+type Engine struct {
+	store     Store
+	hub       *stream.Hub
+	notifiers map[string]Notifier
+	opts      Options
+	events    *stream.Subscription
+	queue     chan notification
+
+	mu        sync.RWMutex
+	rules     map[string]*compiledRule
+	lastFired map[string]time.Time
+	alerted   map[string]time.Time
+	history   []Alert
+}
+
+// NewEngine creates an engine sending over the given notifiers, keyed by
+// channel type. Rules can only use channel types that have a notifier.
+func NewEngine(store Store, hub *stream.Hub, notifiers map[string]Notifier, opts Options) *Engine {
+	if opts.Cooldown <= 0 {
+		opts.Cooldown = DefaultCooldown
+	}
+	if opts.DedupWindow <= 0 {
+		opts.DedupWindow = DefaultDedupWindow
+	}
+	if opts.History <= 0 {
+		opts.History = DefaultHistory
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultWorkers
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultQueueSize
+	}
+	if opts.Attempts <= 0 {
+		opts.Attempts = DefaultAttempts
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = DefaultRetryDelay
+	}
+	if opts.ReloadInterval <= 0 {
+		opts.ReloadInterval = DefaultReloadInterval
+	}
+
+	return &Engine{
+		store:     store,
+		hub:       hub,
+		notifiers: notifiers,
+		opts:      opts,
+		events:    hub.Subscribe(nil, 0),
+		queue:     make(chan notification, opts.QueueSize),
+		rules:     make(map[string]*compiledRule),
+		lastFired: make(map[string]time.Time),
+		alerted:   make(map[string]time.Time),
+	}
+}
+
+// Load reads the stored rules.
+func (e *Engine) Load(ctx context.Context) error {
+	count, err := e.reload(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Int("count", count).Msg("Loaded alert rules")
+	return nil
+}
+
+// reload replaces the rules with the stored ones, which other processes may
+// have changed, and returns how many there are.
+func (e *Engine) reload(ctx context.Context) (int, error) {
+	stored, err := e.store.GetAlertRules(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	rules := make(map[string]*compiledRule, len(stored))
+	for _, data := range stored {
+		compiled, err := e.decode(data)
+		if err != nil {
+			log.Warn().Err(err).Msg("Skipping stored alert rule")
+			continue
+		}
+		rules[compiled.ID] = compiled
+	}
+
+	e.mu.Lock()
+	e.rules = rules
+	for id := range e.lastFired {
+		if _, ok := rules[id]; !ok {
+			delete(e.lastFired, id)
+		}
+	}
+	e.mu.Unlock()
+
+	e.track()
+	return len(rules), nil
+}
+
+// decode reads a stored rule.
+func (e *Engine) decode(data []byte) (*compiledRule, error) {
+	var rule Rule
+	if err := json.Unmarshal(data, &rule); err != nil {
+		return nil, err
+	}
+	compiled, err := compile(rule, e.opts.Cooldown)
+	if err != nil {
+		return nil, fmt.Errorf("rule %s: %w", rule.ID, err)
+	}
+	return compiled, nil
+}
+
+// Create validates and stores a rule.
+func (e *Engine) Create(ctx context.Context, rule Rule) (Rule, error) {
+	compiled, err := compile(rule, e.opts.Cooldown)
+	if err != nil {
+		return Rule{}, err
+	}
+	compiled.Channels = make([]Channel, len(rule.Channels))
+	for i, ch := range rule.Channels {
+		notifier, ok := e.notifiers[ch.Type]
+		if !ok {
+			return Rule{}, fmt.Errorf("%w: %q channels are not available", ErrInvalidChannel, ch.Type)
+		}
+		if ch.Target, err = notifier.Validate(ch.Target); err != nil {
+			return Rule{}, err
+		}
+		compiled.Channels[i] = ch
+	}
+
+	if compiled.ID, err = randomID(); err != nil {
+		return Rule{}, err
+	}
+	compiled.CreatedAt = time.Now().UTC()
+
+	data, err := json.Marshal(compiled.Rule)
+	if err != nil {
+		return Rule{}, err
+	}
+	if err := e.store.SaveAlertRule(ctx, compiled.ID, data); err != nil {
+		return Rule{}, err
+	}
+
+	e.mu.Lock()
+	e.rules[compiled.ID] = compiled
+	e.mu.Unlock()
+	e.track()
+
+	log.Info().Str("rule_id", compiled.ID).Strs("tickers", rule.Tickers).Msg("Alert rule created")
+	return compiled.Rule, nil
+}
+
+// List returns every rule, oldest first. Rules created or deleted by other
+// processes are listed from the next reload.
+func (e *Engine) List() []Rule {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	rules := make([]Rule, 0, len(e.rules))
+	for _, r := range e.rules {
+		rules = append(rules, r.Rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].CreatedAt.Before(rules[j].CreatedAt) })
+	return rules
+}
+
+// Get reads a rule from the store, as another process may have created or
+// deleted it, and updates the loaded rules to match.
+func (e *Engine) Get(ctx context.Context, id string) (Rule, bool, error) {
+	data, ok, err := e.store.GetAlertRule(ctx, id)
+	if err != nil {
+		return Rule{}, false, err
+	}
+
+	var compiled *compiledRule
+	if ok {
+		if compiled, err = e.decode(data); err != nil {
+			return Rule{}, false, err
+		}
+	}
+
+	e.mu.Lock()
+	if ok {
+		e.rules[id] = compiled
+	} else {
+		delete(e.rules, id)
+		delete(e.lastFired, id)
+	}
+	e.mu.Unlock()
+	e.track()
+
+	if !ok {
+		return Rule{}, false, nil
+	}
+	return compiled.Rule, true, nil
+}
+
+func (e *Engine) Delete(ctx context.Context, id string) (bool, error) {
+	deleted, err := e.store.DeleteAlertRule(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	e.mu.Lock()
+	_, known := e.rules[id]
+	delete(e.rules, id)
+	delete(e.lastFired, id)
+	e.mu.Unlock()
+	e.track()
+
+	return deleted || known, nil
+}
+
+// Alerts returns the most recent alerts, newest first, optionally for one
+// rule only.
+func (e *Engine) Alerts(ruleID string) []Alert {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	alerts := []Alert{}
+	for i := len(e.history) - 1; i >= 0; i-- {
+		if ruleID == "" || e.history[i].RuleID == ruleID {
+			alerts = append(alerts, e.history[i])
+		}
+	}
+	return alerts
+}
+
+// Run evaluates new articles and sends alerts, rereading the stored rules
+// every ReloadInterval, until ctx is done.
+func (e *Engine) Run(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(e.opts.ReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := e.reload(ctx); err != nil {
+					log.Error().Err(err).Msg("Error reloading alert rules")
+				}
+			}
+		}
+	}()
+
+	for i := 0; i < e.opts.Workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case n := <-e.queue:
+					e.notify(ctx, n)
+				}
+			}
+		}()
+	}
+
+	e.hub.Consume(ctx, e.events, func(event stream.Event) {
+		for _, alert := range e.evaluate(event.Ticker, event.Article, time.Now().UTC()) {
+			e.dispatch(alert)
+		}
+	})
+}
+
+// evaluate returns the alerts an article triggers and records them. A rule
+// doesn't alert twice for one article, nor again within its cooldown.
+func (e *Engine) evaluate(ticker string, a models.Article, now time.Time) []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.pruneAlerted(now)
+
+	var alerts []Alert
+	for _, rule := range e.rules {
+		if !rule.matches(ticker, a) {
+			continue
+		}
+
+		key := rule.ID + "/" + a.ID()
+		if _, seen := e.alerted[key]; seen {
+			continue
+		}
+		e.alerted[key] = now
+
+		if last, ok := e.lastFired[rule.ID]; ok && now.Sub(last) < rule.cooldown {
+			log.Debug().Str("rule_id", rule.ID).Str("article_id", a.ID()).Msg("Alert suppressed by cooldown")
+			continue
+		}
+		e.lastFired[rule.ID] = now
+
+		id, err := randomID()
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to generate alert ID")
+			continue
+		}
+		alert := Alert{ID: id, KeyID: rule.KeyID, RuleID: rule.ID, RuleName: rule.Name, Ticker: ticker, Article: a, TriggeredAt: now}
+		alerts = append(alerts, alert)
+
+		e.history = append(e.history, alert)
+		if len(e.history) > e.opts.History {
+			e.history = e.history[len(e.history)-e.opts.History:]
+		}
+	}
+
+	return alerts
+}
+
+// pruneAlerted forgets articles past the dedup window. The caller holds mu.
+func (e *Engine) pruneAlerted(now time.Time) {
+	for key, at := range e.alerted {
+		if now.Sub(at) > e.opts.DedupWindow {
+			delete(e.alerted, key)
+		}
+	}
+}
+
+// dispatch queues an alert for each of its rule's channels without blocking
+// the evaluation of further articles.
+func (e *Engine) dispatch(alert Alert) {
+	e.mu.RLock()
+	rule, ok := e.rules[alert.RuleID]
+	e.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	log.Info().Str("rule_id", alert.RuleID).Str("ticker", alert.Ticker).Str("article_id", alert.Article.ID()).Msg("Alert triggered")
+
+	for _, ch := range rule.Channels {
+		select {
+		case e.queue <- notification{channel: ch, alert: alert}:
+		default:
+			log.Warn().Str("rule_id", alert.RuleID).Str("channel", ch.Type).Msg("Alert queue is full; dropping notification")
+		}
+	}
+}
+
+func (e *Engine) notify(ctx context.Context, n notification) {
+	notifier, ok := e.notifiers[n.channel.Type]
+	if !ok {
+		return
+	}
+
+	alertLog := log.With().Str("alert_id", n.alert.ID).Str("rule_id", n.alert.RuleID).Str("channel", n.channel.Type).Logger()
+
+	for attempt := 1; attempt <= e.opts.Attempts; attempt++ {
+		notifyCtx, cancel := context.WithTimeout(ctx, notifyTimeout)
+		err := notifier.Notify(notifyCtx, n.channel.Target, n.alert)
+		cancel()
+		if err == nil {
+			alertLog.Debug().Int("attempt", attempt).Msg("Alert sent")
+			return
+		}
+		alertLog.Warn().Err(err).Int("attempt", attempt).Msg("Failed to send alert")
+		if attempt == e.opts.Attempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Duration(attempt) * e.opts.RetryDelay):
+		}
+	}
+
+	alertLog.Error().Int("attempts", e.opts.Attempts).Msg("Giving up on alert")
+}
+
+// track tells the hub which tickers to keep refreshing for the rules.
+func (e *Engine) track() {
+	e.mu.RLock()
+	set := make(map[string]bool)
+	var tickers []string
+	for _, r := range e.rules {
+		for _, t := range r.Tickers {
+			if !set[t] {
+				set[t] = true
+				tickers = append(tickers, t)
+			}
+		}
+	}
+	e.mu.RUnlock()
+
+	e.hub.Track(trackerName, tickers)
+}
+
+func randomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
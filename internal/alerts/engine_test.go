@@ -0,0 +1,254 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/akhlexe/stocknews-api/internal/stream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	mu    sync.Mutex
+	rules map[string][]byte
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{rules: make(map[string][]byte)}
+}
+
+func (s *memoryStore) SaveAlertRule(ctx context.Context, id string, rule []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules[id] = rule
+	return nil
+}
+
+func (s *memoryStore) GetAlertRules(ctx context.Context) ([][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rules [][]byte
+	for _, data := range s.rules {
+		rules = append(rules, data)
+	}
+	return rules, nil
+}
+
+func (s *memoryStore) GetAlertRule(ctx context.Context, id string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.rules[id]
+	return data, ok, nil
+}
+
+func (s *memoryStore) DeleteAlertRule(ctx context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.rules[id]
+	delete(s.rules, id)
+	return ok, nil
+}
+
+// recordingNotifier records alerts and fails the first failures sends.
+type recordingNotifier struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+	sent     []Alert
+}
+
+func (n *recordingNotifier) Validate(target string) (string, error) {
+	if target == "" {
+		return "", ErrInvalidChannel
+	}
+	return target, nil
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, target string, alert Alert) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.calls++
+	if n.calls <= n.failures {
+		return errors.New("unavailable")
+	}
+	n.sent = append(n.sent, alert)
+	return nil
+}
+
+func (n *recordingNotifier) alerts() []Alert {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]Alert(nil), n.sent...)
+}
+
+func newTestEngine(notifier Notifier, opts Options) *Engine {
+	return NewEngine(newMemoryStore(), stream.NewHub(stream.Options{}), map[string]Notifier{ChannelWebhook: notifier}, opts)
+}
+
+func TestEngineEvaluateDedupAndCooldown(t *testing.T) {
+	engine := newTestEngine(&recordingNotifier{}, Options{})
+	rule, err := engine.Create(context.Background(), Rule{
+		Name:     "Recalls",
+		Tickers:  []string{"TSLA", "F"},
+		Query:    "recall*",
+		Channels: []Channel{{Type: ChannelWebhook, Target: "hook"}},
+		Cooldown: "10m",
+	})
+	require.NoError(t, err)
+
+	now := time.Date(2026, 10, 19, 14, 0, 0, 0, time.UTC)
+	recall := models.Article{Title: "Tesla recalls vehicles", URL: "https://example.com/1"}
+	other := models.Article{Title: "Ford recall widens", URL: "https://example.com/2"}
+
+	alerts := engine.evaluate("TSLA", recall, now)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, rule.ID, alerts[0].RuleID)
+	assert.Equal(t, "Recalls", alerts[0].RuleName)
+
+	// The same article for another ticker is a duplicate.
+	assert.Empty(t, engine.evaluate("F", recall, now.Add(time.Hour)))
+
+	// A different article within the cooldown is suppressed, and not alerted
+	// later either.
+	assert.Empty(t, engine.evaluate("F", other, now.Add(5*time.Minute)))
+	assert.Empty(t, engine.evaluate("F", other, now.Add(20*time.Minute)))
+
+	// Non-matching articles don't start a cooldown.
+	assert.Empty(t, engine.evaluate("TSLA", models.Article{Title: "Tesla earnings", URL: "https://example.com/3"}, now.Add(11*time.Minute)))
+
+	next := models.Article{Title: "Another recall", URL: "https://example.com/4"}
+	assert.Len(t, engine.evaluate("TSLA", next, now.Add(11*time.Minute)), 1)
+
+	history := engine.Alerts(rule.ID)
+	require.Len(t, history, 2)
+	assert.Equal(t, next.URL, history[0].Article.URL)
+	assert.Empty(t, engine.Alerts("unknown"))
+}
+
+func TestEngineDedupWindowExpires(t *testing.T) {
+	engine := newTestEngine(&recordingNotifier{}, Options{DedupWindow: time.Hour})
+	_, err := engine.Create(context.Background(), Rule{
+		Tickers:  []string{"TSLA"},
+		Channels: []Channel{{Type: ChannelWebhook, Target: "hook"}},
+		Cooldown: "1s",
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	article := models.Article{Title: "Tesla", URL: "https://example.com/1"}
+	assert.Len(t, engine.evaluate("TSLA", article, now), 1)
+	assert.Empty(t, engine.evaluate("TSLA", article, now.Add(30*time.Minute)))
+	assert.Len(t, engine.evaluate("TSLA", article, now.Add(2*time.Hour)), 1)
+}
+
+func TestEngineRunSendsAlertsWithRetries(t *testing.T) {
+	notifier := &recordingNotifier{failures: 1}
+	hub := stream.NewHub(stream.Options{})
+	engine := NewEngine(newMemoryStore(), hub, map[string]Notifier{ChannelWebhook: notifier}, Options{RetryDelay: time.Millisecond})
+
+	_, err := engine.Create(context.Background(), Rule{
+		Tickers:  []string{"TSLA"},
+		Channels: []Channel{{Type: ChannelWebhook, Target: "hook"}},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go engine.Run(ctx)
+
+	hub.Observe("TSLA", nil)
+	hub.Observe("TSLA", []models.Article{{Title: "Tesla news", URL: "https://example.com/1"}})
+
+	require.Eventually(t, func() bool { return len(notifier.alerts()) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "Tesla news", notifier.alerts()[0].Article.Title)
+}
+
+func TestEngineCreateValidatesChannels(t *testing.T) {
+	engine := newTestEngine(&recordingNotifier{}, Options{})
+
+	_, err := engine.Create(context.Background(), Rule{
+		Tickers:  []string{"TSLA"},
+		Channels: []Channel{{Type: ChannelEmail, Target: "desk@example.com"}},
+	})
+	assert.ErrorIs(t, err, ErrInvalidChannel)
+
+	_, err = engine.Create(context.Background(), Rule{
+		Tickers:  []string{"TSLA"},
+		Channels: []Channel{{Type: ChannelWebhook, Target: ""}},
+	})
+	assert.ErrorIs(t, err, ErrInvalidChannel)
+}
+
+func TestEngineLoadAndDelete(t *testing.T) {
+	store := newMemoryStore()
+	hub := stream.NewHub(stream.Options{})
+	notifiers := map[string]Notifier{ChannelWebhook: &recordingNotifier{}}
+
+	engine := NewEngine(store, hub, notifiers, Options{})
+	rule, err := engine.Create(context.Background(), Rule{
+		Name:     "Recalls",
+		Tickers:  []string{"TSLA"},
+		Query:    "recall",
+		Channels: []Channel{{Type: ChannelWebhook, Target: "hook"}},
+	})
+	require.NoError(t, err)
+
+	restarted := NewEngine(store, hub, notifiers, Options{})
+	require.NoError(t, restarted.Load(context.Background()))
+	loaded, ok, err := restarted.Get(context.Background(), rule.ID)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "recall", loaded.Query)
+	assert.Len(t, restarted.evaluate("TSLA", models.Article{Title: "Recall", URL: "https://example.com/1"}, time.Now()), 1)
+
+	deleted, err := restarted.Delete(context.Background(), rule.ID)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	assert.Empty(t, restarted.List())
+	assert.Empty(t, restarted.evaluate("TSLA", models.Article{Title: "Recall", URL: "https://example.com/2"}, time.Now()))
+}
+
+func TestEngineSeesRulesChangedByReplicas(t *testing.T) {
+	store := newMemoryStore()
+	hub := stream.NewHub(stream.Options{})
+	notifiers := map[string]Notifier{ChannelWebhook: &recordingNotifier{}}
+
+	replica := NewEngine(store, hub, notifiers, Options{})
+	require.NoError(t, replica.Load(context.Background()))
+
+	// Created on another process after the replica loaded.
+	other := NewEngine(store, hub, notifiers, Options{})
+	rule, err := other.Create(context.Background(), Rule{
+		Tickers:  []string{"TSLA"},
+		Channels: []Channel{{Type: ChannelWebhook, Target: "hook"}},
+	})
+	require.NoError(t, err)
+
+	got, ok, err := replica.Get(context.Background(), rule.ID)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, []string{"TSLA"}, got.Tickers)
+
+	_, err = other.Delete(context.Background(), rule.ID)
+	require.NoError(t, err)
+	_, ok, err = replica.Get(context.Background(), rule.ID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Rules created elsewhere are evaluated from the next reload.
+	_, err = other.Create(context.Background(), Rule{
+		Tickers:  []string{"AAPL"},
+		Channels: []Channel{{Type: ChannelWebhook, Target: "hook"}},
+	})
+	require.NoError(t, err)
+	count, err := replica.reload(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Len(t, replica.evaluate("AAPL", models.Article{Title: "Apple", URL: "https://example.com/1"}, time.Now()), 1)
+	assert.Empty(t, replica.evaluate("TSLA", models.Article{Title: "Tesla", URL: "https://example.com/2"}, time.Now()))
+}
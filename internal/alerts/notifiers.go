@@ -0,0 +1,187 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/safehttp"
+)
+
+// Notifier sends alerts over one kind of channel.
+type Notifier interface {
+	// Validate checks that target is something the notifier can send to and
+	// returns it in the form to store.
+	Validate(target string) (string, error)
+
+	Notify(ctx context.Context, target string, alert Alert) error
+}
+
+// WebhookNotifier posts the alert as JSON. Targets are chosen by API clients,
+// so Client should refuse private addresses, as safehttp.NewClient does.
+type WebhookNotifier struct {
+	Client *http.Client
+}
+
+func (n *WebhookNotifier) Validate(target string) (string, error) {
+	return target, validateURL(target)
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, target string, alert Alert) error {
+	return postJSON(ctx, n.Client, target, alert)
+}
+
+// SlackNotifier posts a one-line message to a Slack-compatible incoming
+// webhook. Like WebhookNotifier's, Client should refuse private addresses.
+type SlackNotifier struct {
+	Client *http.Client
+}
+
+func (n *SlackNotifier) Validate(target string) (string, error) {
+	return target, validateURL(target)
+}
+
+func (n *SlackNotifier) Notify(ctx context.Context, target string, alert Alert) error {
+	return postJSON(ctx, n.Client, target, map[string]string{"text": slackText(alert)})
+}
+
+// EmailNotifier sends a plain-text email through an SMTP server. Auth may be
+// nil for servers that accept mail without it.
+type EmailNotifier struct {
+	Addr string
+	From string
+	Auth smtp.Auth
+}
+
+// Validate accepts an address with or without a display name, e.g.
+// "Desk <desk@example.com>", and keeps only the address.
+func (n *EmailNotifier) Validate(target string) (string, error) {
+	addr, err := mail.ParseAddress(target)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid email address %q", ErrInvalidChannel, target)
+	}
+	return addr.Address, nil
+}
+
+// Notify sends the email. net/smtp has no context support, so ctx only stops
+// a send that hasn't started.
+func (n *EmailNotifier) Notify(ctx context.Context, target string, alert Alert) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// Rules stored before targets were normalized may still have a display
+	// name, which SMTP recipients can't carry.
+	addr, err := mail.ParseAddress(target)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(n.Addr, n.Auth, n.From, []string{addr.Address}, emailMessage(n.From, addr.Address, alert))
+}
+
+// validateURL checks that target is an absolute http or https URL that doesn't
+// name a local or private address outright.
+func validateURL(target string) error {
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: target must be an absolute http or https URL", ErrInvalidChannel)
+	}
+	if err := safehttp.CheckHost(u.Hostname()); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidChannel, err)
+	}
+	return nil
+}
+
+func postJSON(ctx context.Context, client *http.Client, target string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// subject is the alert's one-line description.
+func subject(alert Alert) string {
+	return fmt.Sprintf("[%s] %s", alert.Ticker, alert.Article.Title)
+}
+
+// slackText links the headline and escapes the characters Slack treats as
+// markup.
+func slackText(alert Alert) string {
+	escape := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace
+
+	headline := escape(alert.Article.Title)
+	if alert.Article.URL != "" {
+		headline = fmt.Sprintf("<%s|%s>", escape(alert.Article.URL), headline)
+	}
+
+	text := fmt.Sprintf("*[%s]* %s", escape(alert.Ticker), headline)
+	if details := alertDetails(alert); details != "" {
+		text += " (" + escape(details) + ")"
+	}
+	return text + " · rule " + escape(alert.RuleName)
+}
+
+// alertDetails is the source and sentiment, where known.
+func alertDetails(alert Alert) string {
+	var details []string
+	if alert.Article.Source != "" {
+		details = append(details, alert.Article.Source)
+	}
+	if alert.Article.Sentiment != "" {
+		details = append(details, alert.Article.Sentiment)
+	}
+	return strings.Join(details, ", ")
+}
+
+func emailMessage(from, to string, alert Alert) []byte {
+	// Headers come from article text, which must not be able to add headers.
+	header := strings.NewReplacer("\r", " ", "\n", " ").Replace
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", header(from))
+	fmt.Fprintf(&b, "To: %s\r\n", header(to))
+	fmt.Fprintf(&b, "Subject: %s\r\n", header(subject(alert)))
+	fmt.Fprintf(&b, "Date: %s\r\n", alert.TriggeredAt.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+
+	fmt.Fprintf(&b, "Rule: %s\r\n", alert.RuleName)
+	fmt.Fprintf(&b, "Ticker: %s\r\n", alert.Ticker)
+	if details := alertDetails(alert); details != "" {
+		fmt.Fprintf(&b, "Details: %s\r\n", details)
+	}
+	if alert.Article.URL != "" {
+		fmt.Fprintf(&b, "Link: %s\r\n", alert.Article.URL)
+	}
+	if alert.Article.Summary != "" {
+		fmt.Fprintf(&b, "\r\n%s\r\n", alert.Article.Summary)
+	}
+
+	return []byte(b.String())
+}
@@ -0,0 +1,198 @@
+package alerts
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testAlert = Alert{
+	ID:       "alert-1",
+	RuleID:   "rule-1",
+	RuleName: "Tesla recalls",
+	Ticker:   "TSLA",
+	Article: models.Article{
+		Title:     "Tesla recalls <2M> vehicles",
+		URL:       "https://example.com/recall",
+		Summary:   "Regulators opened a safety probe.",
+		Source:    "Reuters",
+		Sentiment: "Bearish",
+	},
+	TriggeredAt: time.Date(2026, 10, 19, 14, 0, 0, 0, time.UTC),
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var received Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	notifier := &WebhookNotifier{Client: server.Client()}
+	require.NoError(t, notifier.Notify(context.Background(), server.URL, testAlert))
+	assert.Equal(t, testAlert.ID, received.ID)
+	assert.Equal(t, testAlert.Article.URL, received.Article.URL)
+
+	target, err := notifier.Validate("https://hooks.example.com/alerts")
+	require.NoError(t, err)
+	assert.Equal(t, "https://hooks.example.com/alerts", target)
+	for _, target := range []string{"mailto:desk@example.com", server.URL, "http://localhost/alerts", "http://169.254.169.254/latest"} {
+		_, err := notifier.Validate(target)
+		assert.ErrorIs(t, err, ErrInvalidChannel, target)
+	}
+}
+
+func TestWebhookNotifierErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	notifier := &WebhookNotifier{Client: server.Client()}
+	assert.Error(t, notifier.Notify(context.Background(), server.URL, testAlert))
+}
+
+func TestSlackNotifier(t *testing.T) {
+	var body map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	notifier := &SlackNotifier{Client: server.Client()}
+	require.NoError(t, notifier.Notify(context.Background(), server.URL, testAlert))
+	assert.Equal(t,
+		"*[TSLA]* <https://example.com/recall|Tesla recalls &lt;2M&gt; vehicles> (Reuters, Bearish) · rule Tesla recalls",
+		body["text"])
+}
+
+// smtpServer is a minimal SMTP stand-in that records the messages it accepts.
+type smtpServer struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	messages []smtpMessage
+}
+
+type smtpMessage struct {
+	from, to, data string
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &smtpServer{listener: listener}
+	go s.serve()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+func (s *smtpServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpServer) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+
+	reply("220 localhost ESMTP test")
+	var msg smtpMessage
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			msg.from = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			msg.to = strings.Trim(line[len("RCPT TO:"):], "<> ")
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			msg.data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (s *smtpServer) received() []smtpMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpMessage(nil), s.messages...)
+}
+
+func TestEmailNotifier(t *testing.T) {
+	server := newSMTPServer(t)
+	notifier := &EmailNotifier{Addr: server.listener.Addr().String(), From: "alerts@example.com"}
+
+	target, err := notifier.Validate("Trading Desk <desk@example.com>")
+	require.NoError(t, err)
+	assert.Equal(t, "desk@example.com", target, "only the address is kept")
+	_, err = notifier.Validate("not an address")
+	assert.ErrorIs(t, err, ErrInvalidChannel)
+
+	alert := testAlert
+	alert.Article.Title = "Tesla recalls\r\nBcc: everyone@example.com"
+	require.NoError(t, notifier.Notify(context.Background(), "Trading Desk <desk@example.com>", alert))
+
+	messages := server.received()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "alerts@example.com", messages[0].from)
+	assert.Equal(t, "desk@example.com", messages[0].to)
+
+	data := messages[0].data
+	assert.Contains(t, data, "Subject: [TSLA] Tesla recalls  Bcc: everyone@example.com\r\n")
+	assert.NotContains(t, data, "\r\nBcc:")
+	assert.Contains(t, data, "Rule: Tesla recalls\r\n")
+	assert.Contains(t, data, "Link: https://example.com/recall\r\n")
+	assert.Contains(t, data, "Regulators opened a safety probe.")
+}
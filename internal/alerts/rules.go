@@ -0,0 +1,132 @@
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/filter"
+	"github.com/akhlexe/stocknews-api/internal/models"
+)
+
+// Channel types.
+const (
+	ChannelWebhook = "webhook"
+	ChannelSlack   = "slack"
+	ChannelEmail   = "email"
+)
+
+var (
+	// ErrInvalidRule is returned for a rule that can't be saved.
+	ErrInvalidRule = errors.New("invalid alert rule")
+
+	// ErrInvalidChannel is returned for a channel of unknown or unconfigured
+	// type, or with a target its notifier can't send to.
+	ErrInvalidChannel = errors.New("invalid alert channel")
+)
+
+// Rule describes the articles a trader wants to be alerted about and where to
+// send the alerts. Every condition that is set must hold. KeyID is the API key
+// that created it, empty without authentication.
+type Rule struct {
+	ID      string   `json:"id"`
+	KeyID   string   `json:"key_id,omitempty"`
+	Name    string   `json:"name"`
+	Tickers []string `json:"tickers"`
+
+	// Query is a filter.Expression over the title and summary.
+	Query string `json:"query,omitempty"`
+
+	// MinSentiment and MaxSentiment bound the article's sentiment score
+	// (-1 to 1), e.g. MaxSentiment -0.35 for bearish news only.
+	MinSentiment *float64 `json:"min_sentiment,omitempty"`
+	MaxSentiment *float64 `json:"max_sentiment,omitempty"`
+
+	// Sources lists the publishers to alert on.
+	Sources []string `json:"sources,omitempty"`
+
+	Channels []Channel `json:"channels"`
+
+	// Cooldown is the minimum time between two alerts of the rule, e.g. "15m".
+	// Matches during the cooldown are suppressed.
+	Cooldown string `json:"cooldown,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Channel is where a rule's alerts are sent. Target is a URL for webhook and
+// slack channels and an address for email.
+type Channel struct {
+	Type   string `json:"type"`
+	Target string `json:"target"`
+}
+
+// compiledRule is a rule with its query and cooldown parsed.
+type compiledRule struct {
+	Rule
+	query    *filter.Expression
+	cooldown time.Duration
+}
+
+// compile validates a rule. defaultCooldown applies when it sets none.
+func compile(rule Rule, defaultCooldown time.Duration) (*compiledRule, error) {
+	if len(rule.Tickers) == 0 {
+		return nil, fmt.Errorf("%w: no tickers", ErrInvalidRule)
+	}
+	if len(rule.Channels) == 0 {
+		return nil, fmt.Errorf("%w: no channels", ErrInvalidRule)
+	}
+	if rule.MinSentiment != nil && rule.MaxSentiment != nil && *rule.MinSentiment > *rule.MaxSentiment {
+		return nil, fmt.Errorf("%w: min_sentiment is above max_sentiment", ErrInvalidRule)
+	}
+
+	compiled := &compiledRule{Rule: rule, cooldown: defaultCooldown}
+
+	if strings.TrimSpace(rule.Query) != "" {
+		query, err := filter.ParseExpression(rule.Query)
+		if err != nil {
+			return nil, err
+		}
+		compiled.query = query
+	}
+
+	if rule.Cooldown != "" {
+		cooldown, err := time.ParseDuration(rule.Cooldown)
+		if err != nil || cooldown < 0 {
+			return nil, fmt.Errorf("%w: invalid cooldown %q", ErrInvalidRule, rule.Cooldown)
+		}
+		compiled.cooldown = cooldown
+	}
+
+	return compiled, nil
+}
+
+// matches reports whether an article seen for ticker satisfies the rule.
+func (r *compiledRule) matches(ticker string, a models.Article) bool {
+	if !containsFold(r.Tickers, ticker) {
+		return false
+	}
+	if len(r.Sources) > 0 && !containsFold(r.Sources, a.Source) {
+		return false
+	}
+
+	score := filter.SentimentScore(a)
+	if r.MinSentiment != nil && score < *r.MinSentiment {
+		return false
+	}
+	if r.MaxSentiment != nil && score > *r.MaxSentiment {
+		return false
+	}
+
+	return r.query == nil || r.query.Match(a)
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
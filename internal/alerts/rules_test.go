@@ -0,0 +1,84 @@
+package alerts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/filter"
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func float(v float64) *float64 { return &v }
+
+var webhookChannel = []Channel{{Type: ChannelWebhook, Target: "https://example.com/hook"}}
+
+func TestRuleMatches(t *testing.T) {
+	article := models.Article{
+		Title:          "Tesla recalls 2 million vehicles",
+		Summary:        "Regulators opened a safety probe.",
+		Source:         "Reuters",
+		Sentiment:      "Bearish",
+		SentimentScore: -0.5,
+	}
+
+	tests := []struct {
+		name   string
+		rule   Rule
+		ticker string
+		want   bool
+	}{
+		{"ticker", Rule{Tickers: []string{"TSLA"}}, "TSLA", true},
+		{"other ticker", Rule{Tickers: []string{"TSLA"}}, "AAPL", false},
+		{"query", Rule{Tickers: []string{"TSLA"}, Query: "recall* AND NOT rumor"}, "TSLA", true},
+		{"query mismatch", Rule{Tickers: []string{"TSLA"}, Query: "earnings"}, "TSLA", false},
+		{"bearish only", Rule{Tickers: []string{"TSLA"}, MaxSentiment: float(-0.35)}, "TSLA", true},
+		{"bullish only", Rule{Tickers: []string{"TSLA"}, MinSentiment: float(0.35)}, "TSLA", false},
+		{"sentiment range", Rule{Tickers: []string{"TSLA"}, MinSentiment: float(-0.6), MaxSentiment: float(-0.4)}, "TSLA", true},
+		{"source", Rule{Tickers: []string{"TSLA"}, Sources: []string{"reuters"}}, "TSLA", true},
+		{"source mismatch", Rule{Tickers: []string{"TSLA"}, Sources: []string{"Bloomberg"}}, "TSLA", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.Channels = webhookChannel
+			compiled, err := compile(tt.rule, time.Minute)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, compiled.matches(tt.ticker, article))
+		})
+	}
+}
+
+func TestRuleSentimentFromLabel(t *testing.T) {
+	// Without a provider score the label decides.
+	compiled, err := compile(Rule{Tickers: []string{"AAPL"}, Channels: webhookChannel, MinSentiment: float(0.35)}, time.Minute)
+	require.NoError(t, err)
+
+	assert.True(t, compiled.matches("AAPL", models.Article{Sentiment: "Bullish"}))
+	assert.False(t, compiled.matches("AAPL", models.Article{Sentiment: "Somewhat-Bullish"}))
+}
+
+func TestCompileRule(t *testing.T) {
+	compiled, err := compile(Rule{Tickers: []string{"AAPL"}, Channels: webhookChannel}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, compiled.cooldown)
+
+	compiled, err = compile(Rule{Tickers: []string{"AAPL"}, Channels: webhookChannel, Cooldown: "1h"}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, compiled.cooldown)
+
+	invalid := []Rule{
+		{Channels: webhookChannel},
+		{Tickers: []string{"AAPL"}},
+		{Tickers: []string{"AAPL"}, Channels: webhookChannel, Cooldown: "soon"},
+		{Tickers: []string{"AAPL"}, Channels: webhookChannel, MinSentiment: float(0.5), MaxSentiment: float(0)},
+	}
+	for _, rule := range invalid {
+		_, err := compile(rule, time.Minute)
+		assert.ErrorIs(t, err, ErrInvalidRule)
+	}
+
+	_, err = compile(Rule{Tickers: []string{"AAPL"}, Channels: webhookChannel, Query: "(recall"}, time.Minute)
+	assert.ErrorIs(t, err, filter.ErrInvalidExpression)
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/akhlexe/stocknews-api/internal/alerts"
+	"github.com/akhlexe/stocknews-api/internal/filter"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// maxRuleTickers caps the number of tickers in one alert rule.
+const maxRuleTickers = 50
+
+// handleCreateAlertRule validates and stores an alert rule.
+func handleCreateAlertRule(c *gin.Context, engine *alerts.Engine) {
+	var rule alerts.Rule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		log.Warn().Err(err).Msg("Invalid alert rule request body")
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid request body.")
+		return
+	}
+
+	if len(rule.Tickers) == 0 || len(rule.Tickers) > maxRuleTickers {
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid tickers parameter.")
+		return
+	}
+	for _, ticker := range rule.Tickers {
+		if !validTickerRegex.MatchString(ticker) {
+			log.Warn().Str("ticker", ticker).Msg("Invalid ticker format")
+			writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid ticker format.")
+			return
+		}
+	}
+
+	if engine == nil {
+		writeError(c, http.StatusServiceUnavailable, codeNotConfigured, "Alerts are not configured.")
+		return
+	}
+
+	rule.KeyID = requestKeyID(c)
+	created, err := engine.Create(c, rule)
+	switch {
+	case errors.Is(err, filter.ErrInvalidExpression):
+		log.Warn().Err(err).Msg("Invalid alert rule query")
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid query parameter.")
+		return
+	case errors.Is(err, alerts.ErrInvalidChannel):
+		log.Warn().Err(err).Msg("Invalid alert rule channel")
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid channels parameter.")
+		return
+	case errors.Is(err, alerts.ErrInvalidRule):
+		log.Warn().Err(err).Msg("Invalid alert rule")
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid alert rule.")
+		return
+	case err != nil:
+		log.Error().Err(err).Msg("Failed to create alert rule")
+		writeError(c, http.StatusInternalServerError, codeInternal, "Internal server error.")
+		return
+	}
+
+	c.Header("Location", resourcePath(c, "/alerts/rules/"+created.ID))
+	respondStatus(c, http.StatusCreated, nil, created)
+}
+
+func handleListAlertRules(c *gin.Context, engine *alerts.Engine) {
+	if engine == nil {
+		writeError(c, http.StatusServiceUnavailable, codeNotConfigured, "Alerts are not configured.")
+		return
+	}
+
+	keyID := requestKeyID(c)
+	rules := []alerts.Rule{}
+	for _, rule := range engine.List() {
+		if rule.KeyID == keyID {
+			rules = append(rules, rule)
+		}
+	}
+
+	respond(c, nil, gin.H{"rules": rules})
+}
+
+func handleGetAlertRule(c *gin.Context, engine *alerts.Engine) {
+	if engine == nil {
+		writeError(c, http.StatusServiceUnavailable, codeNotConfigured, "Alerts are not configured.")
+		return
+	}
+
+	rule, ok := ownedAlertRule(c, engine)
+	if !ok {
+		return
+	}
+
+	respond(c, nil, rule)
+}
+
+func handleDeleteAlertRule(c *gin.Context, engine *alerts.Engine) {
+	if engine == nil {
+		writeError(c, http.StatusServiceUnavailable, codeNotConfigured, "Alerts are not configured.")
+		return
+	}
+
+	if _, ok := ownedAlertRule(c, engine); !ok {
+		return
+	}
+
+	deleted, err := engine.Delete(c, c.Param("id"))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to delete alert rule")
+		writeError(c, http.StatusInternalServerError, codeInternal, "Internal server error.")
+		return
+	}
+	if !deleted {
+		writeError(c, http.StatusNotFound, codeNotFound, "Alert rule not found.")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// handleListAlerts returns the most recent alerts of the request's key,
+// optionally for one rule.
+func handleListAlerts(c *gin.Context, engine *alerts.Engine) {
+	if engine == nil {
+		writeError(c, http.StatusServiceUnavailable, codeNotConfigured, "Alerts are not configured.")
+		return
+	}
+
+	keyID := requestKeyID(c)
+	owned := []alerts.Alert{}
+	for _, alert := range engine.Alerts(c.Query("rule_id")) {
+		if alert.KeyID == keyID {
+			owned = append(owned, alert)
+		}
+	}
+
+	respond(c, nil, gin.H{"alerts": owned})
+}
+
+// ownedAlertRule returns the rule of the id parameter if it belongs to the
+// request's key, and writes the error response when it doesn't.
+func ownedAlertRule(c *gin.Context, engine *alerts.Engine) (alerts.Rule, bool) {
+	rule, ok, err := engine.Get(c, c.Param("id"))
+	if err != nil {
+		log.Error().Err(err).Str("rule_id", c.Param("id")).Msg("Failed to get alert rule")
+		writeError(c, http.StatusInternalServerError, codeInternal, "Internal server error.")
+		return alerts.Rule{}, false
+	}
+	if !ok || rule.KeyID != requestKeyID(c) {
+		writeError(c, http.StatusNotFound, codeNotFound, "Alert rule not found.")
+		return alerts.Rule{}, false
+	}
+	return rule, true
+}
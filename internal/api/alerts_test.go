@@ -0,0 +1,162 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/akhlexe/stocknews-api/internal/alerts"
+	"github.com/akhlexe/stocknews-api/internal/stream"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// alertRuleStore keeps alert rules in memory.
+type alertRuleStore struct {
+	mu    sync.Mutex
+	rules map[string][]byte
+}
+
+func (s *alertRuleStore) SaveAlertRule(ctx context.Context, id string, rule []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules[id] = rule
+	return nil
+}
+
+func (s *alertRuleStore) GetAlertRules(ctx context.Context) ([][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rules [][]byte
+	for _, data := range s.rules {
+		rules = append(rules, data)
+	}
+	return rules, nil
+}
+
+func (s *alertRuleStore) GetAlertRule(ctx context.Context, id string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.rules[id]
+	return data, ok, nil
+}
+
+func (s *alertRuleStore) DeleteAlertRule(ctx context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.rules[id]
+	delete(s.rules, id)
+	return ok, nil
+}
+
+func setupAlertRouter(engine *alerts.Engine) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/alerts/rules", func(c *gin.Context) { handleCreateAlertRule(c, engine) })
+	router.GET("/alerts/rules", func(c *gin.Context) { handleListAlertRules(c, engine) })
+	router.GET("/alerts/rules/:id", func(c *gin.Context) { handleGetAlertRule(c, engine) })
+	router.DELETE("/alerts/rules/:id", func(c *gin.Context) { handleDeleteAlertRule(c, engine) })
+	router.GET("/alerts", func(c *gin.Context) { handleListAlerts(c, engine) })
+	return router
+}
+
+func newAlertEngine() *alerts.Engine {
+	store := &alertRuleStore{rules: make(map[string][]byte)}
+	notifiers := map[string]alerts.Notifier{
+		alerts.ChannelWebhook: &alerts.WebhookNotifier{Client: http.DefaultClient},
+		alerts.ChannelSlack:   &alerts.SlackNotifier{Client: http.DefaultClient},
+	}
+	return alerts.NewEngine(store, stream.NewHub(stream.Options{}), notifiers, alerts.Options{})
+}
+
+func TestAlertRuleLifecycle(t *testing.T) {
+	router := setupAlertRouter(newAlertEngine())
+
+	w := httptest.NewRecorder()
+	body := `{"name": "Recalls", "tickers": ["TSLA"], "query": "recall* AND NOT rumor", "max_sentiment": -0.35, "channels": [{"type": "slack", "target": "https://hooks.slack.com/services/T/B/X"}], "cooldown": "30m"}`
+	req, _ := http.NewRequest(http.MethodPost, "/alerts/rules", bytes.NewBufferString(body))
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created alerts.Rule
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, "recall* AND NOT rumor", created.Query)
+	assert.Equal(t, "/alerts/rules/"+created.ID, w.Header().Get("Location"))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/alerts/rules", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), created.ID)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/alerts/rules/"+created.ID, nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/alerts?rule_id="+created.ID, nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"alerts":[]}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodDelete, "/alerts/rules/"+created.ID, nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(method, "/alerts/rules/"+created.ID, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code, method)
+	}
+}
+
+func TestCreateAlertRuleValidation(t *testing.T) {
+	router := setupAlertRouter(newAlertEngine())
+	webhook := `"channels": [{"type": "webhook", "target": "https://example.com/hook"}]`
+
+	tests := []struct {
+		name  string
+		body  string
+		error string
+	}{
+		{"invalid body", `{`, "Invalid request body."},
+		{"no tickers", `{"tickers": [], ` + webhook + `}`, "Invalid tickers parameter."},
+		{"invalid ticker", `{"tickers": ["tsla"], ` + webhook + `}`, "Invalid ticker format."},
+		{"invalid query", `{"tickers": ["TSLA"], "query": "(recall", ` + webhook + `}`, "Invalid query parameter."},
+		{"unavailable channel", `{"tickers": ["TSLA"], "channels": [{"type": "email", "target": "desk@example.com"}]}`, "Invalid channels parameter."},
+		{"invalid target", `{"tickers": ["TSLA"], "channels": [{"type": "webhook", "target": "/hook"}]}`, "Invalid channels parameter."},
+		{"no channels", `{"tickers": ["TSLA"]}`, "Invalid alert rule."},
+		{"invalid cooldown", `{"tickers": ["TSLA"], "cooldown": "soon", ` + webhook + `}`, "Invalid alert rule."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/alerts/rules", bytes.NewBufferString(tt.body))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, `{"error": "`+tt.error+`"}`, w.Body.String())
+		})
+	}
+}
+
+func TestAlertsNotConfigured(t *testing.T) {
+	router := setupAlertRouter(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/alerts", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.JSONEq(t, `{"error": "Alerts are not configured."}`, w.Body.String())
+}
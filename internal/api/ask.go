@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/akhlexe/stocknews-api/internal/ai"
+	"github.com/akhlexe/stocknews-api/internal/filter"
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/akhlexe/stocknews-api/internal/news"
+	"github.com/akhlexe/stocknews-api/internal/semantic"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// askArticles is the number of retrieved articles given to the model.
+const askArticles = 8
+
+type askRequest struct {
+	Question string `json:"question"`
+}
+
+// askCitation is an article cited by an answer.
+type askCitation struct {
+	Index int    `json:"index"`
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// handleAsk answers a question from the ticker's news. The most relevant
+// articles are retrieved by keyword score, plus by meaning when semantic
+// search is configured, and the model answers from those alone.
+func handleAsk(c *gin.Context, fetcher news.Provider, summaries *ai.SummaryService, search *semantic.Service) {
+	ticker := c.Param("ticker")
+	requestLog := log.With().Str("ticker", ticker).Logger()
+
+	if !validTickerRegex.MatchString(ticker) {
+		requestLog.Warn().Msg("Invalid ticker format")
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid ticker format.")
+		return
+	}
+
+	var body askRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		requestLog.Warn().Err(err).Msg("Invalid ask request body")
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid request body.")
+		return
+	}
+
+	question := strings.TrimSpace(body.Question)
+	if question == "" {
+		writeError(c, http.StatusBadRequest, codeMissingParameter, "Missing question.")
+		return
+	}
+	if utf8.RuneCountInString(question) > ai.MaxQuestionChars {
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Question is too long.")
+		return
+	}
+
+	if summaries == nil {
+		requestLog.Warn().Msg("Question asked but no AI backend is configured")
+		writeError(c, http.StatusServiceUnavailable, codeNotConfigured, "AI summarization is not configured.")
+		return
+	}
+
+	fetchCtx, report := news.WithFetchReport(c)
+	fetchCtx, cancelFetch := context.WithTimeout(fetchCtx, 10*time.Second)
+	defer cancelFetch()
+
+	articles, err := fetcher.GetNewsByTicker(fetchCtx, ticker)
+	if err != nil {
+		requestLog.Error().Err(err).Msg("Error processing ask request")
+		writeFetchError(c, err)
+		return
+	}
+
+	answerCtx, cancelAnswer := context.WithTimeout(c.Request.Context(), summaryTimeout)
+	defer cancelAnswer()
+	answerCtx = ai.WithSlotWait(answerCtx, summarySlotWait)
+
+	relevant := retrieveArticles(answerCtx, articles, question, ticker, search)
+
+	answer, err := summaries.Answer(answerCtx, ai.AnswerRequest{Ticker: ticker, Question: question, Articles: relevant})
+	if err != nil {
+		requestLog.Error().Err(err).Msg("Failed to answer question")
+		writeAIError(c, err)
+		return
+	}
+
+	citations := make([]askCitation, 0, len(answer.Citations))
+	for _, citation := range answer.Citations {
+		citations = append(citations, askCitation{
+			Index: citation.Index,
+			ID:    citation.Article.ID(),
+			Title: citation.Article.Title,
+			URL:   citation.Article.URL,
+		})
+	}
+
+	requestLog.Info().
+		Bool("answered", answer.Answered).
+		Int("articles_included", answer.ArticlesIncluded).
+		Int("citations", len(citations)).
+		Msg("Answered question")
+
+	respond(c, report, gin.H{
+		"ticker":            ticker,
+		"question":          question,
+		"answer":            answer.Text,
+		"answered":          answer.Answered,
+		"citations":         citations,
+		"prompt_version":    answer.PromptVersion,
+		"warnings":          nonNilStrings(answer.Warnings),
+		"articles_included": answer.ArticlesIncluded,
+	})
+}
+
+// retrieveArticles picks the articles most relevant to question, best first.
+// Semantic matches for the ticker and keyword matches take turns so neither
+// crowds out the other; duplicates are dropped.
+func retrieveArticles(ctx context.Context, articles []models.Article, question, ticker string, search *semantic.Service) []models.Article {
+	var semanticMatches []models.Article
+	if search != nil {
+		matches, err := search.Search(ctx, question, askArticles, ticker)
+		if err != nil {
+			// Keyword retrieval still works without the embedding backend.
+			log.Warn().Err(err).Str("ticker", ticker).Msg("Semantic retrieval failed")
+		}
+		semanticMatches = matches
+	}
+
+	scored := filter.ScoreBM25(articles, question, filter.DefaultRankOptions)
+	filter.SortArticles(scored, filter.SortRelevance)
+	var keywordMatches []models.Article
+	for _, a := range scored {
+		if a.Score > 0 {
+			keywordMatches = append(keywordMatches, a)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var relevant []models.Article
+	for i := 0; len(relevant) < askArticles && (i < len(semanticMatches) || i < len(keywordMatches)); i++ {
+		for _, list := range [][]models.Article{semanticMatches, keywordMatches} {
+			if i >= len(list) || seen[list[i].ID()] || len(relevant) == askArticles {
+				continue
+			}
+			seen[list[i].ID()] = true
+			relevant = append(relevant, list[i])
+		}
+	}
+
+	// Questions such as "what happened today?" match no terms; fall back to
+	// the latest articles.
+	if len(relevant) == 0 {
+		latest := make([]models.Article, len(articles))
+		copy(latest, articles)
+		filter.SortArticles(latest, filter.SortNewest)
+		if len(latest) > askArticles {
+			latest = latest[:askArticles]
+		}
+		relevant = latest
+	}
+
+	return relevant
+}
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/akhlexe/stocknews-api/internal/ai"
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/akhlexe/stocknews-api/internal/news"
+	"github.com/akhlexe/stocknews-api/internal/semantic"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupAskRouter(fetcher news.Provider, summaries *ai.SummaryService, search *semantic.Service) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/news/:ticker/ask", func(c *gin.Context) {
+		handleAsk(c, fetcher, summaries, search)
+	})
+	return router
+}
+
+var askTestArticles = []models.Article{
+	{Title: "Tesla opens factory", URL: "https://example.com/factory", Summary: "A new plant in Texas."},
+	{Title: "Tesla recall", URL: "https://example.com/recall", Summary: "Brake recall of Model Y vehicles."},
+}
+
+func TestHandleAsk(t *testing.T) {
+	testCases := []struct {
+		name           string
+		ticker         string
+		body           string
+		mockSetup      func(*MockNewsProvider, *MockSummarizer)
+		nilSummaries   bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "Answers with citations",
+			ticker: "TSLA",
+			body:   `{"question": "What is the recall about?"}`,
+			mockSetup: func(f *MockNewsProvider, s *MockSummarizer) {
+				f.On("GetNewsByTicker", mock.Anything, "TSLA").Return(askTestArticles, nil).Once()
+				// Only the article matching the question is retrieved.
+				s.On("Generate", mock.Anything, mock.MatchedBy(func(prompt string) bool {
+					return strings.Contains(prompt, "Tesla recall") && !strings.Contains(prompt, "factory")
+				})).Return("Model Y vehicles are recalled over brakes [1].", nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{
+				"ticker": "TSLA",
+				"question": "What is the recall about?",
+				"answer": "Model Y vehicles are recalled over brakes [1].",
+				"answered": true,
+				"citations": [{"index": 1, "id": "` + askTestArticles[1].ID() + `", "title": "Tesla recall", "url": "https://example.com/recall"}],
+				"prompt_version": "answer@v1",
+				"warnings": [],
+				"articles_included": 1
+			}`,
+		},
+		{
+			name:   "Not answerable",
+			ticker: "TSLA",
+			body:   `{"question": "Who designed the factory?"}`,
+			mockSetup: func(f *MockNewsProvider, s *MockSummarizer) {
+				f.On("GetNewsByTicker", mock.Anything, "TSLA").Return(askTestArticles, nil).Once()
+				s.On("Generate", mock.Anything, mock.Anything).Return("NO_ANSWER", nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{
+				"ticker": "TSLA",
+				"question": "Who designed the factory?",
+				"answer": "The available articles do not answer this question.",
+				"answered": false,
+				"citations": [],
+				"prompt_version": "answer@v1",
+				"warnings": [],
+				"articles_included": 1
+			}`,
+		},
+		{
+			name:   "No matching terms uses latest articles",
+			ticker: "TSLA",
+			body:   `{"question": "Anything worth knowing?"}`,
+			mockSetup: func(f *MockNewsProvider, s *MockSummarizer) {
+				f.On("GetNewsByTicker", mock.Anything, "TSLA").Return(askTestArticles, nil).Once()
+				s.On("Generate", mock.Anything, mock.Anything).Return("A factory opened [1] and a recall began [2].", nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{
+				"ticker": "TSLA",
+				"question": "Anything worth knowing?",
+				"answer": "A factory opened [1] and a recall began [2].",
+				"answered": true,
+				"citations": [
+					{"index": 1, "id": "` + askTestArticles[0].ID() + `", "title": "Tesla opens factory", "url": "https://example.com/factory"},
+					{"index": 2, "id": "` + askTestArticles[1].ID() + `", "title": "Tesla recall", "url": "https://example.com/recall"}
+				],
+				"prompt_version": "answer@v1",
+				"warnings": [],
+				"articles_included": 2
+			}`,
+		},
+		{
+			name:           "Missing question",
+			ticker:         "TSLA",
+			body:           `{"question": "  "}`,
+			mockSetup:      func(f *MockNewsProvider, s *MockSummarizer) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error": "Missing question."}`,
+		},
+		{
+			name:           "Question too long",
+			ticker:         "TSLA",
+			body:           `{"question": "` + strings.Repeat("a", ai.MaxQuestionChars+1) + `"}`,
+			mockSetup:      func(f *MockNewsProvider, s *MockSummarizer) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error": "Question is too long."}`,
+		},
+		{
+			name:           "Invalid body",
+			ticker:         "TSLA",
+			body:           `question`,
+			mockSetup:      func(f *MockNewsProvider, s *MockSummarizer) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error": "Invalid request body."}`,
+		},
+		{
+			name:           "Invalid ticker",
+			ticker:         "tsla",
+			body:           `{"question": "Why?"}`,
+			mockSetup:      func(f *MockNewsProvider, s *MockSummarizer) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error": "Invalid ticker format."}`,
+		},
+		{
+			name:           "AI not configured",
+			ticker:         "TSLA",
+			body:           `{"question": "Why?"}`,
+			mockSetup:      func(f *MockNewsProvider, s *MockSummarizer) {},
+			nilSummaries:   true,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"error": "AI summarization is not configured."}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockFetcher := new(MockNewsProvider)
+			mockSummarizer := new(MockSummarizer)
+			tc.mockSetup(mockFetcher, mockSummarizer)
+
+			summaries := ai.NewSummaryService(mockSummarizer, nil, ai.SummaryOptions{})
+			if tc.nilSummaries {
+				summaries = nil
+			}
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/news/"+tc.ticker+"/ask", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			setupAskRouter(mockFetcher, summaries, nil).ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.JSONEq(t, tc.expectedBody, w.Body.String())
+			mockFetcher.AssertExpectations(t)
+			mockSummarizer.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRetrieveArticlesCombinesSemanticAndKeywordMatches(t *testing.T) {
+	search := semantic.NewService(&keywordEmbedder{words: []string{"brake"}}, nil, 0)
+	semanticOnly := models.Article{Title: "Braking system defect", Summary: "brake issue", URL: "https://example.com/brakes", Tickers: []string{"TSLA"}}
+	search.Index(context.Background(), semanticOnly)
+	search.Index(context.Background(), askTestArticles[1])
+
+	relevant := retrieveArticles(context.Background(), askTestArticles, "What is the brake recall?", "TSLA", search)
+
+	var urls []string
+	for _, a := range relevant {
+		urls = append(urls, a.URL)
+	}
+	// askTestArticles[1] is untagged, so semantic search skips it for TSLA,
+	// but keyword search finds it.
+	assert.Equal(t, []string{"https://example.com/brakes", "https://example.com/recall"}, urls)
+}
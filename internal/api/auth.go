@@ -0,0 +1,186 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// apiKeyContextKey holds the authenticated auth.Key in the gin context.
+	apiKeyContextKey = "api_key"
+
+	// defaultUsageDays is the number of days of usage listed when no days
+	// parameter is given.
+	defaultUsageDays = 30
+	maxUsageDays     = 90
+)
+
+// publicPaths are served without an API key.
+var publicPaths = map[string]bool{
+	"/health":       true,
+	"/openapi.json": true,
+	"/docs":         true,
+}
+
+// authMiddleware requires an API key on every route but the public ones and
+// meters the request against the key's scope.
+func authMiddleware(service *auth.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if publicPaths[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		secret := apiKeySecret(c)
+		if secret == "" {
+			c.Header("WWW-Authenticate", "Bearer")
+			abortWithError(c, http.StatusUnauthorized, codeUnauthorized, "Missing API key.")
+			return
+		}
+
+		key, err := service.Authenticate(c, secret)
+		if errors.Is(err, auth.ErrInvalidKey) {
+			c.Header("WWW-Authenticate", "Bearer")
+			abortWithError(c, http.StatusUnauthorized, codeUnauthorized, "Invalid API key.")
+			return
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to authenticate API key")
+			abortWithError(c, http.StatusInternalServerError, codeInternal, "Internal server error.")
+			return
+		}
+
+		endpoint := c.Request.Method + " " + c.FullPath()
+		scope := requestScope(c)
+		requestLog := log.With().Str("key_id", key.ID).Str("scope", scope).Logger()
+
+		retryAfter, err := service.Allow(c, key, scope, endpoint)
+		switch {
+		case errors.Is(err, auth.ErrScopeDenied):
+			requestLog.Warn().Msg("API key lacks scope")
+			abortWithError(c, http.StatusForbidden, codeForbidden, "API key lacks the "+scope+" scope.")
+			return
+		case errors.Is(err, auth.ErrRateLimited):
+			requestLog.Warn().Dur("retry_after", retryAfter).Msg("API key rate limited")
+			writeRetryAfter(c, retryAfter)
+			abortWithError(c, http.StatusTooManyRequests, codeRateLimited, "Rate limit exceeded.")
+			return
+		case errors.Is(err, auth.ErrQuotaExceeded):
+			requestLog.Warn().Msg("API key daily quota exceeded")
+			writeRetryAfter(c, retryAfter)
+			abortWithError(c, http.StatusTooManyRequests, codeQuotaExceeded, "Daily quota exceeded.")
+			return
+		case err != nil:
+			requestLog.Error().Err(err).Msg("Failed to meter API key request")
+			abortWithError(c, http.StatusInternalServerError, codeInternal, "Internal server error.")
+			return
+		}
+
+		c.Set(apiKeyContextKey, key)
+		c.Next()
+	}
+}
+
+// apiKeySecret reads the key from the X-API-Key header, a bearer token, or the
+// api_key query parameter for clients such as EventSource that can't set
+// headers.
+func apiKeySecret(c *gin.Context) string {
+	if secret := c.GetHeader("X-API-Key"); secret != "" {
+		return secret
+	}
+	if header := c.GetHeader("Authorization"); len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return c.Query("api_key")
+}
+
+// requestKeyID is the ID of the key the request was authenticated with, or ""
+// when authentication is disabled. Subscriptions, alert rules and watchlists
+// are only visible to the key that created them.
+func requestKeyID(c *gin.Context) string {
+	if value, ok := c.Get(apiKeyContextKey); ok {
+		return value.(auth.Key).ID
+	}
+	return ""
+}
+
+// requestScope is auth.ScopeSummarize for requests that run the LLM and
+// auth.ScopeNews for everything else.
+func requestScope(c *gin.Context) string {
+	path := strings.TrimPrefix(c.FullPath(), "/v1")
+	switch {
+	case c.Request.Method == http.MethodPost && path == "/summaries",
+		path == "/news/:ticker/summary/stream",
+		path == "/news/:ticker/ask",
+		path == "/news/:ticker" && c.Query("summarize") == "true":
+		return auth.ScopeSummarize
+	default:
+		return auth.ScopeNews
+	}
+}
+
+// writeRetryAfter sets Retry-After in whole seconds, rounding up.
+func writeRetryAfter(c *gin.Context, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(seconds))
+}
+
+// handleUsage returns the calling key's request counts by day, scope and
+// endpoint.
+func handleUsage(c *gin.Context, service *auth.Service) {
+	value, ok := c.Get(apiKeyContextKey)
+	if service == nil || !ok {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Authentication is not configured."})
+		return
+	}
+	key := value.(auth.Key)
+
+	days := defaultUsageDays
+	if daysParam := c.Query("days"); daysParam != "" {
+		parsed, err := strconv.Atoi(daysParam)
+		if err != nil || parsed < 1 || parsed > maxUsageDays {
+			log.Warn().Str("days", daysParam).Msg("Invalid days parameter")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid days parameter."})
+			return
+		}
+		days = parsed
+	}
+
+	since := time.Now().UTC().AddDate(0, 0, -(days - 1))
+	usage, err := service.Usage(c, key.ID, since)
+	if err != nil {
+		log.Error().Err(err).Str("key_id", key.ID).Msg("Failed to retrieve API key usage")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error."})
+		return
+	}
+
+	entries := make([]usageEntry, 0, len(usage))
+	for _, u := range usage {
+		entries = append(entries, usageEntry{
+			Day:      u.Day.Format("2006-01-02"),
+			Scope:    u.Scope,
+			Endpoint: u.Endpoint,
+			Requests: u.Requests,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"key_id": key.ID, "scopes": key.Scopes, "usage": entries})
+}
+
+type usageEntry struct {
+	Day      string `json:"day"`
+	Scope    string `json:"scope"`
+	Endpoint string `json:"endpoint"`
+	Requests int64  `json:"requests"`
+}
@@ -0,0 +1,257 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/auth"
+	"github.com/akhlexe/stocknews-api/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// apiKeyStore keeps API keys and usage in memory.
+type apiKeyStore struct {
+	mu    sync.Mutex
+	keys  map[string]storage.APIKey
+	usage []storage.APIKeyUsage
+}
+
+func (s *apiKeyStore) SaveAPIKey(ctx context.Context, key storage.APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[key.ID] = key
+	return nil
+}
+
+func (s *apiKeyStore) GetAPIKeyByHash(ctx context.Context, hash string) (storage.APIKey, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range s.keys {
+		if key.Hash == hash {
+			return key, true, nil
+		}
+	}
+	return storage.APIKey{}, false, nil
+}
+
+func (s *apiKeyStore) GetAPIKeys(ctx context.Context) ([]storage.APIKey, error) {
+	return nil, nil
+}
+
+func (s *apiKeyStore) RevokeAPIKey(ctx context.Context, id string, at time.Time) (bool, error) {
+	return false, nil
+}
+
+func (s *apiKeyStore) AddAPIKeyUsage(ctx context.Context, usage []storage.APIKeyUsage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.usage = append(s.usage, usage...)
+	return nil
+}
+
+func (s *apiKeyStore) GetAPIKeyUsage(ctx context.Context, keyID string, since time.Time) ([]storage.APIKeyUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var usage []storage.APIKeyUsage
+	for _, u := range s.usage {
+		if u.KeyID == keyID && !u.Day.Before(since) {
+			usage = append(usage, u)
+		}
+	}
+	return usage, nil
+}
+
+func setupAuthRouter(service *auth.Service) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(authMiddleware(service))
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"status": "ok"}) }
+	router.GET("/health", ok)
+	router.GET("/news/:ticker", ok)
+	router.GET("/v1/news/:ticker", ok)
+	router.POST("/summaries", ok)
+	router.GET("/usage", func(c *gin.Context) { handleUsage(c, service) })
+	return router
+}
+
+func newAuthService(t *testing.T, scopes map[string]auth.Limit) (*auth.Service, string) {
+	service := auth.NewService(&apiKeyStore{keys: make(map[string]storage.APIKey)}, auth.Options{})
+	_, secret, err := service.Create(context.Background(), "test", scopes)
+	require.NoError(t, err)
+	return service, secret
+}
+
+func TestAuthMiddlewareRequiresKey(t *testing.T) {
+	service, secret := newAuthService(t, map[string]auth.Limit{auth.ScopeNews: {}})
+	router := setupAuthRouter(service)
+
+	tests := []struct {
+		name   string
+		path   string
+		header map[string]string
+		status int
+		body   string
+	}{
+		{"public", "/health", nil, http.StatusOK, `{"status": "ok"}`},
+		{"missing", "/news/AAPL", nil, http.StatusUnauthorized, `{"error": "Missing API key."}`},
+		{"invalid", "/news/AAPL", map[string]string{"X-API-Key": "snk_nope"}, http.StatusUnauthorized, `{"error": "Invalid API key."}`},
+		{"header", "/news/AAPL", map[string]string{"X-API-Key": secret}, http.StatusOK, `{"status": "ok"}`},
+		{"bearer", "/news/AAPL", map[string]string{"Authorization": "Bearer " + secret}, http.StatusOK, `{"status": "ok"}`},
+		{"query", "/news/AAPL?api_key=" + secret, nil, http.StatusOK, `{"status": "ok"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.JSONEq(t, tt.body, w.Body.String())
+		})
+	}
+}
+
+func TestAuthMiddlewareMetersSummarizeScope(t *testing.T) {
+	service, secret := newAuthService(t, map[string]auth.Limit{auth.ScopeNews: {}})
+	router := setupAuthRouter(service)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/news/AAPL?summarize=true", nil),
+		httptest.NewRequest(http.MethodGet, "/v1/news/AAPL?summarize=true", nil),
+		httptest.NewRequest(http.MethodPost, "/summaries", nil),
+	} {
+		w := httptest.NewRecorder()
+		req.Header.Set("X-API-Key", secret)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code, req.URL.String())
+		assert.Contains(t, w.Body.String(), "API key lacks the summarize scope.")
+	}
+}
+
+func TestAuthMiddlewareRateLimits(t *testing.T) {
+	service, secret := newAuthService(t, map[string]auth.Limit{auth.ScopeNews: {PerMinute: 1}})
+	router := setupAuthRouter(service)
+
+	var w *httptest.ResponseRecorder
+	for i := 0; i < 2; i++ {
+		w = httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/news/AAPL", nil)
+		req.Header.Set("X-API-Key", secret)
+		router.ServeHTTP(w, req)
+	}
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"error": "Rate limit exceeded."}`, w.Body.String())
+}
+
+func TestUsageReportsRequestsByEndpoint(t *testing.T) {
+	service, secret := newAuthService(t, map[string]auth.Limit{auth.ScopeNews: {}})
+	router := setupAuthRouter(service)
+
+	for _, path := range []string{"/news/AAPL", "/news/MSFT", "/usage"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-API-Key", secret)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, path)
+
+		if path == "/usage" {
+			day := time.Now().UTC().Format("2006-01-02")
+			assert.Contains(t, w.Body.String(), `{"day":"`+day+`","scope":"news","endpoint":"GET /news/:ticker","requests":2}`)
+			assert.Contains(t, w.Body.String(), `{"day":"`+day+`","scope":"news","endpoint":"GET /usage","requests":1}`)
+		}
+	}
+}
+
+func TestRequestLogOmitsAPIKey(t *testing.T) {
+	service, secret := newAuthService(t, map[string]auth.Limit{auth.ScopeNews: {}})
+
+	var logs bytes.Buffer
+	defer func(logger zerolog.Logger, writer io.Writer) {
+		log.Logger, gin.DefaultWriter = logger, writer
+	}(log.Logger, gin.DefaultWriter)
+	log.Logger, gin.DefaultWriter = zerolog.New(&logs), &logs
+
+	w := httptest.NewRecorder()
+	(&Server{Auth: service}).Router().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/usage?api_key="+secret, nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, logs.String(), `"path":"/usage"`)
+	assert.NotContains(t, logs.String(), secret)
+}
+
+func TestResourcesBelongToTheirKey(t *testing.T) {
+	service, owner := newAuthService(t, map[string]auth.Limit{auth.ScopeNews: {}})
+	_, other, err := service.Create(context.Background(), "other", map[string]auth.Limit{auth.ScopeNews: {}})
+	require.NoError(t, err)
+
+	router := (&Server{
+		Auth:       service,
+		Webhooks:   newWebhookService(),
+		Alerts:     newAlertEngine(),
+		Watchlists: newWatchlistService(),
+	}).Router()
+	serve := func(secret, method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("X-API-Key", secret)
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	resources := []struct {
+		collection string
+		body       string
+		paths      []string
+	}{
+		{"/subscriptions", `{"url": "https://hooks.example.com/news", "tickers": ["AAPL"]}`, []string{"", "/deliveries"}},
+		{"/alerts/rules", `{"name": "Recalls", "tickers": ["TSLA"], "channels": [{"type": "slack", "target": "https://hooks.slack.com/services/T/B/X"}]}`, []string{""}},
+		{"/watchlists", `{"name": "Tech", "tickers": ["AAPL"]}`, []string{"", "/news"}},
+	}
+
+	for _, r := range resources {
+		t.Run(r.collection, func(t *testing.T) {
+			w := serve(owner, http.MethodPost, r.collection, r.body)
+			require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+			var created struct {
+				ID string `json:"id"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+			w = serve(other, http.MethodGet, r.collection, "")
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.NotContains(t, w.Body.String(), created.ID)
+			for _, path := range r.paths {
+				w = serve(other, http.MethodGet, r.collection+"/"+created.ID+path, "")
+				assert.Equal(t, http.StatusNotFound, w.Code, path)
+			}
+			w = serve(other, http.MethodDelete, r.collection+"/"+created.ID, "")
+			assert.Equal(t, http.StatusNotFound, w.Code)
+
+			w = serve(owner, http.MethodGet, r.collection, "")
+			assert.Contains(t, w.Body.String(), created.ID)
+			w = serve(owner, http.MethodDelete, r.collection+"/"+created.ID, "")
+			assert.Equal(t, http.StatusNoContent, w.Code)
+		})
+	}
+}
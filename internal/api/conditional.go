@@ -0,0 +1,96 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/ai"
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/akhlexe/stocknews-api/internal/news"
+	"github.com/gin-gonic/gin"
+)
+
+// articlesETag is an ETag for a response listing articles: the article-set
+// fingerprint, combined with the path and query since they select what is
+// listed and how, and with any other values the response includes.
+//
+// It is strong on legacy routes. /v1 bodies carry the per-request
+// meta.request_id, so they are only equivalent, not identical, and get a weak
+// ETag.
+func articlesETag(c *gin.Context, articles []models.Article, variant ...string) string {
+	fingerprint := ai.Fingerprint(articles, "", "")
+	key := fingerprint + "\n" + c.Request.URL.RequestURI() + "\n" + strings.Join(variant, "\n")
+	sum := sha256.Sum256([]byte(key))
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	if isV1(c) {
+		return "W/" + etag
+	}
+	return etag
+}
+
+// weakETag marks etag weak, as for a body that is equivalent but not
+// identical across responses.
+func weakETag(etag string) string {
+	if strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return "W/" + etag
+}
+
+// notModified sets ETag, Last-Modified and Cache-Control for a response built
+// from the fetches in report, then answers 304 Not Modified when the client's
+// copy is current. It reports whether it did.
+//
+// Last-Modified is the most recent upstream fetch, and max-age runs until the
+// first cached ticker expires. Stale articles are not cacheable.
+func notModified(c *gin.Context, report *news.FetchReport, etag string) bool {
+	lastModified := report.LastFetchedAt()
+
+	c.Header("ETag", etag)
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	remaining := time.Until(report.Expires())
+	if report.Expires().IsZero() || remaining < time.Second || report.Status() == news.CacheStale {
+		c.Header("Cache-Control", "private, no-cache")
+	} else {
+		c.Header("Cache-Control", "private, max-age="+strconv.Itoa(int(remaining.Seconds())))
+	}
+
+	if !requestIsCurrent(c.Request, etag, lastModified) {
+		return false
+	}
+
+	c.Status(http.StatusNotModified)
+	return true
+}
+
+// requestIsCurrent evaluates If-None-Match, or If-Modified-Since when there is
+// no If-None-Match, as RFC 9110 orders them.
+func requestIsCurrent(r *http.Request, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			// If-None-Match uses weak comparison.
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	ifModifiedSince := r.Header.Get("If-Modified-Since")
+	if ifModifiedSince == "" || lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
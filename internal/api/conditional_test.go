@@ -0,0 +1,120 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/akhlexe/stocknews-api/internal/news"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveConditional(provider news.Provider, path string, header map[string]string) *httptest.ResponseRecorder {
+	router := setupV1Router(provider)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestConditionalNews(t *testing.T) {
+	fetchedAt := time.Now().Add(-2 * time.Minute).Truncate(time.Second)
+	provider := &reportingProvider{
+		name:      "alphavantage",
+		status:    news.CacheMemory,
+		fetchedAt: fetchedAt,
+		expires:   fetchedAt.Add(10 * time.Minute),
+		articles: []models.Article{
+			{Title: "Apple beats estimates", URL: "https://example.com/1"},
+			{Title: "Apple ships", URL: "https://example.com/2"},
+		},
+	}
+
+	w := serveConditional(provider, "/news/AAPL", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, etag)
+	assert.Equal(t, fetchedAt.UTC().Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+	assert.Regexp(t, `^private, max-age=(47[0-9]|480)$`, w.Header().Get("Cache-Control"))
+
+	tests := []struct {
+		name   string
+		path   string
+		header map[string]string
+		status int
+	}{
+		{"matching etag", "/news/AAPL", map[string]string{"If-None-Match": etag}, http.StatusNotModified},
+		{"etag in a list", "/news/AAPL", map[string]string{"If-None-Match": `"other", W/` + etag}, http.StatusNotModified},
+		{"any etag", "/news/AAPL", map[string]string{"If-None-Match": "*"}, http.StatusNotModified},
+		{"other etag", "/news/AAPL", map[string]string{"If-None-Match": `"other"`}, http.StatusOK},
+		{"other query", "/news/AAPL?limit=1", map[string]string{"If-None-Match": etag}, http.StatusOK},
+		{"not modified since", "/news/AAPL", map[string]string{"If-Modified-Since": fetchedAt.UTC().Format(http.TimeFormat)}, http.StatusNotModified},
+		{"modified since", "/news/AAPL", map[string]string{"If-Modified-Since": fetchedAt.Add(-time.Hour).UTC().Format(http.TimeFormat)}, http.StatusOK},
+		{"etag wins over date", "/news/AAPL", map[string]string{
+			"If-None-Match":     `"other"`,
+			"If-Modified-Since": fetchedAt.UTC().Format(http.TimeFormat),
+		}, http.StatusOK},
+		{"v1", "/v1/news/AAPL", map[string]string{"If-Modified-Since": fetchedAt.UTC().Format(http.TimeFormat)}, http.StatusNotModified},
+		{"stories", "/news/AAPL/stories", map[string]string{"If-Modified-Since": fetchedAt.UTC().Format(http.TimeFormat)}, http.StatusNotModified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveConditional(provider, tt.path, tt.header)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.NotEmpty(t, w.Header().Get("ETag"))
+			if tt.status == http.StatusNotModified {
+				assert.Empty(t, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestConditionalV1UsesWeakETag(t *testing.T) {
+	provider := &reportingProvider{name: "alphavantage", status: news.CacheMemory, fetchedAt: time.Now(),
+		expires: time.Now().Add(10 * time.Minute), articles: []models.Article{{Title: "Apple ships", URL: "https://example.com/1"}}}
+
+	// The envelope's request_id differs on every response.
+	w := serveConditional(provider, "/v1/news/AAPL", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.Regexp(t, `^W/"[0-9a-f]{32}"$`, etag)
+
+	w = serveConditional(provider, "/v1/news/AAPL", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	w = serveConditional(provider, "/v1/news/AAPL", map[string]string{"If-None-Match": strings.TrimPrefix(etag, "W/")})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+}
+
+func TestConditionalNewsChangesWithArticles(t *testing.T) {
+	provider := &reportingProvider{name: "alphavantage", status: news.CacheUpstream, fetchedAt: time.Now(),
+		articles: []models.Article{{Title: "Apple ships", URL: "https://example.com/1"}}}
+	etag := serveConditional(provider, "/news/AAPL", nil).Header().Get("ETag")
+
+	provider.articles = append(provider.articles, models.Article{Title: "Apple beats estimates", URL: "https://example.com/2"})
+	w := serveConditional(provider, "/news/AAPL", map[string]string{"If-None-Match": etag})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+}
+
+func TestConditionalNewsNotCacheable(t *testing.T) {
+	stale := &reportingProvider{name: "alphavantage", status: news.CacheStale, fetchedAt: time.Now().Add(-time.Hour),
+		expires: time.Now().Add(-50 * time.Minute), articles: []models.Article{{Title: "Apple ships"}}}
+	w := serveConditional(stale, "/news/AAPL", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "private, no-cache", w.Header().Get("Cache-Control"))
+
+	w = serveConditional(stale, "/news/AAPL?summarize=true", map[string]string{"If-None-Match": "*"})
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "summaries are not conditional")
+	assert.Empty(t, w.Header().Get("ETag"))
+}
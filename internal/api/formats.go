@@ -0,0 +1,153 @@
+package api
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/formats"
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Output formats for articles. The summary formats produce JSON.
+const (
+	outputJSON   = "json"
+	outputRSS    = "rss"
+	outputAtom   = "atom"
+	outputCSV    = "csv"
+	outputNDJSON = "ndjson"
+)
+
+// outputMediaTypes maps the media types clients may Accept onto output
+// formats. Wildcards mean JSON.
+var outputMediaTypes = map[string]string{
+	"*/*":                  outputJSON,
+	"application/*":        outputJSON,
+	"application/json":     outputJSON,
+	"application/rss+xml":  outputRSS,
+	"application/atom+xml": outputAtom,
+	"text/csv":             outputCSV,
+	"application/x-ndjson": outputNDJSON,
+	"application/ndjson":   outputNDJSON,
+}
+
+// validNewsFormat reports whether format is a summary format, or an output
+// format for articles when no summary is requested.
+func validNewsFormat(format string, summarize bool) bool {
+	switch format {
+	case summaryFormatText, summaryFormatStructured:
+		return true
+	case outputJSON, outputRSS, outputAtom, outputCSV, outputNDJSON:
+		return !summarize
+	default:
+		return false
+	}
+}
+
+// articleOutput picks the output format for articles from the format
+// parameter, or from the Accept header when there is none. Summary formats
+// mean JSON, as they always have.
+func articleOutput(r *http.Request, format string, formatSet bool) string {
+	if !formatSet {
+		return negotiateOutput(r.Header.Get("Accept"))
+	}
+	if format == summaryFormatText || format == summaryFormatStructured {
+		return outputJSON
+	}
+	return format
+}
+
+// negotiateOutput picks the output format the Accept header prefers. Types it
+// doesn't offer are ignored, so clients that accept nothing it offers still
+// get JSON; on a tie the first listed wins.
+func negotiateOutput(accept string) string {
+	best, bestQuality := outputJSON, 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		output, ok := outputMediaTypes[mediaType]
+		if !ok {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality > bestQuality {
+			best, bestQuality = output, quality
+		}
+	}
+	return best
+}
+
+// writeArticles writes a page of a ticker's articles as RSS, Atom, CSV or
+// NDJSON. The next page, if any, is linked in a Link header.
+func writeArticles(c *gin.Context, output, ticker string, articles []models.Article, fields []string, nextCursor string, updated time.Time) error {
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+	feed := formats.Feed{
+		Title:       ticker + " news",
+		Description: "Financial news for " + ticker + ".",
+		URL:         requestURL(c, nil).String(),
+		Updated:     updated,
+	}
+
+	var body bytes.Buffer
+	var contentType string
+	var err error
+	switch output {
+	case outputRSS:
+		contentType = formats.ContentTypeRSS
+		err = formats.WriteRSS(&body, feed, articles)
+	case outputAtom:
+		contentType = formats.ContentTypeAtom
+		err = formats.WriteAtom(&body, feed, articles)
+	case outputCSV:
+		contentType = formats.ContentTypeCSV
+		c.Header("Content-Disposition", `attachment; filename="`+ticker+`-news.csv"`)
+		err = formats.WriteCSV(&body, articles, fields)
+	case outputNDJSON:
+		contentType = formats.ContentTypeNDJSON
+		err = formats.WriteNDJSON(&body, articles, fields)
+	}
+	if err != nil {
+		return err
+	}
+
+	if nextCursor != "" {
+		next := requestURL(c, url.Values{"cursor": {nextCursor}})
+		c.Header("Link", "<"+next.String()+`>; rel="next"`)
+	}
+
+	c.Data(http.StatusOK, contentType, body.Bytes())
+	return nil
+}
+
+// requestURL rebuilds the absolute URL of the request with the given query
+// parameters replaced. The api_key parameter is dropped so it isn't copied
+// into feeds and links.
+func requestURL(c *gin.Context, replace url.Values) *url.URL {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+
+	query := c.Request.URL.Query()
+	query.Del("api_key")
+	for name, values := range replace {
+		query[name] = values
+	}
+
+	return &url.URL{Scheme: scheme, Host: c.Request.Host, Path: c.Request.URL.Path, RawQuery: query.Encode()}
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/akhlexe/stocknews-api/internal/news"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateOutput(t *testing.T) {
+	tests := []struct {
+		accept string
+		output string
+	}{
+		{"", outputJSON},
+		{"application/json", outputJSON},
+		{"text/csv", outputCSV},
+		{"application/x-ndjson", outputNDJSON},
+		{"application/rss+xml, application/atom+xml;q=0.9, */*;q=0.1", outputRSS},
+		{"application/atom+xml;q=0.9, application/rss+xml;q=0.8", outputAtom},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", outputJSON},
+		{"*/*, text/csv", outputJSON},
+		{"text/plain", outputJSON},
+		{"text/csv;q=abc", outputJSON},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.output, negotiateOutput(tt.accept), tt.accept)
+	}
+}
+
+func TestNewsOutputFormats(t *testing.T) {
+	provider := &reportingProvider{name: "alphavantage", status: news.CacheMemory, articles: []models.Article{
+		{Title: "Apple ships", URL: "https://example.com/1", PublishedAt: "20250102T150405", Tickers: []string{"AAPL"}},
+		{Title: "Apple & friends", URL: "https://example.com/2", Tickers: []string{"AAPL"}},
+	}}
+
+	tests := []struct {
+		name        string
+		path        string
+		accept      string
+		contentType string
+		contains    string
+	}{
+		{"rss", "/news/AAPL?format=rss", "", "application/rss+xml; charset=utf-8", `<rss version="2.0"`},
+		{"atom", "/news/AAPL?format=atom", "", "application/atom+xml; charset=utf-8", `<feed xmlns="http://www.w3.org/2005/Atom">`},
+		{"csv", "/news/AAPL?format=csv&fields=title,url", "", "text/csv; charset=utf-8", "title,url\nApple ships,https://example.com/1\n"},
+		{"ndjson", "/news/AAPL?format=ndjson&fields=title", "", "application/x-ndjson", "{\"title\":\"Apple ships\"}\n{\"title\":\"Apple \\u0026 friends\"}\n"},
+		{"accept csv", "/news/AAPL", "text/csv", "text/csv; charset=utf-8", "id,time_published,title"},
+		{"format wins over accept", "/news/AAPL?format=json", "text/csv", "application/json; charset=utf-8", `"news":[`},
+		{"summary format means json", "/news/AAPL?format=structured", "text/csv", "application/json; charset=utf-8", `"news":[`},
+		{"browser", "/news/AAPL", "text/html,*/*;q=0.8", "application/json; charset=utf-8", `"news":[`},
+		{"v1 feeds are not enveloped", "/v1/news/AAPL?format=rss", "", "application/rss+xml; charset=utf-8", `<rss version="2.0"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveConditional(provider, tt.path, map[string]string{"Accept": tt.accept})
+
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"))
+			assert.Contains(t, w.Body.String(), tt.contains)
+			assert.Equal(t, "Accept", w.Header().Get("Vary"))
+		})
+	}
+}
+
+func TestNewsOutputFormatDetails(t *testing.T) {
+	provider := &reportingProvider{name: "alphavantage", status: news.CacheMemory, articles: []models.Article{
+		{Title: "Apple ships", URL: "https://example.com/1"},
+		{Title: "Apple beats", URL: "https://example.com/2"},
+	}}
+
+	w := serveConditional(provider, "/news/AAPL?format=csv&limit=1", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `attachment; filename="AAPL-news.csv"`, w.Header().Get("Content-Disposition"))
+	link := w.Header().Get("Link")
+	assert.True(t, strings.HasPrefix(link, "<http://"), link)
+	assert.Contains(t, link, "cursor=")
+	assert.Contains(t, link, "format=csv")
+	assert.True(t, strings.HasSuffix(link, `>; rel="next"`), link)
+
+	w = serveConditional(provider, "/news/AAPL?format=rss&api_key=snk_secret", map[string]string{"X-Forwarded-Proto": "https"})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "snk_secret", "credentials are not copied into feeds")
+	assert.Contains(t, w.Body.String(), "<link>https://")
+
+	csvETag := serveConditional(provider, "/news/AAPL", map[string]string{"Accept": "text/csv"}).Header().Get("ETag")
+	jsonETag := serveConditional(provider, "/news/AAPL", nil).Header().Get("ETag")
+	assert.NotEqual(t, csvETag, jsonETag, "each format has its own ETag")
+	assert.False(t, strings.HasPrefix(csvETag, "W/"), csvETag)
+
+	// Feeds include the fetch time and host, so they are only equivalent.
+	rssETag := serveConditional(provider, "/news/AAPL?format=rss", nil).Header().Get("ETag")
+	assert.Regexp(t, `^W/"[0-9a-f]{32}"$`, rssETag)
+	atomETag := serveConditional(provider, "/news/AAPL", map[string]string{"Accept": "application/atom+xml"}).Header().Get("ETag")
+	assert.Regexp(t, `^W/"[0-9a-f]{32}"$`, atomETag)
+	w = serveConditional(provider, "/news/AAPL?format=rss", map[string]string{"If-None-Match": rssETag})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	w = serveConditional(provider, "/news/AAPL?summarize=true&format=csv", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "Invalid format parameter."}`, w.Body.String())
+
+	w = serveConditional(provider, "/news/AAPL?format=xml", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "Invalid format parameter."}`, w.Body.String())
+}
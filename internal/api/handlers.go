@@ -10,27 +10,101 @@ import (
 	"time"
 
 	"github.com/akhlexe/stocknews-api/internal/ai"
-	"github.com/akhlexe/stocknews-api/internal/apperrors"
+	"github.com/akhlexe/stocknews-api/internal/alerts"
+	"github.com/akhlexe/stocknews-api/internal/auth"
 	"github.com/akhlexe/stocknews-api/internal/filter"
+	"github.com/akhlexe/stocknews-api/internal/jobs"
+	"github.com/akhlexe/stocknews-api/internal/models"
 	"github.com/akhlexe/stocknews-api/internal/news"
+	"github.com/akhlexe/stocknews-api/internal/semantic"
+	"github.com/akhlexe/stocknews-api/internal/stream"
+	"github.com/akhlexe/stocknews-api/internal/watchlists"
+	"github.com/akhlexe/stocknews-api/internal/webhooks"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 )
 
 var validTickerRegex = regexp.MustCompile(`^[A-Z]{1,10}$`)
 
+const (
+	summaryFormatText       = "text"
+	summaryFormatStructured = "structured"
+)
+
+const (
+	// summaryTimeout bounds a summary or answer generated while the client
+	// waits, across every map and reduce call; each model call also has its
+	// own timeout.
+	summaryTimeout = 2 * time.Minute
+
+	// summarySlotWait bounds how long an inline summary waits for a model
+	// slot held by summary jobs or other requests before answering 503.
+	summarySlotWait = 5 * time.Second
+
+	// summaryBusyRetryAfter is the Retry-After sent with that 503.
+	summaryBusyRetryAfter = 30 * time.Second
+)
+
 type Server struct {
 	MultiFetcher *news.MultiFetcher
+	Summaries    *ai.SummaryService
+
+	// Auth requires an API key on every route but /health; nil disables it.
+	Auth *auth.Service
+
+	// RateLimits limits requests per client; nil disables it.
+	RateLimits *RateLimits
+
+	// TrustedProxies lists the addresses or CIDR ranges of the proxies whose
+	// X-Forwarded-For is believed; by default none is, and clients are known
+	// by their own address.
+	TrustedProxies []string
+
+	// Semantic serves /search/semantic; nil disables it.
+	Semantic *semantic.Service
+
+	// Jobs runs POST /summaries jobs; nil disables them.
+	Jobs *jobs.Queue
+
+	// Stream serves /stream and /stream/ws; nil disables them.
+	Stream *stream.Hub
+
+	// StreamOrigins lists the browser origins, e.g. https://app.example.com,
+	// allowed to open /stream/ws besides the API's own.
+	StreamOrigins []string
+
+	// Webhooks serves /subscriptions; nil disables it.
+	Webhooks *webhooks.Service
+
+	// Alerts serves /alerts; nil disables it.
+	Alerts *alerts.Engine
+
+	// Watchlists serves /watchlists; nil disables it.
+	Watchlists *watchlists.Service
 }
 
-func NewServer(multiFetcher *news.MultiFetcher) *Server {
+func NewServer(multiFetcher *news.MultiFetcher, summaries *ai.SummaryService) *Server {
 	return &Server{
 		MultiFetcher: multiFetcher,
+		Summaries:    summaries,
 	}
 }
 
-func (s *Server) Run() {
-	router := gin.Default()
+// Router builds the HTTP routes.
+func (s *Server) Router() *gin.Engine {
+	// gin.Default's logger prints the raw query, which may carry an api_key;
+	// requests are logged below by path only.
+	router := gin.New()
+	router.Use(gin.Recovery())
+	// Handlers pass the gin.Context on as a context.Context; without this its
+	// Done and Err ignore the client going away.
+	router.ContextWithFallback = true
+
+	if err := router.SetTrustedProxies(s.TrustedProxies); err != nil {
+		log.Fatal().Err(err).Msg("Invalid trusted proxies")
+	}
+
+	router.Use(requestIDMiddleware())
 
 	router.Use(func(c *gin.Context) {
 		start := time.Now()
@@ -40,19 +114,162 @@ func (s *Server) Run() {
 			Str("method", c.Request.Method).
 			Str("path", c.Request.URL.Path).
 			Str("status", fmt.Sprintf("%d", c.Writer.Status())).
+			Str("request_id", c.GetString(requestIDContextKey)).
 			Dur("latency", latency).
 			Msg("Request handled")
 
 	})
 
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	if s.Auth != nil {
+		router.Use(authMiddleware(s.Auth))
+	}
+
+	if s.RateLimits != nil {
+		router.Use(rateLimitMiddleware(s.RateLimits))
+	}
+
+	validator, err := newSpecValidator(openAPISpec)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid OpenAPI specification")
+	}
+	router.Use(validator.middleware())
+
+	router.GET("/health", handleHealth)
+
+	router.GET("/openapi.json", handleOpenAPISpec)
+
+	router.GET("/docs", handleDocs)
+
+	router.GET("/usage", func(c *gin.Context) {
+		handleUsage(c, s.Auth)
 	})
 
 	router.GET("/news/:ticker", func(c *gin.Context) {
-		handleNews(c, s.MultiFetcher)
+		handleNews(c, s.MultiFetcher, s.Summaries)
+	})
+
+	// /v1 routes wrap responses in an Envelope and report errors as problem
+	// details; their legacy counterparts stay as they are.
+	router.GET("/v1/news/:ticker", func(c *gin.Context) {
+		handleNews(c, s.MultiFetcher, s.Summaries)
+	})
+
+	router.GET("/news/:ticker/summary/stream", func(c *gin.Context) {
+		handleSummaryStream(c, s.MultiFetcher, s.Summaries)
+	})
+
+	router.GET("/stream", func(c *gin.Context) {
+		handleStream(c, s.Stream, heartbeatInterval)
+	})
+
+	router.GET("/stream/ws", func(c *gin.Context) {
+		handleStreamWebSocket(c, s.Stream, heartbeatInterval, s.StreamOrigins)
 	})
 
+	// The remaining JSON routes are served under /v1 too, with the same
+	// envelope and problem details as /v1/news/:ticker.
+	for _, routes := range []gin.IRoutes{router, router.Group("/v1")} {
+		routes.GET("/news/:ticker/stories", func(c *gin.Context) {
+			handleStories(c, s.MultiFetcher)
+		})
+
+		routes.POST("/news/:ticker/ask", func(c *gin.Context) {
+			handleAsk(c, s.MultiFetcher, s.Summaries, s.Semantic)
+		})
+
+		routes.POST("/summaries", func(c *gin.Context) {
+			handleCreateSummaryJob(c, s.Jobs)
+		})
+
+		routes.GET("/summaries/:id", func(c *gin.Context) {
+			handleGetSummaryJob(c, s.Jobs)
+		})
+
+		routes.GET("/search/semantic", func(c *gin.Context) {
+			handleSemanticSearch(c, s.Semantic)
+		})
+
+		routes.POST("/subscriptions", func(c *gin.Context) {
+			handleCreateSubscription(c, s.Webhooks)
+		})
+
+		routes.GET("/subscriptions", func(c *gin.Context) {
+			handleListSubscriptions(c, s.Webhooks)
+		})
+
+		routes.GET("/subscriptions/:id", func(c *gin.Context) {
+			handleGetSubscription(c, s.Webhooks)
+		})
+
+		routes.DELETE("/subscriptions/:id", func(c *gin.Context) {
+			handleDeleteSubscription(c, s.Webhooks)
+		})
+
+		routes.GET("/subscriptions/:id/deliveries", func(c *gin.Context) {
+			handleListDeliveries(c, s.Webhooks)
+		})
+
+		routes.POST("/alerts/rules", func(c *gin.Context) {
+			handleCreateAlertRule(c, s.Alerts)
+		})
+
+		routes.GET("/alerts/rules", func(c *gin.Context) {
+			handleListAlertRules(c, s.Alerts)
+		})
+
+		routes.GET("/alerts/rules/:id", func(c *gin.Context) {
+			handleGetAlertRule(c, s.Alerts)
+		})
+
+		routes.DELETE("/alerts/rules/:id", func(c *gin.Context) {
+			handleDeleteAlertRule(c, s.Alerts)
+		})
+
+		routes.GET("/alerts", func(c *gin.Context) {
+			handleListAlerts(c, s.Alerts)
+		})
+
+		routes.POST("/watchlists", func(c *gin.Context) {
+			handleCreateWatchlist(c, s.Watchlists)
+		})
+
+		routes.GET("/watchlists", func(c *gin.Context) {
+			handleListWatchlists(c, s.Watchlists)
+		})
+
+		routes.GET("/watchlists/:id", func(c *gin.Context) {
+			handleGetWatchlist(c, s.Watchlists)
+		})
+
+		routes.PUT("/watchlists/:id", func(c *gin.Context) {
+			handleReplaceWatchlist(c, s.Watchlists)
+		})
+
+		routes.DELETE("/watchlists/:id", func(c *gin.Context) {
+			handleDeleteWatchlist(c, s.Watchlists)
+		})
+
+		routes.POST("/watchlists/:id/tickers", func(c *gin.Context) {
+			handleAddWatchlistTickers(c, s.Watchlists)
+		})
+
+		routes.DELETE("/watchlists/:id/tickers/:ticker", func(c *gin.Context) {
+			handleRemoveWatchlistTicker(c, s.Watchlists)
+		})
+
+		routes.GET("/watchlists/:id/news", func(c *gin.Context) {
+			handleWatchlistNews(c, s.MultiFetcher, s.Watchlists)
+		})
+	}
+
+	router.NoRoute(handleNotFound)
+
+	return router
+}
+
+func (s *Server) Run() {
+	router := s.Router()
+
 	port := os.Getenv("APP_PORT")
 	if port == "" {
 		port = "8080"
@@ -74,85 +291,254 @@ func (s *Server) Run() {
 	}
 }
 
-func handleNews(c *gin.Context, fetcher news.Provider) {
+func handleHealth(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func handleNews(c *gin.Context, fetcher news.Provider, summaries *ai.SummaryService) {
 	ticker := c.Param("ticker")
 	query := c.Query("q")
 	summarize := c.DefaultQuery("summarize", "false") == "true"
+	format, formatSet := c.GetQuery("format")
+	style := c.DefaultQuery("style", ai.DefaultStyle)
+	sortParam := c.Query("sort")
+	decayParam := c.Query("decay")
+	cursorParam := c.Query("cursor")
+	fields := parseFields(c.Query("fields"))
 
 	requestLog := log.With().Str("ticker", ticker).Logger()
 
 	// Input validation.
 	if !validTickerRegex.MatchString(ticker) {
 		requestLog.Warn().Msg("Invalid ticker format")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticker format."})
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid ticker format.")
+		return
+	}
+
+	if !formatSet {
+		format = summaryFormatText
+	}
+	if !validNewsFormat(format, summarize) {
+		requestLog.Warn().Str("format", format).Msg("Invalid format parameter")
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid format parameter.")
+		return
+	}
+
+	if !ai.IsValidStyle(style) {
+		requestLog.Warn().Str("style", style).Msg("Invalid style parameter")
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid style parameter.")
+		return
+	}
+
+	sortOrder, err := filter.ParseSortOrder(sortParam)
+	if err != nil {
+		requestLog.Warn().Str("sort", sortParam).Msg("Invalid sort parameter")
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid sort parameter.")
+		return
+	}
+
+	rankOpts := filter.DefaultRankOptions
+	if decayParam != "" {
+		halfLife, err := time.ParseDuration(decayParam)
+		if err != nil || halfLife <= 0 {
+			requestLog.Warn().Str("decay", decayParam).Msg("Invalid decay parameter")
+			writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid decay parameter.")
+			return
+		}
+		rankOpts.HalfLife = halfLife
+	}
+
+	limit, err := parseLimit(c.Query("limit"))
+	if err != nil {
+		requestLog.Warn().Str("limit", c.Query("limit")).Msg("Invalid limit parameter")
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid limit parameter.")
 		return
 	}
 
+	if err := models.ValidateArticleFields(fields); err != nil {
+		requestLog.Warn().Err(err).Msg("Invalid fields parameter")
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid fields parameter.")
+		return
+	}
+
+	var cursor *pageCursor
+	if cursorParam != "" {
+		decoded, err := decodeCursor(cursorParam)
+		if err != nil {
+			requestLog.Warn().Msg("Invalid cursor parameter")
+			writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid cursor parameter.")
+			return
+		}
+		cursor = &decoded
+	}
+
 	// add a timeout to the context for the fetcher call
-	fetchCtx, cancelFetch := context.WithTimeout(c, 10*time.Second)
+	fetchCtx, report := news.WithFetchReport(c)
+	fetchCtx, cancelFetch := context.WithTimeout(fetchCtx, 10*time.Second)
 	defer cancelFetch() // Important: ensure cancel is called to release resources
 
 	articles, err := fetcher.GetNewsByTicker(fetchCtx, ticker)
 
 	if err != nil {
 		requestLog.Error().Err(err).Msg("Error processing news request")
-
-		if errors.Is(err, context.DeadlineExceeded) {
-			c.JSON(http.StatusGatewayTimeout, gin.H{"error": "Request timed out."})
-		} else if errors.Is(err, context.Canceled) {
-			c.JSON(http.StatusRequestTimeout, gin.H{"error": "Request canceled."})
-		} else if errors.Is(err, apperrors.ErrNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "No news found for the specified ticker."})
-		} else if errors.Is(err, apperrors.ErrServiceUnavailable) {
-			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "External service unavailable."})
-		} else if errors.Is(err, apperrors.ErrInternal) {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error."})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Unknown error."})
-		}
+		writeFetchError(c, err)
 		return
 	}
 
 	if summarize {
-		var allArticles string
-		for _, a := range articles {
-			allArticles += a.Title + ":" + a.Summary + "\n"
+		if summaries == nil {
+			requestLog.Warn().Msg("Summary requested but no AI backend is configured")
+			writeError(c, http.StatusServiceUnavailable, codeNotConfigured, "AI summarization is not configured.")
+			return
 		}
 
-		if allArticles == "" {
+		if ai.CombineArticles(articles) == "" {
 			requestLog.Warn().Msg("No article content to summarize.")
-			c.JSON(http.StatusOK, gin.H{"ticker": ticker, "summary": ""})
+			respond(c, report, newsSummary{Ticker: ticker, Summary: "", Warnings: []string{}, ArticlesTotal: len(articles)})
 			return
 		}
 
-		aiCtx, cancelAI := context.WithTimeout(c, 10*time.Second)
-		defer cancelAI() // Important: ensure cancel is called to release resources
+		summaryCtx, cancelSummary := context.WithTimeout(c.Request.Context(), summaryTimeout)
+		defer cancelSummary()
+		summaryCtx = ai.WithSlotWait(summaryCtx, summarySlotWait)
+
+		summaryReq := ai.SummaryRequest{Ticker: ticker, Articles: articles, Style: style}
 
-		summary, err := ai.SummarizeArticles(aiCtx, allArticles)
+		var summary ai.Summary
+		if format == summaryFormatStructured {
+			summary, err = summaries.SummarizeStructured(summaryCtx, summaryReq)
+		} else {
+			summary, err = summaries.Summarize(summaryCtx, summaryReq)
+		}
 		if err != nil {
 			requestLog.Error().Err(err).Msg("Failed to generate AI summary")
+			writeAIError(c, err)
+			return
+		}
 
-			if errors.Is(err, context.DeadlineExceeded) {
-				c.JSON(http.StatusGatewayTimeout, gin.H{"error": "Request timed out."})
-			} else if errors.Is(err, context.Canceled) {
-				c.JSON(http.StatusRequestTimeout, gin.H{"error": "Request canceled."})
-			} else {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			}
+		requestLog.Info().
+			Bool("cached", summary.Cached).
+			Str("prompt_version", summary.PromptVersion).
+			Int("articles_included", summary.ArticlesIncluded).
+			Int("chunks", summary.Chunks).
+			Msg("AI summary generated successfully")
 
-			return
+		var summaryBody interface{} = summary.Text
+		if summary.Structured != nil {
+			summaryBody = summary.Structured
 		}
 
-		requestLog.Info().Msg("AI summary generated successfully")
-		c.JSON(http.StatusOK, gin.H{"ticker": ticker, "summary": summary})
+		respond(c, report, newsSummary{
+			Ticker:           ticker,
+			Summary:          summaryBody,
+			Cached:           summary.Cached,
+			PromptVersion:    summary.PromptVersion,
+			Warnings:         nonNilStrings(summary.Warnings),
+			ArticlesIncluded: summary.ArticlesIncluded,
+			ArticlesTotal:    len(articles),
+		})
+		return
+	}
+
+	// Without a format parameter the output depends on Accept.
+	output := articleOutput(c.Request, format, formatSet)
+	c.Header("Vary", "Accept")
+
+	etag := articlesETag(c, articles, output)
+	if output == outputRSS || output == outputAtom {
+		// Feeds include the fetch time and the request's host and scheme.
+		etag = weakETag(etag)
+	}
+	if notModified(c, report, etag) {
+		requestLog.Debug().Msg("News not modified")
 		return
 	}
 
 	if query != "" {
-		articles = filter.FilterByQuery(articles, query)
+		// Score against the full set so corpus statistics aren't skewed by the filter.
+		articles = filter.FilterByQuery(filter.ScoreBM25(articles, query, rankOpts), query)
 		requestLog.Debug().Str("query", query).Int("result_count", len(articles)).Msg("Filtered articles by query")
+
+		if sortOrder == filter.SortNone {
+			sortOrder = filter.SortRelevance
+		}
+	}
+
+	filter.SortArticles(articles, sortOrder)
+
+	total := len(articles)
+	page, nextCursor := paginate(articles, cursor, limit)
+
+	if output != outputJSON {
+		if err := writeArticles(c, output, ticker, page, fields, nextCursor, report.LastFetchedAt()); err != nil {
+			requestLog.Error().Err(err).Str("output", output).Msg("Failed to write articles")
+			writeError(c, http.StatusInternalServerError, codeInternal, "Internal server error.")
+			return
+		}
+		requestLog.Info().Int("article_count", len(page)).Int("total", total).Str("output", output).Msg("Successfully retrieved news articles")
+		return
 	}
 
-	requestLog.Info().Int("article_count", len(articles)).Msg("Successfully retrieved news articles")
-	c.JSON(http.StatusOK, gin.H{"ticker": ticker, "news": articles})
+	response := newsPage{Ticker: ticker, News: page, Total: total, NextCursor: nextCursor}
+
+	if len(fields) > 0 {
+		projected, err := models.ProjectArticles(page, fields)
+		if err != nil {
+			requestLog.Error().Err(err).Msg("Failed to project article fields")
+			writeError(c, http.StatusInternalServerError, codeInternal, "Internal server error.")
+			return
+		}
+		response.News = projected
+	}
+
+	requestLog.Info().Int("article_count", len(page)).Int("total", total).Msg("Successfully retrieved news articles")
+	respond(c, report, response)
+}
+
+// nonNilStrings keeps empty lists as [] rather than null in JSON responses.
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// writeFetchError maps a provider error onto an HTTP error response.
+func writeFetchError(c *gin.Context, err error) {
+	status, code := errorStatus(err)
+	writeError(c, status, code, fetchErrorMessages[code])
+}
+
+var fetchErrorMessages = map[string]string{
+	codeTimeout:            "Request timed out.",
+	codeCanceled:           "Request canceled.",
+	codeNotFound:           "No news found for the specified ticker.",
+	codeServiceUnavailable: "External service unavailable.",
+	codeConfiguration:      "Unknown error.",
+	codeInternal:           "Internal server error.",
+	codeUnknown:            "Unknown error.",
+}
+
+// writeAIError maps a summarizer error onto an HTTP error response.
+func writeAIError(c *gin.Context, err error) {
+	if errors.Is(err, ai.ErrBusy) {
+		writeRetryAfter(c, summaryBusyRetryAfter)
+		writeError(c, http.StatusServiceUnavailable, codeServiceUnavailable, "AI service is busy; retry later or queue a summary job.")
+		return
+	}
+
+	status, code := errorStatus(err)
+	switch code {
+	case codeTimeout, codeCanceled:
+		writeError(c, status, code, fetchErrorMessages[code])
+	case codeServiceUnavailable:
+		writeError(c, status, code, "AI service unavailable.")
+	default:
+		if code != codeConfiguration {
+			code = codeInternal
+		}
+		// The error can carry model output or prompt details; keep it in the log.
+		log.Error().Err(err).Str("code", code).Str("request_id", c.GetString(requestIDContextKey)).Msg("AI request failed")
+		writeError(c, http.StatusInternalServerError, code, fetchErrorMessages[codeInternal])
+	}
 }
@@ -5,9 +5,12 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/akhlexe/stocknews-api/internal/ai"
 	"github.com/akhlexe/stocknews-api/internal/apperrors"
+	"github.com/akhlexe/stocknews-api/internal/filter"
 	"github.com/akhlexe/stocknews-api/internal/models"
 	"github.com/akhlexe/stocknews-api/internal/news"
 	"github.com/gin-gonic/gin"
@@ -16,11 +19,15 @@ import (
 )
 
 func setupTestRouter(fetcher news.Provider) *gin.Engine {
+	return setupTestRouterWithSummarizer(fetcher, nil)
+}
+
+func setupTestRouterWithSummarizer(fetcher news.Provider, summaries *ai.SummaryService) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
 
 	router.GET("/news/:ticker", func(c *gin.Context) {
-		handleNews(c, fetcher)
+		handleNews(c, fetcher, summaries)
 	})
 
 	return router
@@ -56,6 +63,10 @@ func TestHandleNews(t *testing.T) {
 		},
 	}
 
+	// Filtered results carry their BM25 score
+	scoredArticle := withField(expectedSampleArticleBody[0], "score",
+		filter.ScoreBM25(samplerArticles, "Stock Up", filter.DefaultRankOptions)[0].Score)
+
 	testCases := []struct {
 		name           string
 		tickerParam    string
@@ -78,6 +89,7 @@ func TestHandleNews(t *testing.T) {
 			expectedBody: map[string]interface{}{
 				"ticker": "TEST",
 				"news":   expectedSampleArticleBody,
+				"total":  float64(2),
 			},
 		},
 		{
@@ -93,7 +105,73 @@ func TestHandleNews(t *testing.T) {
 			expectedStatus: http.StatusOK,
 			expectedBody: map[string]interface{}{
 				"ticker": "TEST",
-				"news":   []interface{}{expectedSampleArticleBody[0]},
+				"news":   []interface{}{scoredArticle},
+				"total":  float64(1),
+			},
+		},
+		{
+			name:        "Success - Sort Newest",
+			tickerParam: "TEST",
+			queryParams: map[string]string{"sort": "newest"},
+			mockSetup: func(mf *MockNewsProvider) {
+				mf.On("GetNewsByTicker",
+					mock.AnythingOfType("*context.timerCtx"),
+					"TEST",
+				).Return([]models.Article{
+					{Title: "Test Stock Up", Summary: "Good news for TEST", Tickers: []string{"TEST"}, PublishedAt: "20240101T100000"},
+					{Title: "TEST Results", Summary: "Quarterly results analysis", Tickers: []string{"TEST"}, PublishedAt: "20240102T100000"},
+				}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: map[string]interface{}{
+				"ticker": "TEST",
+				"news": []interface{}{
+					withField(expectedSampleArticleBody[1], "time_published", "20240102T100000"),
+					withField(expectedSampleArticleBody[0], "time_published", "20240101T100000"),
+				},
+				"total": float64(2),
+			},
+		},
+		{
+			name:        "Success - Limit With Field Projection",
+			tickerParam: "TEST",
+			queryParams: map[string]string{"limit": "1", "fields": "title,url"},
+			mockSetup: func(mf *MockNewsProvider) {
+				mf.On("GetNewsByTicker",
+					mock.AnythingOfType("*context.timerCtx"),
+					"TEST",
+				).Return(samplerArticles, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: map[string]interface{}{
+				"ticker": "TEST",
+				"news": []interface{}{
+					map[string]interface{}{"title": "Test Stock Up", "url": ""},
+				},
+				"total":       float64(2),
+				"next_cursor": encodeCursor(pageCursor{ID: samplerArticles[0].ID(), Offset: 1}),
+			},
+		},
+		{
+			name:        "Error - Invalid Fields",
+			tickerParam: "TEST",
+			queryParams: map[string]string{"fields": "title,password"},
+			mockSetup: func(mf *MockNewsProvider) {
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "Invalid fields parameter.",
+			},
+		},
+		{
+			name:        "Error - Invalid Sort",
+			tickerParam: "TEST",
+			queryParams: map[string]string{"sort": "random"},
+			mockSetup: func(mf *MockNewsProvider) {
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "Invalid sort parameter.",
 			},
 		},
 		{
@@ -207,3 +285,168 @@ func TestHandleNews(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleNewsSummary(t *testing.T) {
+	articles := []models.Article{
+		{Title: "Test Stock Up", Summary: "Good news for TEST"},
+	}
+	prompt := mock.MatchedBy(func(p string) bool {
+		return strings.HasPrefix(p, "Summarize the following stock market news about TEST.") &&
+			strings.Contains(p, "<article id=\"1\">\nTest Stock Up: Good news for TEST\n</article>")
+	})
+	briefPrompt := mock.MatchedBy(func(p string) bool {
+		return strings.Contains(p, "in at most three sentences")
+	})
+
+	testCases := []struct {
+		name           string
+		query          string
+		summarizer     func() *MockSummarizer
+		expectedStatus int
+		expectedBody   map[string]interface{}
+	}{
+		{
+			name: "Success",
+			summarizer: func() *MockSummarizer {
+				ms := new(MockSummarizer)
+				ms.On("Generate", mock.Anything, prompt).Return("Shares rose.", nil).Once()
+				return ms
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: map[string]interface{}{
+				"ticker":            "TEST",
+				"summary":           "Shares rose.",
+				"cached":            false,
+				"prompt_version":    "summary-detailed@v2",
+				"warnings":          []interface{}{},
+				"articles_included": float64(1),
+				"articles_total":    float64(1),
+			},
+		},
+		{
+			name: "Error - AI Unavailable",
+			summarizer: func() *MockSummarizer {
+				ms := new(MockSummarizer)
+				ms.On("Generate", mock.Anything, prompt).Return("", apperrors.ErrServiceUnavailable).Once()
+				return ms
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   map[string]interface{}{"error": "AI service unavailable."},
+		},
+		{
+			name:  "Error - Invalid Format",
+			query: "&format=xml",
+			summarizer: func() *MockSummarizer {
+				return new(MockSummarizer)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "Invalid format parameter."},
+		},
+		{
+			name:  "Success - Structured",
+			query: "&format=structured",
+			summarizer: func() *MockSummarizer {
+				ms := new(MockSummarizer)
+				ms.On("Generate", mock.Anything, mock.Anything).
+					Return(`{"key_points":[{"text":"Shares rose","sources":[1]}],"catalysts":[],"risks":[],"stance":"bullish"}`, nil).Once()
+				return ms
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: map[string]interface{}{
+				"ticker": "TEST",
+				"summary": map[string]interface{}{
+					"key_points": []interface{}{map[string]interface{}{
+						"text":        "Shares rose",
+						"sources":     []interface{}{float64(1)},
+						"article_ids": []interface{}{articles[0].ID()},
+					}},
+					"catalysts": []interface{}{},
+					"risks":     []interface{}{},
+					"stance":    "bullish",
+				},
+				"cached":            false,
+				"prompt_version":    "structured@v2",
+				"warnings":          []interface{}{},
+				"articles_included": float64(1),
+				"articles_total":    float64(1),
+			},
+		},
+		{
+			name:  "Success - Brief Style",
+			query: "&style=brief",
+			summarizer: func() *MockSummarizer {
+				ms := new(MockSummarizer)
+				ms.On("Generate", mock.Anything, briefPrompt).Return("Up.", nil).Once()
+				return ms
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: map[string]interface{}{
+				"ticker":            "TEST",
+				"summary":           "Up.",
+				"cached":            false,
+				"prompt_version":    "summary-brief@v2",
+				"warnings":          []interface{}{},
+				"articles_included": float64(1),
+				"articles_total":    float64(1),
+			},
+		},
+		{
+			name:  "Error - Invalid Style",
+			query: "&style=poem",
+			summarizer: func() *MockSummarizer {
+				return new(MockSummarizer)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "Invalid style parameter."},
+		},
+		{
+			name:           "Error - Not Configured",
+			summarizer:     nil,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   map[string]interface{}{"error": "AI summarization is not configured."},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockFetcher := new(MockNewsProvider)
+			if tc.expectedStatus != http.StatusBadRequest {
+				mockFetcher.On("GetNewsByTicker", mock.Anything, "TEST").Return(articles, nil).Once()
+			}
+
+			var summaries *ai.SummaryService
+			var mockSummarizer *MockSummarizer
+			if tc.summarizer != nil {
+				mockSummarizer = tc.summarizer()
+				summaries = ai.NewSummaryService(mockSummarizer, nil, ai.SummaryOptions{})
+			}
+
+			router := setupTestRouterWithSummarizer(mockFetcher, summaries)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/news/TEST?summarize=true"+tc.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+
+			var responseBody map[string]interface{}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &responseBody))
+			assert.Equal(t, tc.expectedBody, responseBody)
+
+			mockFetcher.AssertExpectations(t)
+			if mockSummarizer != nil {
+				mockSummarizer.AssertExpectations(t)
+			}
+		})
+	}
+}
+
+// withField returns a copy of an expected article body with one field replaced.
+func withField(article interface{}, key string, value interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for k, v := range article.(map[string]interface{}) {
+		result[k] = v
+	}
+	result[key] = value
+	return result
+}
@@ -24,3 +24,16 @@ func (m *MockNewsProvider) GetNewsByTicker(ctx context.Context, ticker string) (
 
 	return articles, args.Error(1)
 }
+
+type MockSummarizer struct {
+	mock.Mock
+}
+
+func (m *MockSummarizer) Generate(ctx context.Context, prompt string) (string, error) {
+	args := m.Called(ctx, prompt)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockSummarizer) Model() string {
+	return "test-model"
+}
@@ -0,0 +1,212 @@
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// openAPISpec documents the API. The TypeScript client is generated from it,
+// so it is also enforced: see specValidator.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+//go:embed docs.html
+var docsPage []byte
+
+// openAPIDocument is the part of an OpenAPI 3 document needed to validate
+// requests. Parameters are declared on operations, not on paths.
+type openAPIDocument struct {
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components struct {
+		SecuritySchemes map[string]struct {
+			Type string `json:"type"`
+			In   string `json:"in"`
+			Name string `json:"name"`
+		} `json:"securitySchemes"`
+	} `json:"components"`
+}
+
+type openAPIOperation struct {
+	OperationID string             `json:"operationId"`
+	Handler     string             `json:"x-handler"`
+	Parameters  []openAPIParameter `json:"parameters"`
+}
+
+type openAPIParameter struct {
+	Name     string        `json:"name"`
+	In       string        `json:"in"`
+	Required bool          `json:"required"`
+	Schema   openAPISchema `json:"schema"`
+}
+
+// openAPISchema supports the keywords used by parameters in openapi.json.
+type openAPISchema struct {
+	Type      string   `json:"type"`
+	Enum      []string `json:"enum"`
+	Pattern   string   `json:"pattern"`
+	Minimum   *int     `json:"minimum"`
+	Maximum   *int     `json:"maximum"`
+	MaxLength *int     `json:"maxLength"`
+
+	pattern *regexp.Regexp
+}
+
+// specValidator rejects requests whose parameters are missing, unknown or
+// don't match the spec, on the routes the spec covers.
+type specValidator struct {
+	// operations is keyed by method and gin route, e.g. "GET /news/:ticker".
+	operations map[string]openAPIOperation
+
+	// credentials are query parameters carrying credentials, allowed on
+	// every operation.
+	credentials map[string]bool
+}
+
+var pathTemplateParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+func newSpecValidator(spec []byte) (*specValidator, error) {
+	var doc openAPIDocument
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, err
+	}
+
+	v := &specValidator{
+		operations:  make(map[string]openAPIOperation),
+		credentials: make(map[string]bool),
+	}
+	for _, scheme := range doc.Components.SecuritySchemes {
+		if scheme.Type == "apiKey" && scheme.In == "query" {
+			v.credentials[scheme.Name] = true
+		}
+	}
+
+	for path, operations := range doc.Paths {
+		route := pathTemplateParam.ReplaceAllString(path, ":$1")
+		for method, op := range operations {
+			for i, p := range op.Parameters {
+				if p.Schema.Pattern == "" {
+					continue
+				}
+				pattern, err := regexp.Compile(p.Schema.Pattern)
+				if err != nil {
+					return nil, fmt.Errorf("parameter %s of %s %s: %w", p.Name, method, path, err)
+				}
+				op.Parameters[i].Schema.pattern = pattern
+			}
+			v.operations[strings.ToUpper(method)+" "+route] = op
+		}
+	}
+
+	return v, nil
+}
+
+// middleware validates requests to operations in the spec and lets any other
+// request through.
+func (v *specValidator) middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		op, ok := v.operations[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			c.Next()
+			return
+		}
+
+		if code, message := v.validate(c, op); message != "" {
+			log.Warn().Str("path", c.Request.URL.Path).Str("error", message).Msg("Request does not match the API spec")
+			abortWithError(c, http.StatusBadRequest, code, message)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// validate returns the problem code and error message for the first invalid
+// parameter, or "" and "". Messages match the ones the handlers use.
+func (v *specValidator) validate(c *gin.Context, op openAPIOperation) (string, string) {
+	query := c.Request.URL.Query()
+	known := make(map[string]bool, len(op.Parameters))
+
+	for _, p := range op.Parameters {
+		switch p.In {
+		case "path":
+			if !p.Schema.accepts(c.Param(p.Name)) {
+				return codeInvalidParameter, "Invalid " + p.Name + " format."
+			}
+		case "query":
+			known[p.Name] = true
+			values, present := query[p.Name]
+			if !present {
+				if p.Required {
+					return codeMissingParameter, "Missing " + p.Name + " parameter."
+				}
+				continue
+			}
+			if len(values) != 1 || !p.Schema.accepts(values[0]) {
+				return codeInvalidParameter, "Invalid " + p.Name + " parameter."
+			}
+		}
+	}
+
+	for name := range query {
+		if !known[name] && !v.credentials[name] {
+			return codeUnknownParameter, "Unknown " + name + " parameter."
+		}
+	}
+
+	return "", ""
+}
+
+func (s openAPISchema) accepts(value string) bool {
+	switch s.Type {
+	case "boolean":
+		if value != "true" && value != "false" {
+			return false
+		}
+	case "integer":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return false
+		}
+		if (s.Minimum != nil && n < *s.Minimum) || (s.Maximum != nil && n > *s.Maximum) {
+			return false
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if value == e {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if s.MaxLength != nil && utf8.RuneCountInString(value) > *s.MaxLength {
+		return false
+	}
+
+	return s.pattern == nil || s.pattern.MatchString(value)
+}
+
+func handleOpenAPISpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", openAPISpec)
+}
+
+// handleDocs serves a page rendering the spec, without external scripts.
+func handleDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSpecMatchesRoutes fails when an operation in openapi.json has no route.
+func TestSpecMatchesRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validator, err := newSpecValidator(openAPISpec)
+	require.NoError(t, err)
+
+	routes := make(map[string]bool)
+	for _, r := range (&Server{}).Router().Routes() {
+		routes[r.Method+" "+r.Path] = true
+	}
+
+	for route := range validator.operations {
+		assert.True(t, routes[route], "%s is in the spec but not routed", route)
+	}
+}
+
+// TestSpecMatchesHandlerParameters fails when a handler reads a parameter the
+// spec doesn't declare, or the spec declares one the handler ignores.
+func TestSpecMatchesHandlerParameters(t *testing.T) {
+	validator, err := newSpecValidator(openAPISpec)
+	require.NoError(t, err)
+
+	handlers := parseHandlers(t)
+
+	for route, op := range validator.operations {
+		fn, ok := handlers[op.Handler]
+		require.True(t, ok, "%s: x-handler %q not found", route, op.Handler)
+
+		declared := []string{}
+		for _, p := range op.Parameters {
+			declared = append(declared, p.In+":"+p.Name)
+		}
+		sort.Strings(declared)
+
+		assert.Equal(t, declared, readParameters(fn), "%s: spec parameters differ from %s", route, op.Handler)
+	}
+}
+
+func parseHandlers(t *testing.T) map[string]*ast.FuncDecl {
+	pkgs, err := parser.ParseDir(token.NewFileSet(), ".", func(info fs.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	require.NoError(t, err)
+
+	handlers := make(map[string]*ast.FuncDecl)
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil {
+					handlers[fn.Name.Name] = fn
+				}
+			}
+		}
+	}
+	return handlers
+}
+
+// readParameters lists the parameters a handler reads through gin, as
+// "query:name" and "path:name".
+func readParameters(fn *ast.FuncDecl) []string {
+	set := make(map[string]bool)
+	ast.Inspect(fn, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+		name, _ := strconv.Unquote(lit.Value)
+
+		switch sel.Sel.Name {
+		case "Query", "DefaultQuery", "GetQuery":
+			set["query:"+name] = true
+		case "Param":
+			set["path:"+name] = true
+		}
+		return true
+	})
+
+	params := []string{}
+	for p := range set {
+		params = append(params, p)
+	}
+	sort.Strings(params)
+	return params
+}
+
+func setupSpecRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	validator, err := newSpecValidator(openAPISpec)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(validator.middleware())
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"status": "ok"}) }
+	router.GET("/news/:ticker", ok)
+	router.GET("/news/:ticker/stories", ok)
+	return router
+}
+
+func TestSpecValidator(t *testing.T) {
+	router := setupSpecRouter(t)
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		error  string
+	}{
+		{"valid", "/news/AAPL?q=earnings&sort=newest&limit=10&decay=1h30m&fields=title,url", http.StatusOK, ""},
+		{"api key", "/news/AAPL?api_key=snk_x", http.StatusOK, ""},
+		{"route outside the spec", "/news/AAPL/stories?window=1h", http.StatusOK, ""},
+		{"invalid ticker", "/news/aapl", http.StatusBadRequest, "Invalid ticker format."},
+		{"invalid enum", "/news/AAPL?sort=Newest", http.StatusBadRequest, "Invalid sort parameter."},
+		{"invalid boolean", "/news/AAPL?summarize=yes", http.StatusBadRequest, "Invalid summarize parameter."},
+		{"limit too high", "/news/AAPL?limit=101", http.StatusBadRequest, "Invalid limit parameter."},
+		{"limit not a number", "/news/AAPL?limit=ten", http.StatusBadRequest, "Invalid limit parameter."},
+		{"invalid decay", "/news/AAPL?decay=soon", http.StatusBadRequest, "Invalid decay parameter."},
+		{"repeated", "/news/AAPL?sort=newest&sort=oldest", http.StatusBadRequest, "Invalid sort parameter."},
+		{"unknown", "/news/AAPL?page=2", http.StatusBadRequest, "Unknown page parameter."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.error != "" {
+				assert.JSONEq(t, `{"error": "`+tt.error+`"}`, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestServeOpenAPISpec(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := (&Server{}).Router()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/openapi.json", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var spec map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &spec))
+	assert.Equal(t, "3.0.3", spec["openapi"])
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/docs", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), "/openapi.json")
+}
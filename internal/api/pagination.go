@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+)
+
+const maxPageLimit = 100
+
+var (
+	errInvalidLimit  = errors.New("invalid limit")
+	errInvalidCursor = errors.New("invalid cursor")
+)
+
+// pageCursor marks the last article of the previous page. The article ID keeps
+// the position stable when a cache refresh adds or removes articles; the
+// offset is only used when that article is no longer in the list.
+type pageCursor struct {
+	ID     string `json:"id"`
+	Offset int    `json:"o"`
+}
+
+func encodeCursor(cursor pageCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string) (pageCursor, error) {
+	var cursor pageCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, errInvalidCursor
+	}
+
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Offset < 0 {
+		return cursor, errInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+func parseLimit(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > maxPageLimit {
+		return 0, errInvalidLimit
+	}
+
+	return limit, nil
+}
+
+func parseFields(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	var fields []string
+	for _, f := range strings.Split(value, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// paginate returns the page of articles following cursor and the cursor for the
+// next page, which is empty on the last page. A zero limit disables paging.
+func paginate(articles []models.Article, cursor *pageCursor, limit int) ([]models.Article, string) {
+	start := 0
+	if cursor != nil {
+		start = cursor.Offset
+		for i, a := range articles {
+			if a.ID() == cursor.ID {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	if start > len(articles) {
+		start = len(articles)
+	}
+
+	if limit == 0 {
+		return articles[start:], ""
+	}
+
+	end := start + limit
+	if end >= len(articles) {
+		return articles[start:], ""
+	}
+
+	page := articles[start:end]
+	next := encodeCursor(pageCursor{ID: page[len(page)-1].ID(), Offset: end})
+	return page, next
+}
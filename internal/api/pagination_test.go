@@ -0,0 +1,47 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginateStableAcrossRefresh(t *testing.T) {
+	articles := []models.Article{
+		{Title: "a", URL: "https://example.com/a"},
+		{Title: "b", URL: "https://example.com/b"},
+		{Title: "c", URL: "https://example.com/c"},
+		{Title: "d", URL: "https://example.com/d"},
+	}
+
+	page, next := paginate(articles, nil, 2)
+	assert.Equal(t, articles[:2], page)
+	assert.NotEmpty(t, next)
+
+	cursor, err := decodeCursor(next)
+	assert.NoError(t, err)
+
+	// A refresh prepends a new article; the next page must still start at "c".
+	refreshed := append([]models.Article{{Title: "new", URL: "https://example.com/new"}}, articles...)
+	page, next = paginate(refreshed, &cursor, 2)
+	assert.Equal(t, articles[2:], page)
+	assert.Empty(t, next)
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	_, err := decodeCursor("not-a-cursor!")
+	assert.ErrorIs(t, err, errInvalidCursor)
+}
+
+func TestParseLimit(t *testing.T) {
+	limit, err := parseLimit("")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, limit)
+
+	_, err = parseLimit("0")
+	assert.ErrorIs(t, err, errInvalidLimit)
+
+	_, err = parseLimit("101")
+	assert.ErrorIs(t, err, errInvalidLimit)
+}
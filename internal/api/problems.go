@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/akhlexe/stocknews-api/internal/apperrors"
+	"github.com/gin-gonic/gin"
+)
+
+// Problem codes identify /v1 errors for clients; the titles are for people.
+const (
+	codeInvalidParameter   = "invalid_parameter"
+	codeUnknownParameter   = "unknown_parameter"
+	codeMissingParameter   = "missing_parameter"
+	codeUnauthorized       = "unauthorized"
+	codeForbidden          = "forbidden"
+	codeRateLimited        = "rate_limited"
+	codeQuotaExceeded      = "quota_exceeded"
+	codeNotFound           = "not_found"
+	codeCanceled           = "canceled"
+	codeTimeout            = "timeout"
+	codeNotConfigured      = "not_configured"
+	codeServiceUnavailable = "service_unavailable"
+	codeConfiguration      = "configuration_error"
+	codeInternal           = "internal_error"
+	codeUnknown            = "unknown_error"
+)
+
+var problemTitles = map[string]string{
+	codeInvalidParameter:   "Invalid parameter",
+	codeUnknownParameter:   "Unknown parameter",
+	codeMissingParameter:   "Missing parameter",
+	codeUnauthorized:       "Unauthorized",
+	codeForbidden:          "Forbidden",
+	codeRateLimited:        "Rate limit exceeded",
+	codeQuotaExceeded:      "Daily quota exceeded",
+	codeNotFound:           "Not found",
+	codeCanceled:           "Request canceled",
+	codeTimeout:            "Request timed out",
+	codeNotConfigured:      "Not configured",
+	codeServiceUnavailable: "Service unavailable",
+	codeConfiguration:      "Configuration error",
+	codeInternal:           "Internal error",
+	codeUnknown:            "Unknown error",
+}
+
+// problemTypePrefix makes problem codes into the type URIs RFC 7807 asks for.
+const problemTypePrefix = "urn:stocknews:problem:"
+
+// Problem is an RFC 7807 problem details body, returned for every /v1 error.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// isV1 reports whether the request is to the versioned API.
+func isV1(c *gin.Context) bool {
+	return strings.HasPrefix(c.Request.URL.Path, "/v1/")
+}
+
+// writeError writes problem details on /v1 routes and the legacy
+// {"error": message} body everywhere else.
+func writeError(c *gin.Context, status int, code, message string) {
+	if !isV1(c) {
+		c.JSON(status, gin.H{"error": message})
+		return
+	}
+
+	// gin keeps a Content-Type that is already set.
+	c.Header("Content-Type", "application/problem+json")
+	c.JSON(status, Problem{
+		Type:      problemTypePrefix + code,
+		Title:     problemTitles[code],
+		Status:    status,
+		Detail:    message,
+		Instance:  c.Request.URL.Path,
+		Code:      code,
+		RequestID: c.GetString(requestIDContextKey),
+	})
+}
+
+// abortWithError is writeError for middleware.
+func abortWithError(c *gin.Context, status int, code, message string) {
+	writeError(c, status, code, message)
+	c.Abort()
+}
+
+// errorStatus maps an error onto an HTTP status and problem code.
+func errorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, codeTimeout
+	case errors.Is(err, context.Canceled):
+		return http.StatusRequestTimeout, codeCanceled
+	case errors.Is(err, apperrors.ErrNotFound):
+		return http.StatusNotFound, codeNotFound
+	case errors.Is(err, apperrors.ErrServiceUnavailable):
+		return http.StatusServiceUnavailable, codeServiceUnavailable
+	case errors.Is(err, apperrors.ErrConfiguration):
+		return http.StatusInternalServerError, codeConfiguration
+	case errors.Is(err, apperrors.ErrInternal):
+		return http.StatusInternalServerError, codeInternal
+	default:
+		return http.StatusInternalServerError, codeUnknown
+	}
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/auth"
+	"github.com/akhlexe/stocknews-api/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Rate limit classes.
+const (
+	rateClassNews      = "news"
+	rateClassSummarize = "summarize"
+	rateClassSearch    = "search"
+	rateClassDefault   = "default"
+)
+
+// RateLimits configures per-client rate limiting. Requests are counted per API
+// key, or per client IP when authentication is disabled, separately for each
+// class of route. A zero Limit leaves its class unlimited.
+type RateLimits struct {
+	Limiter *ratelimit.Limiter
+
+	// News covers /news/{ticker}, /v1/news/{ticker} and watchlist feeds.
+	News ratelimit.Limit
+
+	// Summarize covers every request that runs the LLM.
+	Summarize ratelimit.Limit
+
+	// Search covers /search/semantic.
+	Search ratelimit.Limit
+
+	// Default covers every other route.
+	Default ratelimit.Limit
+}
+
+func (r *RateLimits) limit(class string) ratelimit.Limit {
+	switch class {
+	case rateClassNews:
+		return r.News
+	case rateClassSummarize:
+		return r.Summarize
+	case rateClassSearch:
+		return r.Search
+	default:
+		return r.Default
+	}
+}
+
+// rateLimitMiddleware rejects clients over their limit with 429 and reports
+// the limit in X-RateLimit-* headers. It runs after authMiddleware so it can
+// key by API key. When the limiter's store fails, requests are let through.
+func rateLimitMiddleware(limits *RateLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if publicPaths[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		class := rateClass(c)
+		limit := limits.limit(class)
+		if limit.Unlimited() {
+			c.Next()
+			return
+		}
+
+		client := rateLimitClient(c)
+		result, err := limits.Limiter.Allow(c, class+":"+client, limit)
+		if err != nil {
+			log.Warn().Err(err).Str("class", class).Msg("Rate limiter unavailable; allowing request")
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(result.Reset.Unix(), 10))
+
+		if !result.Allowed {
+			log.Warn().Str("class", class).Str("client", client).Msg("Rate limit exceeded")
+			writeRetryAfter(c, result.RetryAfter(time.Now()))
+			abortWithError(c, http.StatusTooManyRequests, codeRateLimited, "Rate limit exceeded.")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// rateClass picks the limit a request counts against.
+func rateClass(c *gin.Context) string {
+	path := strings.TrimPrefix(c.FullPath(), "/v1")
+	switch {
+	case requestScope(c) == auth.ScopeSummarize:
+		return rateClassSummarize
+	case path == "/search/semantic":
+		return rateClassSearch
+	case strings.HasPrefix(path, "/news/"), path == "/watchlists/:id/news":
+		return rateClassNews
+	default:
+		return rateClassDefault
+	}
+}
+
+// rateLimitClient identifies the caller by API key, or by IP address when the
+// request wasn't authenticated.
+func rateLimitClient(c *gin.Context) string {
+	if value, ok := c.Get(apiKeyContextKey); ok {
+		return "key:" + value.(auth.Key).ID
+	}
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRateLimitRouter(limits *RateLimits) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(rateLimitMiddleware(limits))
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"status": "ok"}) }
+	router.GET("/health", ok)
+	router.GET("/news/:ticker", ok)
+	router.GET("/search/semantic", ok)
+	return router
+}
+
+func serveFrom(router *gin.Engine, path, ip string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = ip + ":1234"
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	router := setupRateLimitRouter(&RateLimits{
+		Limiter:   ratelimit.NewLimiter(ratelimit.NewMemoryStore()),
+		News:      ratelimit.Limit{Requests: 2, Window: time.Hour},
+		Summarize: ratelimit.Limit{Requests: 1, Window: time.Hour},
+	})
+
+	w := serveFrom(router, "/news/AAPL", "10.0.0.1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, w.Header().Get("X-RateLimit-Reset"))
+
+	w = serveFrom(router, "/news/AAPL?summarize=true", "10.0.0.1")
+	assert.Equal(t, http.StatusOK, w.Code, "summaries have their own limit")
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+
+	serveFrom(router, "/news/MSFT", "10.0.0.1")
+	w = serveFrom(router, "/news/TSLA", "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.JSONEq(t, `{"error": "Rate limit exceeded."}`, w.Body.String())
+
+	w = serveFrom(router, "/news/TSLA", "10.0.0.2")
+	assert.Equal(t, http.StatusOK, w.Code, "other clients are not affected")
+
+	w = serveFrom(router, "/search/semantic?q=chips", "10.0.0.1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("X-RateLimit-Limit"), "search is unlimited here")
+
+	w = serveFrom(router, "/health", "10.0.0.1")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRateLimitIgnoresForwardedForFromUntrustedClients(t *testing.T) {
+	limits := &RateLimits{
+		Limiter: ratelimit.NewLimiter(ratelimit.NewMemoryStore()),
+		Default: ratelimit.Limit{Requests: 1, Window: time.Hour},
+	}
+	serve := func(router *gin.Engine, forwardedFor string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/usage", nil)
+		req.RemoteAddr = "203.0.113.7:1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	router := (&Server{RateLimits: limits}).Router()
+	serve(router, "198.51.100.1")
+	assert.Equal(t, http.StatusTooManyRequests, serve(router, "198.51.100.2"), "a spoofed header doesn't make a new client")
+
+	limits.Limiter = ratelimit.NewLimiter(ratelimit.NewMemoryStore())
+	router = (&Server{RateLimits: limits, TrustedProxies: []string{"203.0.113.0/24"}}).Router()
+	serve(router, "198.51.100.1")
+	assert.NotEqual(t, http.StatusTooManyRequests, serve(router, "198.51.100.2"), "trusted proxies forward the client address")
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/akhlexe/stocknews-api/internal/semantic"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultSemanticLimit is the number of results when no limit is given.
+const defaultSemanticLimit = 10
+
+// handleSemanticSearch returns the indexed articles closest in meaning to q,
+// optionally restricted to a ticker. Only articles that have been fetched and
+// embedded before are searched.
+func handleSemanticSearch(c *gin.Context, search *semantic.Service) {
+	query := strings.TrimSpace(c.Query("q"))
+	ticker := c.Query("ticker")
+	requestLog := log.With().Str("query", query).Logger()
+
+	if query == "" {
+		requestLog.Warn().Msg("Missing q parameter")
+		writeError(c, http.StatusBadRequest, codeMissingParameter, "Missing q parameter.")
+		return
+	}
+
+	if ticker != "" && !validTickerRegex.MatchString(ticker) {
+		requestLog.Warn().Str("ticker", ticker).Msg("Invalid ticker format")
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid ticker format.")
+		return
+	}
+
+	limit, err := parseLimit(c.Query("limit"))
+	if err != nil {
+		requestLog.Warn().Str("limit", c.Query("limit")).Msg("Invalid limit parameter")
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid limit parameter.")
+		return
+	}
+	if limit == 0 {
+		limit = defaultSemanticLimit
+	}
+
+	if search == nil {
+		requestLog.Warn().Msg("Semantic search requested but no embedding backend is configured")
+		writeError(c, http.StatusServiceUnavailable, codeNotConfigured, "Semantic search is not configured.")
+		return
+	}
+
+	articles, err := search.Search(c, query, limit, ticker)
+	if err != nil {
+		requestLog.Error().Err(err).Msg("Failed to run semantic search")
+		writeAIError(c, err)
+		return
+	}
+
+	requestLog.Info().Int("result_count", len(articles)).Msg("Semantic search completed")
+	respond(c, nil, gin.H{"query": query, "news": articles, "total": len(articles)})
+}
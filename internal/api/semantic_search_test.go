@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/akhlexe/stocknews-api/internal/apperrors"
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/akhlexe/stocknews-api/internal/semantic"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// keywordEmbedder puts each known word on its own axis.
+type keywordEmbedder struct {
+	words []string
+	err   error
+}
+
+func (e *keywordEmbedder) Model() string { return "test-embeddings" }
+
+func (e *keywordEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	vector := make([]float32, len(e.words))
+	for i, w := range e.words {
+		if strings.Contains(strings.ToLower(text), w) {
+			vector[i] = 1
+		}
+	}
+	return vector, nil
+}
+
+func setupSemanticRouter(search *semantic.Service) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/search/semantic", func(c *gin.Context) {
+		handleSemanticSearch(c, search)
+	})
+	return router
+}
+
+func TestHandleSemanticSearch(t *testing.T) {
+	embedder := &keywordEmbedder{words: []string{"chip", "recall"}}
+	search := semantic.NewService(embedder, nil, 0)
+	search.Index(context.Background(), models.Article{Title: "Chip shortage", URL: "https://example.com/chips", Tickers: []string{"NVDA"}})
+	search.Index(context.Background(), models.Article{Title: "Car recall", URL: "https://example.com/recall", Tickers: []string{"TSLA"}})
+
+	testCases := []struct {
+		name           string
+		search         *semantic.Service
+		query          string
+		expectedStatus int
+		expectedURLs   []string
+		expectedError  string
+	}{
+		{"Nearest first", search, "?q=chip+supply", http.StatusOK, []string{"https://example.com/chips", "https://example.com/recall"}, ""},
+		{"Limit", search, "?q=recall&limit=1", http.StatusOK, []string{"https://example.com/recall"}, ""},
+		{"Ticker filter", search, "?q=chip&ticker=TSLA", http.StatusOK, []string{"https://example.com/recall"}, ""},
+		{"Missing query", search, "?q=+", http.StatusBadRequest, nil, "Missing q parameter."},
+		{"Invalid ticker", search, "?q=chip&ticker=bad", http.StatusBadRequest, nil, "Invalid ticker format."},
+		{"Invalid limit", search, "?q=chip&limit=0", http.StatusBadRequest, nil, "Invalid limit parameter."},
+		{"Not configured", nil, "?q=chip", http.StatusServiceUnavailable, nil, "Semantic search is not configured."},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/search/semantic"+tc.query, nil)
+			setupSemanticRouter(tc.search).ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+
+			var body struct {
+				Error string           `json:"error"`
+				News  []models.Article `json:"news"`
+				Total int              `json:"total"`
+			}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tc.expectedError, body.Error)
+
+			var urls []string
+			for _, a := range body.News {
+				urls = append(urls, a.URL)
+			}
+			assert.Equal(t, tc.expectedURLs, urls)
+		})
+	}
+}
+
+func TestHandleSemanticSearchEmbeddingError(t *testing.T) {
+	search := semantic.NewService(&keywordEmbedder{err: apperrors.ErrServiceUnavailable}, nil, 0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/search/semantic?q=chip", nil)
+	setupSemanticRouter(search).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.JSONEq(t, `{"error": "AI service unavailable."}`, w.Body.String())
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/filter"
+	"github.com/akhlexe/stocknews-api/internal/news"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// handleStories groups a ticker's articles into stories, largest first.
+func handleStories(c *gin.Context, fetcher news.Provider) {
+	ticker := c.Param("ticker")
+	windowParam := c.Query("window")
+	requestLog := log.With().Str("ticker", ticker).Logger()
+
+	if !validTickerRegex.MatchString(ticker) {
+		requestLog.Warn().Msg("Invalid ticker format")
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid ticker format.")
+		return
+	}
+
+	opts := filter.DefaultClusterOptions
+	if windowParam != "" {
+		window, err := time.ParseDuration(windowParam)
+		if err != nil || window <= 0 {
+			requestLog.Warn().Str("window", windowParam).Msg("Invalid window parameter")
+			writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid window parameter.")
+			return
+		}
+		opts.Window = window
+	}
+
+	fetchCtx, report := news.WithFetchReport(c)
+	fetchCtx, cancelFetch := context.WithTimeout(fetchCtx, 10*time.Second)
+	defer cancelFetch()
+
+	articles, err := fetcher.GetNewsByTicker(fetchCtx, ticker)
+	if err != nil {
+		requestLog.Error().Err(err).Msg("Error processing stories request")
+		writeFetchError(c, err)
+		return
+	}
+
+	if notModified(c, report, articlesETag(c, articles)) {
+		requestLog.Debug().Msg("Stories not modified")
+		return
+	}
+
+	stories := filter.ClusterStories(articles, opts)
+
+	requestLog.Info().Int("article_count", len(articles)).Int("story_count", len(stories)).Msg("Clustered articles into stories")
+	respond(c, report, gin.H{"ticker": ticker, "stories": stories, "total": len(stories)})
+}
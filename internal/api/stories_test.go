@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/akhlexe/stocknews-api/internal/apperrors"
+	"github.com/akhlexe/stocknews-api/internal/filter"
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/akhlexe/stocknews-api/internal/news"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupStoriesRouter(fetcher news.Provider) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/news/:ticker/stories", func(c *gin.Context) {
+		handleStories(c, fetcher)
+	})
+	return router
+}
+
+func TestHandleStories(t *testing.T) {
+	articles := []models.Article{
+		{Title: "Tesla recalls Model Y", PublishedAt: "20240801T000000"},
+		{Title: "Tesla recalls Model Y vehicles", PublishedAt: "20240801T120000"},
+		{Title: "Tesla opens new factory", PublishedAt: "20240801T130000"},
+	}
+
+	testCases := []struct {
+		name           string
+		path           string
+		mockSetup      func(*MockNewsProvider)
+		expectedStatus int
+		expectedSizes  []int
+		expectedError  string
+	}{
+		{
+			name: "Clusters articles",
+			path: "/news/TSLA/stories",
+			mockSetup: func(m *MockNewsProvider) {
+				m.On("GetNewsByTicker", mock.Anything, "TSLA").Return(articles, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedSizes:  []int{2, 1},
+		},
+		{
+			name: "Custom window",
+			path: "/news/TSLA/stories?window=1h",
+			mockSetup: func(m *MockNewsProvider) {
+				m.On("GetNewsByTicker", mock.Anything, "TSLA").Return(articles, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedSizes:  []int{1, 1, 1},
+		},
+		{
+			name:           "Invalid window",
+			path:           "/news/TSLA/stories?window=soon",
+			mockSetup:      func(m *MockNewsProvider) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid window parameter.",
+		},
+		{
+			name:           "Invalid ticker",
+			path:           "/news/tsla/stories",
+			mockSetup:      func(m *MockNewsProvider) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid ticker format.",
+		},
+		{
+			name: "Not found",
+			path: "/news/TSLA/stories",
+			mockSetup: func(m *MockNewsProvider) {
+				m.On("GetNewsByTicker", mock.Anything, "TSLA").Return(nil, apperrors.ErrNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "No news found for the specified ticker.",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockFetcher := new(MockNewsProvider)
+			tc.mockSetup(mockFetcher)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tc.path, nil)
+			setupStoriesRouter(mockFetcher).ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+
+			var body struct {
+				Error   string         `json:"error"`
+				Stories []filter.Story `json:"stories"`
+				Total   int            `json:"total"`
+			}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tc.expectedError, body.Error)
+
+			var sizes []int
+			for _, s := range body.Stories {
+				sizes = append(sizes, s.ArticleCount)
+			}
+			assert.Equal(t, tc.expectedSizes, sizes)
+			assert.Equal(t, len(tc.expectedSizes), body.Total)
+			mockFetcher.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/stream"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// maxStreamTickers caps the number of tickers on one stream connection.
+	maxStreamTickers = 20
+
+	// heartbeatInterval keeps idle connections open through proxies and lets
+	// clients detect a dead connection.
+	heartbeatInterval = 15 * time.Second
+
+	// wsWriteTimeout bounds a single WebSocket write to a stalled client.
+	wsWriteTimeout = 10 * time.Second
+)
+
+// wsMessage is a WebSocket frame: an "article" event, a "heartbeat" or an
+// "error" before the server closes the connection.
+type wsMessage struct {
+	Type  string        `json:"type"`
+	Event *stream.Event `json:"event,omitempty"`
+	Error string        `json:"error,omitempty"`
+}
+
+// handleStream pushes newly seen articles for the requested tickers as
+// Server-Sent Events. Each "article" event carries its ID, so a reconnecting
+// client resumes with the Last-Event-ID header. A client that falls too far
+// behind gets an "error" event and is disconnected.
+func handleStream(c *gin.Context, hub *stream.Hub, heartbeat time.Duration) {
+	tickers, lastEventID, ok := parseStreamRequest(c, hub, c.GetHeader("Last-Event-ID"))
+	if !ok {
+		return
+	}
+
+	sub := hub.Subscribe(tickers, lastEventID)
+	defer hub.Unsubscribe(sub)
+
+	log.Info().Strs("tickers", tickers).Uint64("last_event_id", lastEventID).Msg("Stream client connected")
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeats := time.NewTicker(heartbeat)
+	defer heartbeats.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			log.Info().Strs("tickers", tickers).Msg("Stream client disconnected")
+			return
+		case <-heartbeats.C:
+			// A comment line is ignored by EventSource clients.
+			if _, err := c.Writer.WriteString(": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case event, open := <-sub.Events:
+			if !open {
+				if hub.Dropped(sub) {
+					c.SSEvent("error", gin.H{"error": "Client is too slow; reconnect with Last-Event-ID to resume."})
+					c.Writer.Flush()
+				}
+				return
+			}
+			c.Render(-1, sse.Event{
+				Id:    strconv.FormatUint(event.ID, 10),
+				Event: "article",
+				Data:  event,
+			})
+			c.Writer.Flush()
+		}
+	}
+}
+
+// handleStreamWebSocket is handleStream over a WebSocket, for clients that
+// can't use EventSource. Resuming uses the last_event_id query parameter.
+//
+// WebSockets are not subject to CORS, so any page a user visits could open
+// one; only pages on the API's own origin or on one of origins may connect.
+// Clients that send no Origin aren't browsers and are let through.
+func handleStreamWebSocket(c *gin.Context, hub *stream.Hub, heartbeat time.Duration, origins []string) {
+	tickers, lastEventID, ok := parseStreamRequest(c, hub, "")
+	if !ok {
+		return
+	}
+
+	server := websocket.Server{
+		Handshake: func(_ *websocket.Config, r *http.Request) error {
+			origin := r.Header.Get("Origin")
+			if origin == "" || allowedOrigin(origin, r.Host, origins) {
+				return nil
+			}
+			log.Warn().Str("origin", origin).Msg("WebSocket stream origin not allowed")
+			return errOriginNotAllowed
+		},
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+			streamWebSocket(ws, hub, tickers, lastEventID, heartbeat)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+func streamWebSocket(ws *websocket.Conn, hub *stream.Hub, tickers []string, lastEventID uint64, heartbeat time.Duration) {
+	sub := hub.Subscribe(tickers, lastEventID)
+	defer hub.Unsubscribe(sub)
+
+	log.Info().Strs("tickers", tickers).Uint64("last_event_id", lastEventID).Msg("WebSocket stream client connected")
+
+	// Clients don't send anything; reading only detects the close.
+	ctx, cancel := context.WithCancel(ws.Request().Context())
+	defer cancel()
+	go func() {
+		defer cancel()
+		var discard string
+		for websocket.Message.Receive(ws, &discard) == nil {
+		}
+	}()
+
+	send := func(msg wsMessage) bool {
+		if err := ws.SetWriteDeadline(time.Now().Add(wsWriteTimeout)); err != nil {
+			return false
+		}
+		return websocket.JSON.Send(ws, msg) == nil
+	}
+
+	heartbeats := time.NewTicker(heartbeat)
+	defer heartbeats.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Strs("tickers", tickers).Msg("WebSocket stream client disconnected")
+			return
+		case <-heartbeats.C:
+			if !send(wsMessage{Type: "heartbeat"}) {
+				return
+			}
+		case event, open := <-sub.Events:
+			if !open {
+				if hub.Dropped(sub) {
+					send(wsMessage{Type: "error", Error: "Client is too slow; reconnect with last_event_id to resume."})
+				}
+				return
+			}
+			if !send(wsMessage{Type: "article", Event: &event}) {
+				return
+			}
+		}
+	}
+}
+
+// parseStreamRequest validates the tickers and resume position shared by both
+// transports. lastEventHeader takes precedence over the last_event_id query
+// parameter. It writes the error response and returns false when invalid.
+func parseStreamRequest(c *gin.Context, hub *stream.Hub, lastEventHeader string) ([]string, uint64, bool) {
+	if hub == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Streaming is not configured."})
+		return nil, 0, false
+	}
+
+	tickers, ok := parseTickerList(c.Query("tickers"))
+	if !ok || len(tickers) > maxStreamTickers {
+		log.Warn().Str("tickers", c.Query("tickers")).Msg("Invalid tickers parameter")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tickers parameter."})
+		return nil, 0, false
+	}
+
+	resume := lastEventHeader
+	if resume == "" {
+		resume = c.Query("last_event_id")
+	}
+
+	var lastEventID uint64
+	if resume != "" {
+		id, err := strconv.ParseUint(resume, 10, 64)
+		if err != nil {
+			log.Warn().Str("last_event_id", resume).Msg("Invalid last event ID")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid last event ID."})
+			return nil, 0, false
+		}
+		lastEventID = id
+	}
+
+	return tickers, lastEventID, true
+}
+
+// parseTickerList splits a comma-separated list of tickers, dropping
+// duplicates. It returns false if the list is empty or any ticker is invalid.
+func parseTickerList(value string) ([]string, bool) {
+	var tickers []string
+	seen := make(map[string]bool)
+	for _, t := range strings.Split(value, ",") {
+		t = strings.TrimSpace(t)
+		if !validTickerRegex.MatchString(t) {
+			return nil, false
+		}
+		if !seen[t] {
+			seen[t] = true
+			tickers = append(tickers, t)
+		}
+	}
+	return tickers, len(tickers) > 0
+}
+
+var errOriginNotAllowed = errors.New("origin not allowed")
+
+// allowedOrigin reports whether origin is the API's own host or one of
+// origins, compared as scheme://host[:port].
+func allowedOrigin(origin, host string, origins []string) bool {
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	if strings.EqualFold(parsed.Host, host) {
+		return true
+	}
+	for _, allowed := range origins {
+		if strings.EqualFold(strings.TrimSuffix(allowed, "/"), parsed.Scheme+"://"+parsed.Host) {
+			return true
+		}
+	}
+	return false
+}
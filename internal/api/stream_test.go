@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/akhlexe/stocknews-api/internal/stream"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+func setupStreamHubRouter(hub *stream.Hub) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/stream", func(c *gin.Context) {
+		handleStream(c, hub, 10*time.Millisecond)
+	})
+	router.GET("/stream/ws", func(c *gin.Context) {
+		handleStreamWebSocket(c, hub, 10*time.Millisecond, []string{"https://app.example.com"})
+	})
+	return router
+}
+
+// newStreamHub returns a hub that has published one AAPL and one TSLA article.
+func newStreamHub() *stream.Hub {
+	hub := stream.NewHub(stream.Options{})
+	hub.Observe("AAPL", nil)
+	hub.Observe("TSLA", nil)
+	hub.Observe("AAPL", []models.Article{{Title: "Apple first", URL: "https://example.com/1"}})
+	hub.Observe("TSLA", []models.Article{{Title: "Tesla news", URL: "https://example.com/2"}})
+	return hub
+}
+
+func TestHandleStreamResumesFromLastEventID(t *testing.T) {
+	hub := newStreamHub()
+	hub.Observe("AAPL", []models.Article{{Title: "Apple second", URL: "https://example.com/3"}})
+	router := setupStreamHubRouter(hub)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/stream?tickers=AAPL", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+	body := w.Body.String()
+	assert.Contains(t, body, "id:3\nevent:article\ndata:")
+	assert.Contains(t, body, "Apple second")
+	assert.NotContains(t, body, "Apple first")
+	assert.NotContains(t, body, "Tesla news")
+	assert.Contains(t, body, ": heartbeat\n\n")
+}
+
+func TestHandleStreamValidation(t *testing.T) {
+	router := setupStreamHubRouter(stream.NewHub(stream.Options{}))
+
+	tests := []struct {
+		name  string
+		url   string
+		error string
+	}{
+		{"missing tickers", "/stream", "Invalid tickers parameter."},
+		{"invalid ticker", "/stream?tickers=AAPL,bad!", "Invalid tickers parameter."},
+		{"too many tickers", "/stream?tickers=A,B,C,D,E,F,G,H,I,J,K,L,M,N,O,P,Q,R,S,T,U", "Invalid tickers parameter."},
+		{"invalid last event ID", "/stream?tickers=AAPL&last_event_id=abc", "Invalid last event ID."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, `{"error":"`+tt.error+`"}`, w.Body.String())
+		})
+	}
+}
+
+func TestHandleStreamNotConfigured(t *testing.T) {
+	router := setupStreamHubRouter(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/stream?tickers=AAPL", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestHandleStreamWebSocket(t *testing.T) {
+	hub := newStreamHub()
+	server := httptest.NewServer(setupStreamHubRouter(hub))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/stream/ws?tickers=AAPL,TSLA&last_event_id=1"
+	ws, err := websocket.Dial(url, "", server.URL)
+	require.NoError(t, err)
+	defer ws.Close()
+
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(time.Second)))
+
+	var msg wsMessage
+	require.NoError(t, websocket.JSON.Receive(ws, &msg))
+	assert.Equal(t, "article", msg.Type)
+	require.NotNil(t, msg.Event)
+	assert.Equal(t, uint64(2), msg.Event.ID)
+	assert.Equal(t, "TSLA", msg.Event.Ticker)
+
+	// Live events follow the replay.
+	hub.Observe("AAPL", []models.Article{{Title: "Apple live", URL: "https://example.com/live"}})
+	for {
+		msg = wsMessage{}
+		require.NoError(t, websocket.JSON.Receive(ws, &msg))
+		if msg.Type == "article" {
+			break
+		}
+		assert.Equal(t, "heartbeat", msg.Type)
+	}
+	assert.Equal(t, "Apple live", msg.Event.Article.Title)
+}
+
+func TestHandleStreamWebSocketChecksOrigin(t *testing.T) {
+	server := httptest.NewServer(setupStreamHubRouter(newStreamHub()))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/stream/ws?tickers=AAPL"
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{server.URL, true},
+		{"https://app.example.com", true},
+		{"https://evil.example.com", false},
+		{"http://app.example.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			ws, err := websocket.Dial(url, "", tt.origin)
+			if !tt.allowed {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			ws.Close()
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/akhlexe/stocknews-api/internal/sentiment"
+	"github.com/akhlexe/stocknews-api/internal/storage"
+	"github.com/akhlexe/stocknews-api/internal/webhooks"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// maxSubscriptionTickers caps the number of tickers in one subscription.
+	maxSubscriptionTickers = 50
+
+	// defaultDeliveryLimit is the number of deliveries listed when no limit
+	// is given.
+	defaultDeliveryLimit = 20
+)
+
+var sentimentLabels = []string{
+	sentiment.LabelBearish,
+	sentiment.LabelSomewhatBearish,
+	sentiment.LabelNeutral,
+	sentiment.LabelSomewhatBullish,
+	sentiment.LabelBullish,
+}
+
+type subscriptionRequest struct {
+	URL     string           `json:"url"`
+	Tickers []string         `json:"tickers"`
+	Filters webhooks.Filters `json:"filters"`
+	Secret  string           `json:"secret"`
+}
+
+// handleCreateSubscription registers a webhook. The response is the only one
+// that includes the signing secret.
+func handleCreateSubscription(c *gin.Context, service *webhooks.Service) {
+	var body subscriptionRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.Warn().Err(err).Msg("Invalid subscription request body")
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid request body.")
+		return
+	}
+
+	if err := webhooks.ValidateURL(body.URL); err != nil {
+		log.Warn().Err(err).Str("url", body.URL).Msg("Invalid webhook URL")
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid url parameter.")
+		return
+	}
+
+	if len(body.Tickers) == 0 || len(body.Tickers) > maxSubscriptionTickers {
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid tickers parameter.")
+		return
+	}
+	for _, ticker := range body.Tickers {
+		if !validTickerRegex.MatchString(ticker) {
+			log.Warn().Str("ticker", ticker).Msg("Invalid ticker format")
+			writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid ticker format.")
+			return
+		}
+	}
+
+	for _, label := range body.Filters.Sentiment {
+		if !containsFold(sentimentLabels, label) {
+			log.Warn().Str("sentiment", label).Msg("Invalid sentiment filter")
+			writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid filters parameter.")
+			return
+		}
+	}
+
+	if body.Secret != "" && len(body.Secret) < webhooks.MinSecretLength {
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid secret parameter.")
+		return
+	}
+
+	if service == nil {
+		writeError(c, http.StatusServiceUnavailable, codeNotConfigured, "Webhooks are not configured.")
+		return
+	}
+
+	sub, err := service.Create(c, webhooks.Subscription{
+		KeyID:   requestKeyID(c),
+		URL:     body.URL,
+		Tickers: body.Tickers,
+		Filters: body.Filters,
+		Secret:  body.Secret,
+	})
+	if errors.Is(err, webhooks.ErrInvalidSubscription) {
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid subscription.")
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create webhook subscription")
+		writeError(c, http.StatusInternalServerError, codeInternal, "Internal server error.")
+		return
+	}
+
+	c.Header("Location", resourcePath(c, "/subscriptions/"+sub.ID))
+	respondStatus(c, http.StatusCreated, nil, sub)
+}
+
+func handleListSubscriptions(c *gin.Context, service *webhooks.Service) {
+	if service == nil {
+		writeError(c, http.StatusServiceUnavailable, codeNotConfigured, "Webhooks are not configured.")
+		return
+	}
+
+	keyID := requestKeyID(c)
+	subs := service.List()
+	redacted := make([]webhooks.Subscription, 0, len(subs))
+	for _, sub := range subs {
+		if sub.KeyID == keyID {
+			redacted = append(redacted, sub.Redacted())
+		}
+	}
+
+	respond(c, nil, gin.H{"subscriptions": redacted})
+}
+
+func handleGetSubscription(c *gin.Context, service *webhooks.Service) {
+	if service == nil {
+		writeError(c, http.StatusServiceUnavailable, codeNotConfigured, "Webhooks are not configured.")
+		return
+	}
+
+	sub, ok := ownedSubscription(c, service, c.Param("id"))
+	if !ok {
+		writeError(c, http.StatusNotFound, codeNotFound, "Subscription not found.")
+		return
+	}
+
+	respond(c, nil, sub.Redacted())
+}
+
+func handleDeleteSubscription(c *gin.Context, service *webhooks.Service) {
+	if service == nil {
+		writeError(c, http.StatusServiceUnavailable, codeNotConfigured, "Webhooks are not configured.")
+		return
+	}
+
+	if _, ok := ownedSubscription(c, service, c.Param("id")); !ok {
+		writeError(c, http.StatusNotFound, codeNotFound, "Subscription not found.")
+		return
+	}
+
+	deleted, err := service.Delete(c, c.Param("id"))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to delete webhook subscription")
+		writeError(c, http.StatusInternalServerError, codeInternal, "Internal server error.")
+		return
+	}
+	if !deleted {
+		writeError(c, http.StatusNotFound, codeNotFound, "Subscription not found.")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// handleListDeliveries returns a subscription's most recent deliveries with
+// their attempt log. status=dead lists the dead-lettered ones.
+func handleListDeliveries(c *gin.Context, service *webhooks.Service) {
+	status := c.Query("status")
+	if status != "" && status != storage.DeliveryPending && status != storage.DeliveryDelivered && status != storage.DeliveryDead {
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid status parameter.")
+		return
+	}
+
+	limit, err := parseLimit(c.Query("limit"))
+	if err != nil {
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid limit parameter.")
+		return
+	}
+	if limit == 0 {
+		limit = defaultDeliveryLimit
+	}
+
+	if service == nil {
+		writeError(c, http.StatusServiceUnavailable, codeNotConfigured, "Webhooks are not configured.")
+		return
+	}
+
+	id := c.Param("id")
+	if _, ok := ownedSubscription(c, service, id); !ok {
+		writeError(c, http.StatusNotFound, codeNotFound, "Subscription not found.")
+		return
+	}
+
+	deliveries, err := service.Deliveries(c, id, status, limit)
+	if err != nil {
+		log.Error().Err(err).Str("subscription_id", id).Msg("Failed to list webhook deliveries")
+		writeError(c, http.StatusInternalServerError, codeInternal, "Internal server error.")
+		return
+	}
+
+	respond(c, nil, gin.H{"deliveries": deliveries})
+}
+
+// ownedSubscription returns the subscription with the given ID if it belongs
+// to the request's key.
+func ownedSubscription(c *gin.Context, service *webhooks.Service, id string) (webhooks.Subscription, bool) {
+	sub, ok := service.Get(id)
+	if !ok || sub.KeyID != requestKeyID(c) {
+		return webhooks.Subscription{}, false
+	}
+	return sub, true
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/storage"
+	"github.com/akhlexe/stocknews-api/internal/stream"
+	"github.com/akhlexe/stocknews-api/internal/webhooks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// subscriptionStore keeps subscriptions in memory and has no deliveries.
+type subscriptionStore struct {
+	mu            sync.Mutex
+	subscriptions map[string][]byte
+}
+
+func (s *subscriptionStore) SaveSubscription(ctx context.Context, id string, subscription []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscriptions[id] = subscription
+	return nil
+}
+
+func (s *subscriptionStore) GetSubscriptions(ctx context.Context) ([][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var subs [][]byte
+	for _, data := range s.subscriptions {
+		subs = append(subs, data)
+	}
+	return subs, nil
+}
+
+func (s *subscriptionStore) DeleteSubscription(ctx context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.subscriptions[id]
+	delete(s.subscriptions, id)
+	return ok, nil
+}
+
+func (s *subscriptionStore) CreateDelivery(ctx context.Context, delivery storage.Delivery) (bool, error) {
+	return true, nil
+}
+
+func (s *subscriptionStore) SaveDelivery(ctx context.Context, delivery storage.Delivery) error {
+	return nil
+}
+
+func (s *subscriptionStore) ClaimDueDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]storage.Delivery, error) {
+	return nil, nil
+}
+
+func (s *subscriptionStore) GetDeliveries(ctx context.Context, subscriptionID string, status string, limit int) ([]storage.Delivery, error) {
+	return nil, nil
+}
+
+func (s *subscriptionStore) SaveDeliveryAttempt(ctx context.Context, attempt storage.DeliveryAttempt) error {
+	return nil
+}
+
+func (s *subscriptionStore) GetDeliveryAttempts(ctx context.Context, deliveryID string) ([]storage.DeliveryAttempt, error) {
+	return nil, nil
+}
+
+func setupSubscriptionRouter(service *webhooks.Service) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/subscriptions", func(c *gin.Context) { handleCreateSubscription(c, service) })
+	router.GET("/subscriptions", func(c *gin.Context) { handleListSubscriptions(c, service) })
+	router.GET("/subscriptions/:id", func(c *gin.Context) { handleGetSubscription(c, service) })
+	router.DELETE("/subscriptions/:id", func(c *gin.Context) { handleDeleteSubscription(c, service) })
+	router.GET("/subscriptions/:id/deliveries", func(c *gin.Context) { handleListDeliveries(c, service) })
+	return router
+}
+
+func newWebhookService() *webhooks.Service {
+	store := &subscriptionStore{subscriptions: make(map[string][]byte)}
+	return webhooks.NewService(store, stream.NewHub(stream.Options{}), webhooks.Options{})
+}
+
+func TestSubscriptionLifecycle(t *testing.T) {
+	router := setupSubscriptionRouter(newWebhookService())
+
+	w := httptest.NewRecorder()
+	body := `{"url": "https://hooks.example.com/news", "tickers": ["AAPL"], "filters": {"sentiment": ["bullish"], "keywords": ["earnings"]}}`
+	req, _ := http.NewRequest(http.MethodPost, "/subscriptions", bytes.NewBufferString(body))
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created webhooks.Subscription
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.NotEmpty(t, created.ID)
+	assert.NotEmpty(t, created.Secret)
+	assert.Equal(t, []string{"earnings"}, created.Filters.Keywords)
+	assert.Equal(t, "/subscriptions/"+created.ID, w.Header().Get("Location"))
+
+	// The secret is never shown again.
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/subscriptions", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), created.ID)
+	assert.NotContains(t, w.Body.String(), "secret")
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/subscriptions/"+created.ID, nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), created.Secret)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/subscriptions/"+created.ID+"/deliveries?status=dead", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"deliveries":[]}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodDelete, "/subscriptions/"+created.ID, nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	for _, path := range []string{"/subscriptions/" + created.ID, "/subscriptions/" + created.ID + "/deliveries"} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code, path)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodDelete, "/subscriptions/"+created.ID, nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestCreateSubscriptionValidation(t *testing.T) {
+	router := setupSubscriptionRouter(newWebhookService())
+
+	tests := []struct {
+		name  string
+		body  string
+		error string
+	}{
+		{"invalid body", `{`, "Invalid request body."},
+		{"missing url", `{"tickers": ["AAPL"]}`, "Invalid url parameter."},
+		{"relative url", `{"url": "/hook", "tickers": ["AAPL"]}`, "Invalid url parameter."},
+		{"no tickers", `{"url": "https://example.com", "tickers": []}`, "Invalid tickers parameter."},
+		{"invalid ticker", `{"url": "https://example.com", "tickers": ["aapl"]}`, "Invalid ticker format."},
+		{"invalid sentiment", `{"url": "https://example.com", "tickers": ["AAPL"], "filters": {"sentiment": ["great"]}}`, "Invalid filters parameter."},
+		{"short secret", `{"url": "https://example.com", "tickers": ["AAPL"], "secret": "short"}`, "Invalid secret parameter."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/subscriptions", bytes.NewBufferString(tt.body))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, `{"error":"`+tt.error+`"}`, w.Body.String())
+		})
+	}
+}
+
+func TestListDeliveriesInvalidStatus(t *testing.T) {
+	router := setupSubscriptionRouter(newWebhookService())
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/subscriptions/abc/deliveries?status=lost", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSubscriptionsNotConfigured(t *testing.T) {
+	router := setupSubscriptionRouter(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/subscriptions", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
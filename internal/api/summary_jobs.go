@@ -0,0 +1,104 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/akhlexe/stocknews-api/internal/ai"
+	"github.com/akhlexe/stocknews-api/internal/jobs"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// maxJobTickers caps the number of tickers in one summary job.
+const maxJobTickers = 20
+
+type summaryJobRequest struct {
+	Tickers []string `json:"tickers"`
+	Style   string   `json:"style"`
+	Format  string   `json:"format"`
+}
+
+// handleCreateSummaryJob queues a summary job and answers 202 with the job,
+// whose status can be polled at the Location header.
+func handleCreateSummaryJob(c *gin.Context, queue *jobs.Queue) {
+	var body summaryJobRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.Warn().Err(err).Msg("Invalid summary job request body")
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid request body.")
+		return
+	}
+
+	if len(body.Tickers) == 0 || len(body.Tickers) > maxJobTickers {
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid tickers parameter.")
+		return
+	}
+	for _, ticker := range body.Tickers {
+		if !validTickerRegex.MatchString(ticker) {
+			log.Warn().Str("ticker", ticker).Msg("Invalid ticker format")
+			writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid ticker format.")
+			return
+		}
+	}
+
+	if body.Style == "" {
+		body.Style = ai.DefaultStyle
+	}
+	if !ai.IsValidStyle(body.Style) {
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid style parameter.")
+		return
+	}
+
+	if body.Format == "" {
+		body.Format = summaryFormatText
+	}
+	if body.Format != summaryFormatText && body.Format != summaryFormatStructured {
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid format parameter.")
+		return
+	}
+
+	if queue == nil {
+		log.Warn().Msg("Summary job requested but no AI backend is configured")
+		writeError(c, http.StatusServiceUnavailable, codeNotConfigured, "AI summarization is not configured.")
+		return
+	}
+
+	job, err := queue.Submit(jobs.Request{
+		Tickers:    body.Tickers,
+		Style:      body.Style,
+		Structured: body.Format == summaryFormatStructured,
+	})
+	if errors.Is(err, jobs.ErrQueueFull) {
+		log.Warn().Strs("tickers", body.Tickers).Msg("Summary queue is full")
+		c.Header("Retry-After", "30")
+		writeError(c, http.StatusServiceUnavailable, codeServiceUnavailable, "Summary queue is full.")
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to queue summary job")
+		writeError(c, http.StatusInternalServerError, codeInternal, "Internal server error.")
+		return
+	}
+
+	c.Header("Location", resourcePath(c, "/summaries/"+job.ID))
+	respondStatus(c, http.StatusAccepted, nil, job)
+}
+
+// handleGetSummaryJob returns a job's status and, once finished, its results.
+func handleGetSummaryJob(c *gin.Context, queue *jobs.Queue) {
+	id := strings.TrimSpace(c.Param("id"))
+
+	if queue == nil {
+		writeError(c, http.StatusServiceUnavailable, codeNotConfigured, "AI summarization is not configured.")
+		return
+	}
+
+	job, ok := queue.Get(id)
+	if !ok {
+		writeError(c, http.StatusNotFound, codeNotFound, "Summary job not found.")
+		return
+	}
+
+	respond(c, nil, job)
+}
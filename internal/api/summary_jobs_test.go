@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/ai"
+	"github.com/akhlexe/stocknews-api/internal/jobs"
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupJobsRouter(queue *jobs.Queue) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/summaries", func(c *gin.Context) {
+		handleCreateSummaryJob(c, queue)
+	})
+	router.GET("/summaries/:id", func(c *gin.Context) {
+		handleGetSummaryJob(c, queue)
+	})
+	return router
+}
+
+func TestSummaryJobs(t *testing.T) {
+	mockFetcher := new(MockNewsProvider)
+	mockFetcher.On("GetNewsByTicker", mock.Anything, "AAPL").Return([]models.Article{{Title: "Apple", Summary: "News"}}, nil)
+	mockSummarizer := new(MockSummarizer)
+	mockSummarizer.On("Generate", mock.Anything, mock.Anything).Return("Apple summary", nil)
+
+	queue := jobs.NewQueue(mockFetcher, ai.NewSummaryService(mockSummarizer, nil, ai.SummaryOptions{}), jobs.Options{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	queue.Start(ctx)
+
+	router := setupJobsRouter(queue)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/summaries", strings.NewReader(`{"tickers": ["AAPL"], "style": "brief"}`))
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusAccepted, w.Code)
+	var created jobs.Job
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, jobs.StatusQueued, created.Status)
+	assert.Equal(t, jobs.Request{Tickers: []string{"AAPL"}, Style: "brief"}, created.Request)
+	assert.Equal(t, "/summaries/"+created.ID, w.Header().Get("Location"))
+
+	var polled jobs.Job
+	assert.Eventually(t, func() bool {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/summaries/"+created.ID, nil)
+		router.ServeHTTP(w, req)
+		json.Unmarshal(w.Body.Bytes(), &polled)
+		return w.Code == http.StatusOK && polled.Status == jobs.StatusDone
+	}, time.Second, 5*time.Millisecond)
+
+	require.Len(t, polled.Results, 1)
+	assert.Equal(t, "Apple summary", polled.Results[0].Summary)
+	assert.Equal(t, "summary-brief@v2", polled.Results[0].PromptVersion)
+}
+
+func TestSummaryJobErrors(t *testing.T) {
+	queue := jobs.NewQueue(new(MockNewsProvider), ai.NewSummaryService(new(MockSummarizer), nil, ai.SummaryOptions{}), jobs.Options{QueueSize: 1})
+
+	testCases := []struct {
+		name           string
+		queue          *jobs.Queue
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Invalid body", queue, http.MethodPost, "/summaries", `tickers`, http.StatusBadRequest, `{"error": "Invalid request body."}`},
+		{"No tickers", queue, http.MethodPost, "/summaries", `{"tickers": []}`, http.StatusBadRequest, `{"error": "Invalid tickers parameter."}`},
+		{"Invalid ticker", queue, http.MethodPost, "/summaries", `{"tickers": ["aapl"]}`, http.StatusBadRequest, `{"error": "Invalid ticker format."}`},
+		{"Invalid style", queue, http.MethodPost, "/summaries", `{"tickers": ["AAPL"], "style": "poem"}`, http.StatusBadRequest, `{"error": "Invalid style parameter."}`},
+		{"Invalid format", queue, http.MethodPost, "/summaries", `{"tickers": ["AAPL"], "format": "xml"}`, http.StatusBadRequest, `{"error": "Invalid format parameter."}`},
+		{"Not configured", nil, http.MethodPost, "/summaries", `{"tickers": ["AAPL"]}`, http.StatusServiceUnavailable, `{"error": "AI summarization is not configured."}`},
+		{"Unknown job", queue, http.MethodGet, "/summaries/unknown", ``, http.StatusNotFound, `{"error": "Summary job not found."}`},
+		// The queue is never started, so the first job fills it.
+		{"Queued", queue, http.MethodPost, "/summaries", `{"tickers": ["AAPL"]}`, http.StatusAccepted, ``},
+		{"Queue full", queue, http.MethodPost, "/summaries", `{"tickers": ["AAPL"]}`, http.StatusServiceUnavailable, `{"error": "Summary queue is full."}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			setupJobsRouter(tc.queue).ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedBody != "" {
+				assert.JSONEq(t, tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/ai"
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/akhlexe/stocknews-api/internal/news"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// summarySource identifies an article that was fed to the model.
+type summarySource struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// handleSummaryStream streams an AI summary as Server-Sent Events. Each "token"
+// event carries a chunk of generated text, followed by a single "done" event
+// with the articles that were summarized, or an "error" event.
+func handleSummaryStream(c *gin.Context, fetcher news.Provider, summaries *ai.SummaryService) {
+	ticker := c.Param("ticker")
+	style := c.DefaultQuery("style", ai.DefaultStyle)
+	requestLog := log.With().Str("ticker", ticker).Logger()
+
+	if !validTickerRegex.MatchString(ticker) {
+		requestLog.Warn().Msg("Invalid ticker format")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticker format."})
+		return
+	}
+
+	if !ai.IsValidStyle(style) {
+		requestLog.Warn().Str("style", style).Msg("Invalid style parameter")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid style parameter."})
+		return
+	}
+
+	if summaries == nil {
+		requestLog.Warn().Msg("Summary requested but no AI backend is configured")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "AI summarization is not configured."})
+		return
+	}
+
+	fetchCtx, cancelFetch := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancelFetch()
+
+	articles, err := fetcher.GetNewsByTicker(fetchCtx, ticker)
+	if err != nil {
+		requestLog.Error().Err(err).Msg("Error processing summary stream request")
+		writeFetchError(c, err)
+		return
+	}
+
+	// The whole summary, map steps included, stops once the client has gone
+	// away or the deadline passes.
+	summaryCtx, cancelSummary := context.WithTimeout(c.Request.Context(), summaryTimeout)
+	defer cancelSummary()
+	summaryCtx = ai.WithSlotWait(summaryCtx, summarySlotWait)
+
+	// The event stream starts with the first token, so that errors before it
+	// still get a plain error response with the right status.
+	started := false
+	start := func() {
+		if started {
+			return
+		}
+		started = true
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+	}
+	onToken := func(token string) error {
+		start()
+		c.SSEvent("token", gin.H{"token": token})
+		c.Writer.Flush()
+		return summaryCtx.Err()
+	}
+
+	summaryReq := ai.SummaryRequest{Ticker: ticker, Articles: articles, Style: style}
+	summary, err := summaries.SummarizeStream(summaryCtx, summaryReq, onToken)
+	if err != nil {
+		requestLog.Error().Err(err).Msg("Failed to stream AI summary")
+		if !started {
+			writeAIError(c, err)
+			return
+		}
+		c.SSEvent("error", gin.H{"error": "Failed to generate summary."})
+		c.Writer.Flush()
+		return
+	}
+
+	start()
+	c.SSEvent("done", gin.H{
+		"ticker":            ticker,
+		"articles_included": summary.ArticlesIncluded,
+		"articles_total":    len(articles),
+		"articles":          summarySources(articles[:summary.ArticlesIncluded]),
+		"cached":            summary.Cached,
+		"prompt_version":    summary.PromptVersion,
+		"warnings":          nonNilStrings(summary.Warnings),
+	})
+	c.Writer.Flush()
+
+	requestLog.Info().Int("articles_included", summary.ArticlesIncluded).Msg("AI summary streamed successfully")
+}
+
+func summarySources(articles []models.Article) []summarySource {
+	sources := make([]summarySource, 0, len(articles))
+	for _, a := range articles {
+		sources = append(sources, summarySource{ID: a.ID(), Title: a.Title, URL: a.URL})
+	}
+	return sources
+}
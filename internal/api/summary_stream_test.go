@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/ai"
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/akhlexe/stocknews-api/internal/news"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type fakeStreamingSummarizer struct {
+	tokens []string
+}
+
+func (f *fakeStreamingSummarizer) Generate(ctx context.Context, prompt string) (string, error) {
+	return strings.Join(f.tokens, ""), nil
+}
+
+func (f *fakeStreamingSummarizer) GenerateStream(ctx context.Context, prompt string, onToken func(string) error) (string, error) {
+	for _, token := range f.tokens {
+		if err := onToken(token); err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(f.tokens, ""), nil
+}
+
+func (f *fakeStreamingSummarizer) Model() string {
+	return "test-model"
+}
+
+func setupStreamRouter(fetcher news.Provider, summarizer ai.Summarizer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	summaries := ai.NewSummaryService(summarizer, nil, ai.SummaryOptions{})
+	router.GET("/news/:ticker/summary/stream", func(c *gin.Context) {
+		handleSummaryStream(c, fetcher, summaries)
+	})
+	return router
+}
+
+func TestHandleSummaryStream(t *testing.T) {
+	articles := []models.Article{
+		{Title: "Test Stock Up", URL: "https://example.com/up", Summary: "Good news for TEST"},
+	}
+
+	mockFetcher := new(MockNewsProvider)
+	mockFetcher.On("GetNewsByTicker", mock.Anything, "TEST").Return(articles, nil).Once()
+
+	router := setupStreamRouter(mockFetcher, &fakeStreamingSummarizer{tokens: []string{"Shares ", "rose."}})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/news/TEST/summary/stream", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+	body := w.Body.String()
+	assert.Contains(t, body, "event:token\ndata:{\"token\":\"Shares \"}\n\n")
+	assert.Contains(t, body, "event:token\ndata:{\"token\":\"rose.\"}\n\n")
+	assert.Contains(t, body, "event:done\n")
+	assert.Contains(t, body, `"articles_included":1`)
+	assert.Contains(t, body, `"id":"`+articles[0].ID()+`"`)
+	assert.Less(t, strings.Index(body, "rose."), strings.Index(body, "event:done"))
+
+	mockFetcher.AssertExpectations(t)
+}
+
+func TestHandleSummaryStreamNonStreamingBackend(t *testing.T) {
+	mockFetcher := new(MockNewsProvider)
+	mockFetcher.On("GetNewsByTicker", mock.Anything, "TEST").
+		Return([]models.Article{{Title: "Test", Summary: "Summary"}}, nil).Once()
+
+	mockSummarizer := new(MockSummarizer)
+	mockSummarizer.On("Generate", mock.Anything, mock.Anything).Return("Full summary.", nil).Once()
+
+	router := setupStreamRouter(mockFetcher, mockSummarizer)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/news/TEST/summary/stream", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Contains(t, w.Body.String(), "event:token\ndata:{\"token\":\"Full summary.\"}\n\n")
+	assert.Contains(t, w.Body.String(), "event:done\n")
+	mockSummarizer.AssertExpectations(t)
+}
+
+// waitingSummarizer blocks every call until its context is done and reports
+// the context's error.
+type waitingSummarizer struct {
+	started  chan struct{}
+	canceled chan error
+}
+
+func (w *waitingSummarizer) Generate(ctx context.Context, prompt string) (string, error) {
+	close(w.started)
+	<-ctx.Done()
+	w.canceled <- ctx.Err()
+	return "", ctx.Err()
+}
+
+func (w *waitingSummarizer) Model() string { return "test-model" }
+
+func TestSummariesStopWhenClientDisconnects(t *testing.T) {
+	articles := []models.Article{{Title: "Test Stock Up", URL: "https://example.com/up", Summary: "Good news for TEST"}}
+
+	for _, path := range []string{"/news/TEST?summarize=true", "/news/TEST/summary/stream", "/v1/news/TEST?summarize=true"} {
+		t.Run(path, func(t *testing.T) {
+			summarizer := &waitingSummarizer{started: make(chan struct{}), canceled: make(chan error, 1)}
+			gin.SetMode(gin.TestMode)
+			router := (&Server{
+				MultiFetcher: news.NewMultiFetcher(&reportingProvider{name: "alphavantage", articles: articles}),
+				Summaries:    ai.NewSummaryService(summarizer, nil, ai.SummaryOptions{}),
+			}).Router()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+			done := make(chan struct{})
+			go func() {
+				router.ServeHTTP(httptest.NewRecorder(), req)
+				close(done)
+			}()
+
+			<-summarizer.started
+			cancel()
+
+			select {
+			case err := <-summarizer.canceled:
+				assert.ErrorIs(t, err, context.Canceled)
+			case <-time.After(time.Second):
+				t.Fatal("generation kept running after the client went away")
+			}
+			<-done
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/news"
+	"github.com/gin-gonic/gin"
+)
+
+// requestIDContextKey holds the request ID in the gin context.
+const requestIDContextKey = "request_id"
+
+// validRequestID limits the client-supplied X-Request-ID values echoed back.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// Envelope is the body of every successful /v1 response.
+type Envelope struct {
+	Data interface{} `json:"data"`
+	Meta Meta        `json:"meta"`
+}
+
+// Meta describes how a /v1 response was produced.
+type Meta struct {
+	RequestID string `json:"request_id"`
+
+	// FetchedAt is when the oldest of the articles behind the response were
+	// fetched from upstream.
+	FetchedAt time.Time        `json:"fetched_at"`
+	Cache     news.CacheStatus `json:"cache"`
+	Providers []string         `json:"providers"`
+}
+
+// newsPage is the body of a page of articles.
+type newsPage struct {
+	Ticker string `json:"ticker"`
+
+	// News holds []models.Article, or projected articles when fields is set.
+	News       interface{} `json:"news"`
+	Total      int         `json:"total"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// newsSummary is the body of a news summary.
+type newsSummary struct {
+	Ticker string `json:"ticker"`
+
+	// Summary is text, or an ai.StructuredSummary.
+	Summary          interface{} `json:"summary"`
+	Cached           bool        `json:"cached"`
+	PromptVersion    string      `json:"prompt_version"`
+	Warnings         []string    `json:"warnings"`
+	ArticlesIncluded int         `json:"articles_included"`
+	ArticlesTotal    int         `json:"articles_total"`
+}
+
+// requestIDMiddleware gives every request an ID, keeping a valid X-Request-ID
+// from the client, and returns it in the X-Request-ID header.
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader("X-Request-ID")
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+
+		c.Set(requestIDContextKey, id)
+		c.Header("X-Request-ID", id)
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().UTC().Format("20060102T150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
+
+// respond writes data as is on legacy routes, and in an Envelope described by
+// report on /v1 routes.
+func respond(c *gin.Context, report *news.FetchReport, data interface{}) {
+	respondStatus(c, http.StatusOK, report, data)
+}
+
+// respondStatus is respond with a status other than 200. report is nil for
+// responses that aren't built from fetched articles.
+func respondStatus(c *gin.Context, status int, report *news.FetchReport, data interface{}) {
+	if !isV1(c) {
+		c.JSON(status, data)
+		return
+	}
+	if report == nil {
+		report = &news.FetchReport{}
+	}
+
+	fetchedAt := report.FetchedAt()
+	if fetchedAt.IsZero() {
+		fetchedAt = time.Now()
+	}
+
+	c.JSON(status, Envelope{
+		Data: data,
+		Meta: Meta{
+			RequestID: c.GetString(requestIDContextKey),
+			FetchedAt: fetchedAt.UTC(),
+			Cache:     report.Status(),
+			Providers: report.Providers(),
+		},
+	})
+}
+
+// handleNotFound answers unknown routes, with problem details under /v1.
+func handleNotFound(c *gin.Context) {
+	if isV1(c) {
+		writeError(c, http.StatusNotFound, codeNotFound, "Route not found.")
+		return
+	}
+	c.String(http.StatusNotFound, "404 page not found")
+}
+
+// resourcePath is the path of a resource under the API version of the request,
+// for Location headers.
+func resourcePath(c *gin.Context, path string) string {
+	if isV1(c) {
+		return "/v1" + path
+	}
+	return path
+}
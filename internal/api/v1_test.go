@@ -0,0 +1,266 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/ai"
+	"github.com/akhlexe/stocknews-api/internal/apperrors"
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/akhlexe/stocknews-api/internal/news"
+	"github.com/akhlexe/stocknews-api/internal/watchlists"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// reportingProvider serves fixed articles and reports them like a cached
+// provider would.
+type reportingProvider struct {
+	name      string
+	status    news.CacheStatus
+	fetchedAt time.Time
+	expires   time.Time
+	articles  []models.Article
+	err       error
+}
+
+func (p *reportingProvider) GetNewsByTicker(ctx context.Context, ticker string) ([]models.Article, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	news.RecordFetch(ctx, news.Fetch{Provider: p.name, Status: p.status, FetchedAt: p.fetchedAt, Expires: p.expires})
+	return p.articles, nil
+}
+
+func setupV1Router(providers ...news.Provider) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	return (&Server{MultiFetcher: news.NewMultiFetcher(providers...)}).Router()
+}
+
+func TestV1NewsEnvelope(t *testing.T) {
+	fetchedAt := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	router := setupV1Router(
+		&reportingProvider{name: "alphavantage", status: news.CacheMemory, fetchedAt: fetchedAt.Add(time.Minute),
+			articles: []models.Article{{Title: "Apple beats estimates", Tickers: []string{"AAPL"}}}},
+		&reportingProvider{name: "backup", status: news.CacheStale, fetchedAt: fetchedAt,
+			articles: []models.Article{{Title: "Apple ships", Tickers: []string{"AAPL"}}}},
+	)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/v1/news/AAPL?limit=1", nil)
+	req.Header.Set("X-Request-ID", "req-123")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "req-123", w.Header().Get("X-Request-ID"))
+
+	var body struct {
+		Data struct {
+			Ticker     string           `json:"ticker"`
+			News       []models.Article `json:"news"`
+			Total      int              `json:"total"`
+			NextCursor string           `json:"next_cursor"`
+		} `json:"data"`
+		Meta Meta `json:"meta"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+	assert.Equal(t, "AAPL", body.Data.Ticker)
+	assert.Len(t, body.Data.News, 1)
+	assert.Equal(t, 2, body.Data.Total)
+	assert.NotEmpty(t, body.Data.NextCursor)
+
+	assert.Equal(t, "req-123", body.Meta.RequestID)
+	assert.Equal(t, news.CacheStale, body.Meta.Cache)
+	assert.Equal(t, []string{"alphavantage", "backup"}, body.Meta.Providers)
+	assert.True(t, fetchedAt.Equal(body.Meta.FetchedAt))
+}
+
+func TestV1NewsWithoutReportingProvider(t *testing.T) {
+	router := setupV1Router(providerFunc(func(ctx context.Context, ticker string) ([]models.Article, error) {
+		return []models.Article{{Title: "Apple ships"}}, nil
+	}))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/v1/news/AAPL", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var body Envelope
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, news.CacheUpstream, body.Meta.Cache)
+	assert.Equal(t, []string{}, body.Meta.Providers)
+	assert.NotEmpty(t, body.Meta.RequestID)
+	assert.Equal(t, w.Header().Get("X-Request-ID"), body.Meta.RequestID)
+	assert.WithinDuration(t, time.Now(), body.Meta.FetchedAt, time.Minute)
+}
+
+type providerFunc func(ctx context.Context, ticker string) ([]models.Article, error)
+
+func (f providerFunc) GetNewsByTicker(ctx context.Context, ticker string) ([]models.Article, error) {
+	return f(ctx, ticker)
+}
+
+func TestV1Problems(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		err    error
+		status int
+		code   string
+		detail string
+	}{
+		{"invalid ticker", "/v1/news/aapl", nil, http.StatusBadRequest, codeInvalidParameter, "Invalid ticker format."},
+		{"unknown parameter", "/v1/news/AAPL?page=2", nil, http.StatusBadRequest, codeUnknownParameter, "Unknown page parameter."},
+		{"not found", "/v1/news/AAPL", apperrors.ErrNotFound, http.StatusNotFound, codeNotFound, "No news found for the specified ticker."},
+		{"upstream down", "/v1/news/AAPL", apperrors.ErrServiceUnavailable, http.StatusServiceUnavailable, codeServiceUnavailable, "External service unavailable."},
+		{"timeout", "/v1/news/AAPL", context.DeadlineExceeded, http.StatusGatewayTimeout, codeTimeout, "Request timed out."},
+		{"no summarizer", "/v1/news/AAPL?summarize=true", nil, http.StatusServiceUnavailable, codeNotConfigured, "AI summarization is not configured."},
+		{"unknown route", "/v1/nothing", nil, http.StatusNotFound, codeNotFound, "Route not found."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupV1Router(&reportingProvider{name: "alphavantage", err: tt.err,
+				articles: []models.Article{{Title: "Apple ships", Summary: "Apple ships phones"}}})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.status, w.Code)
+			assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+
+			var problem Problem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, tt.code, problem.Code)
+			assert.Equal(t, "urn:stocknews:problem:"+tt.code, problem.Type)
+			assert.Equal(t, tt.status, problem.Status)
+			assert.Equal(t, tt.detail, problem.Detail)
+			assert.NotEmpty(t, problem.Title)
+			assert.Equal(t, w.Header().Get("X-Request-ID"), problem.RequestID)
+		})
+	}
+}
+
+func TestLegacyNewsUnchanged(t *testing.T) {
+	router := setupV1Router(&reportingProvider{name: "alphavantage", status: news.CacheMemory,
+		articles: []models.Article{{Title: "Apple ships"}}})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/news/AAPL?fields=title", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"ticker": "AAPL", "news": [{"title": "Apple ships"}], "total": 1}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/news/aapl", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "Invalid ticker format."}`, w.Body.String())
+}
+
+func TestV1ResourceRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := (&Server{
+		MultiFetcher: news.NewMultiFetcher(&reportingProvider{name: "alphavantage", status: news.CacheMemory, fetchedAt: time.Now(),
+			articles: []models.Article{{Title: "Nvidia rallies", Tickers: []string{"NVDA"}}}}),
+		Watchlists: newWatchlistService(),
+	}).Router()
+
+	w := serveWatchlist(router, http.MethodPost, "/v1/watchlists", `{"name": "Semis", "tickers": ["NVDA"]}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var created struct {
+		Data watchlists.Watchlist `json:"data"`
+		Meta Meta                 `json:"meta"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.NotEmpty(t, created.Data.ID)
+	assert.Equal(t, "/v1/watchlists/"+created.Data.ID, w.Header().Get("Location"))
+	assert.Equal(t, w.Header().Get("X-Request-ID"), created.Meta.RequestID)
+	assert.Equal(t, []string{}, created.Meta.Providers)
+
+	w = serveWatchlist(router, http.MethodGet, "/v1/watchlists/"+created.Data.ID+"/news", "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var feed struct {
+		Data struct {
+			News []models.Article `json:"news"`
+		} `json:"data"`
+		Meta Meta `json:"meta"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &feed))
+	assert.Len(t, feed.Data.News, 1)
+	assert.Equal(t, news.CacheMemory, feed.Meta.Cache)
+	assert.Equal(t, []string{"alphavantage"}, feed.Meta.Providers)
+
+	w = serveWatchlist(router, http.MethodGet, "/v1/watchlists/unknown", "")
+	require.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+
+	w = serveWatchlist(router, http.MethodGet, "/v1/subscriptions", "")
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var problem Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, codeNotConfigured, problem.Code)
+
+	// The legacy route answers the same resource without the envelope.
+	w = serveWatchlist(router, http.MethodGet, "/watchlists/"+created.Data.ID, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), `"meta"`)
+}
+
+func TestV1AIErrorsHideDetails(t *testing.T) {
+	summarizer := new(MockSummarizer)
+	summarizer.On("Model").Return("test-model")
+	summarizer.On("Generate", mock.Anything, mock.Anything).Return("", errors.New("model said: secret prompt text"))
+
+	gin.SetMode(gin.TestMode)
+	router := (&Server{
+		MultiFetcher: news.NewMultiFetcher(&reportingProvider{name: "alphavantage",
+			articles: []models.Article{{Title: "Apple ships", Summary: "Apple ships phones"}}}),
+		Summaries: ai.NewSummaryService(summarizer, nil, ai.SummaryOptions{}),
+	}).Router()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/v1/news/AAPL?summarize=true", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotContains(t, w.Body.String(), "secret prompt text")
+
+	var problem Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, codeInternal, problem.Code)
+	assert.Equal(t, "Internal server error.", problem.Detail)
+}
+
+func TestAIBusyAnswersRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/v1/busy", func(c *gin.Context) {
+		writeAIError(c, fmt.Errorf("summarizing: %w", ai.ErrBusy))
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/v1/busy", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+
+	var problem Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, codeServiceUnavailable, problem.Code)
+}
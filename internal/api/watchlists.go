@@ -0,0 +1,397 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/apperrors"
+	"github.com/akhlexe/stocknews-api/internal/filter"
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/akhlexe/stocknews-api/internal/news"
+	"github.com/akhlexe/stocknews-api/internal/watchlists"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// watchlistFetchers bounds the tickers of a watchlist fetched at once, so a
+// large watchlist doesn't spend the provider's quota in one burst.
+const watchlistFetchers = 6
+
+type watchlistRequest struct {
+	Name    string   `json:"name"`
+	Tickers []string `json:"tickers"`
+}
+
+type watchlistTickersRequest struct {
+	Tickers []string `json:"tickers"`
+}
+
+// handleCreateWatchlist validates and stores a watchlist.
+func handleCreateWatchlist(c *gin.Context, service *watchlists.Service) {
+	var body watchlistRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.Warn().Err(err).Msg("Invalid watchlist request body")
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid request body.")
+		return
+	}
+
+	if !validWatchlistTickers(c, body.Tickers) {
+		return
+	}
+
+	if service == nil {
+		writeError(c, http.StatusServiceUnavailable, codeNotConfigured, "Watchlists are not configured.")
+		return
+	}
+
+	created, err := service.Create(c, requestKeyID(c), body.Name, body.Tickers)
+	if err != nil {
+		writeWatchlistError(c, err)
+		return
+	}
+
+	c.Header("Location", resourcePath(c, "/watchlists/"+created.ID))
+	respondStatus(c, http.StatusCreated, nil, created)
+}
+
+func handleListWatchlists(c *gin.Context, service *watchlists.Service) {
+	if service == nil {
+		writeError(c, http.StatusServiceUnavailable, codeNotConfigured, "Watchlists are not configured.")
+		return
+	}
+
+	keyID := requestKeyID(c)
+	lists := []watchlists.Watchlist{}
+	for _, w := range service.List() {
+		if w.KeyID == keyID {
+			lists = append(lists, w)
+		}
+	}
+
+	respond(c, nil, gin.H{"watchlists": lists})
+}
+
+func handleGetWatchlist(c *gin.Context, service *watchlists.Service) {
+	if service == nil {
+		writeError(c, http.StatusServiceUnavailable, codeNotConfigured, "Watchlists are not configured.")
+		return
+	}
+
+	w, ok := ownedWatchlist(c, service)
+	if !ok {
+		return
+	}
+
+	respond(c, nil, w)
+}
+
+// handleReplaceWatchlist overwrites a watchlist's name and tickers.
+func handleReplaceWatchlist(c *gin.Context, service *watchlists.Service) {
+	var body watchlistRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.Warn().Err(err).Msg("Invalid watchlist request body")
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid request body.")
+		return
+	}
+
+	if !validWatchlistTickers(c, body.Tickers) {
+		return
+	}
+
+	if service == nil {
+		writeError(c, http.StatusServiceUnavailable, codeNotConfigured, "Watchlists are not configured.")
+		return
+	}
+
+	if _, ok := ownedWatchlist(c, service); !ok {
+		return
+	}
+
+	updated, err := service.Replace(c, c.Param("id"), body.Name, body.Tickers)
+	if err != nil {
+		writeWatchlistError(c, err)
+		return
+	}
+
+	respond(c, nil, updated)
+}
+
+func handleDeleteWatchlist(c *gin.Context, service *watchlists.Service) {
+	if service == nil {
+		writeError(c, http.StatusServiceUnavailable, codeNotConfigured, "Watchlists are not configured.")
+		return
+	}
+
+	if _, ok := ownedWatchlist(c, service); !ok {
+		return
+	}
+
+	deleted, err := service.Delete(c, c.Param("id"))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to delete watchlist")
+		writeError(c, http.StatusInternalServerError, codeInternal, "Internal server error.")
+		return
+	}
+	if !deleted {
+		writeError(c, http.StatusNotFound, codeNotFound, "Watchlist not found.")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// handleAddWatchlistTickers adds tickers to a watchlist, ignoring those it
+// already has.
+func handleAddWatchlistTickers(c *gin.Context, service *watchlists.Service) {
+	var body watchlistTickersRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.Warn().Err(err).Msg("Invalid watchlist tickers request body")
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid request body.")
+		return
+	}
+
+	if len(body.Tickers) == 0 {
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid tickers parameter.")
+		return
+	}
+	if !validWatchlistTickers(c, body.Tickers) {
+		return
+	}
+
+	if service == nil {
+		writeError(c, http.StatusServiceUnavailable, codeNotConfigured, "Watchlists are not configured.")
+		return
+	}
+
+	if _, ok := ownedWatchlist(c, service); !ok {
+		return
+	}
+
+	updated, err := service.AddTickers(c, c.Param("id"), body.Tickers)
+	if err != nil {
+		writeWatchlistError(c, err)
+		return
+	}
+
+	respond(c, nil, updated)
+}
+
+func handleRemoveWatchlistTicker(c *gin.Context, service *watchlists.Service) {
+	ticker := c.Param("ticker")
+	if !validTickerRegex.MatchString(ticker) {
+		log.Warn().Str("ticker", ticker).Msg("Invalid ticker format")
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid ticker format.")
+		return
+	}
+
+	if service == nil {
+		writeError(c, http.StatusServiceUnavailable, codeNotConfigured, "Watchlists are not configured.")
+		return
+	}
+
+	if _, ok := ownedWatchlist(c, service); !ok {
+		return
+	}
+
+	updated, err := service.RemoveTicker(c, c.Param("id"), ticker)
+	if err != nil {
+		writeWatchlistError(c, err)
+		return
+	}
+
+	respond(c, nil, updated)
+}
+
+// handleWatchlistNews merges the news of every ticker on a watchlist, newest
+// first. An article tagged with several of the tickers is listed once.
+// Tickers without news are skipped; tickers whose fetch failed are listed
+// under "unavailable" unless every fetch failed.
+func handleWatchlistNews(c *gin.Context, fetcher news.Provider, service *watchlists.Service) {
+	limit, err := parseLimit(c.Query("limit"))
+	if err != nil {
+		log.Warn().Str("limit", c.Query("limit")).Msg("Invalid limit parameter")
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid limit parameter.")
+		return
+	}
+
+	fields := parseFields(c.Query("fields"))
+	if err := models.ValidateArticleFields(fields); err != nil {
+		log.Warn().Err(err).Msg("Invalid fields parameter")
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid fields parameter.")
+		return
+	}
+
+	var cursor *pageCursor
+	if cursorParam := c.Query("cursor"); cursorParam != "" {
+		decoded, err := decodeCursor(cursorParam)
+		if err != nil {
+			log.Warn().Msg("Invalid cursor parameter")
+			writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid cursor parameter.")
+			return
+		}
+		cursor = &decoded
+	}
+
+	if service == nil {
+		writeError(c, http.StatusServiceUnavailable, codeNotConfigured, "Watchlists are not configured.")
+		return
+	}
+
+	w, ok := ownedWatchlist(c, service)
+	if !ok {
+		return
+	}
+	requestLog := log.With().Str("watchlist_id", w.ID).Logger()
+
+	fetchCtx, report := news.WithFetchReport(c)
+	fetchCtx, cancelFetch := context.WithTimeout(fetchCtx, 10*time.Second)
+	defer cancelFetch()
+
+	articles, unavailable, err := fetchWatchlistNews(fetchCtx, fetcher, w.Tickers)
+	if err != nil {
+		requestLog.Error().Err(err).Msg("Error processing watchlist news request")
+		writeFetchError(c, err)
+		return
+	}
+
+	etag := articlesETag(c, articles, strings.Join(w.Tickers, ","), strings.Join(unavailable, ","))
+	if notModified(c, report, etag) {
+		requestLog.Debug().Msg("Watchlist news not modified")
+		return
+	}
+
+	filter.SortArticles(articles, filter.SortNewest)
+
+	total := len(articles)
+	page, nextCursor := paginate(articles, cursor, limit)
+
+	response := gin.H{
+		"watchlist":   w.ID,
+		"tickers":     w.Tickers,
+		"news":        page,
+		"total":       total,
+		"unavailable": unavailable,
+	}
+	if nextCursor != "" {
+		response["next_cursor"] = nextCursor
+	}
+
+	if len(fields) > 0 {
+		projected, err := models.ProjectArticles(page, fields)
+		if err != nil {
+			requestLog.Error().Err(err).Msg("Failed to project article fields")
+			writeError(c, http.StatusInternalServerError, codeInternal, "Internal server error.")
+			return
+		}
+		response["news"] = projected
+	}
+
+	requestLog.Info().Int("article_count", len(page)).Int("total", total).Strs("unavailable", unavailable).Msg("Successfully retrieved watchlist news")
+	respond(c, report, response)
+}
+
+// fetchWatchlistNews fetches the tickers, watchlistFetchers at a time, and
+// merges the articles, dropping repeats. It returns the tickers that failed,
+// or the first error when all of them did.
+func fetchWatchlistNews(ctx context.Context, fetcher news.Provider, tickers []string) ([]models.Article, []string, error) {
+	type result struct {
+		articles []models.Article
+		err      error
+	}
+
+	results := make([]result, len(tickers))
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, watchlistFetchers)
+	for i, ticker := range tickers {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(i int, ticker string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			articles, err := fetcher.GetNewsByTicker(ctx, ticker)
+			results[i] = result{articles: articles, err: err}
+		}(i, ticker)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	articles := []models.Article{}
+	unavailable := []string{}
+	var firstErr error
+	for i, r := range results {
+		if errors.Is(r.err, apperrors.ErrNotFound) {
+			continue
+		}
+		if r.err != nil {
+			log.Warn().Err(r.err).Str("ticker", tickers[i]).Msg("Failed to fetch watchlist ticker")
+			unavailable = append(unavailable, tickers[i])
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			continue
+		}
+		for _, a := range r.articles {
+			if id := a.ID(); !seen[id] {
+				seen[id] = true
+				articles = append(articles, a)
+			}
+		}
+	}
+
+	if len(unavailable) > 0 && len(unavailable) == len(tickers) {
+		return nil, nil, firstErr
+	}
+	return articles, unavailable, nil
+}
+
+// ownedWatchlist returns the watchlist of the id parameter if it belongs to the
+// request's key, and writes the error response when it doesn't.
+func ownedWatchlist(c *gin.Context, service *watchlists.Service) (watchlists.Watchlist, bool) {
+	w, ok, err := service.Get(c, c.Param("id"))
+	if err != nil {
+		log.Error().Err(err).Str("watchlist_id", c.Param("id")).Msg("Failed to get watchlist")
+		writeError(c, http.StatusInternalServerError, codeInternal, "Internal server error.")
+		return watchlists.Watchlist{}, false
+	}
+	if !ok || w.KeyID != requestKeyID(c) {
+		writeError(c, http.StatusNotFound, codeNotFound, "Watchlist not found.")
+		return watchlists.Watchlist{}, false
+	}
+	return w, true
+}
+
+// validWatchlistTickers checks the tickers of a watchlist request and writes
+// the error response when one is invalid.
+func validWatchlistTickers(c *gin.Context, tickers []string) bool {
+	if len(tickers) > watchlists.MaxTickers {
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid tickers parameter.")
+		return false
+	}
+	for _, ticker := range tickers {
+		if !validTickerRegex.MatchString(ticker) {
+			log.Warn().Str("ticker", ticker).Msg("Invalid ticker format")
+			writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid ticker format.")
+			return false
+		}
+	}
+	return true
+}
+
+// writeWatchlistError maps a watchlist service error onto an HTTP error
+// response.
+func writeWatchlistError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, watchlists.ErrNotFound):
+		writeError(c, http.StatusNotFound, codeNotFound, "Watchlist not found.")
+	case errors.Is(err, watchlists.ErrInvalidWatchlist):
+		log.Warn().Err(err).Msg("Invalid watchlist")
+		writeError(c, http.StatusBadRequest, codeInvalidParameter, "Invalid watchlist.")
+	default:
+		log.Error().Err(err).Msg("Failed to save watchlist")
+		writeError(c, http.StatusInternalServerError, codeInternal, "Internal server error.")
+	}
+}
@@ -0,0 +1,265 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/apperrors"
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/akhlexe/stocknews-api/internal/news"
+	"github.com/akhlexe/stocknews-api/internal/stream"
+	"github.com/akhlexe/stocknews-api/internal/watchlists"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// watchlistStore keeps watchlists in memory.
+type watchlistStore struct {
+	mu    sync.Mutex
+	lists map[string][]byte
+}
+
+func (s *watchlistStore) SaveWatchlist(ctx context.Context, id string, watchlist []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lists[id] = watchlist
+	return nil
+}
+
+func (s *watchlistStore) GetWatchlists(ctx context.Context) ([][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var lists [][]byte
+	for _, data := range s.lists {
+		lists = append(lists, data)
+	}
+	return lists, nil
+}
+
+func (s *watchlistStore) GetWatchlist(ctx context.Context, id string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.lists[id]
+	return data, ok, nil
+}
+
+func (s *watchlistStore) DeleteWatchlist(ctx context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.lists[id]
+	delete(s.lists, id)
+	return ok, nil
+}
+
+func setupWatchlistRouter(fetcher news.Provider, service *watchlists.Service) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/watchlists", func(c *gin.Context) { handleCreateWatchlist(c, service) })
+	router.GET("/watchlists", func(c *gin.Context) { handleListWatchlists(c, service) })
+	router.GET("/watchlists/:id", func(c *gin.Context) { handleGetWatchlist(c, service) })
+	router.PUT("/watchlists/:id", func(c *gin.Context) { handleReplaceWatchlist(c, service) })
+	router.DELETE("/watchlists/:id", func(c *gin.Context) { handleDeleteWatchlist(c, service) })
+	router.POST("/watchlists/:id/tickers", func(c *gin.Context) { handleAddWatchlistTickers(c, service) })
+	router.DELETE("/watchlists/:id/tickers/:ticker", func(c *gin.Context) { handleRemoveWatchlistTicker(c, service) })
+	router.GET("/watchlists/:id/news", func(c *gin.Context) { handleWatchlistNews(c, fetcher, service) })
+	return router
+}
+
+func newWatchlistService() *watchlists.Service {
+	store := &watchlistStore{lists: make(map[string][]byte)}
+	return watchlists.NewService(store, stream.NewHub(stream.Options{}))
+}
+
+func serveWatchlist(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestWatchlistLifecycle(t *testing.T) {
+	router := setupWatchlistRouter(new(MockNewsProvider), newWatchlistService())
+
+	w := serveWatchlist(router, http.MethodPost, "/watchlists", `{"name": "Semis", "tickers": ["NVDA", "AMD"]}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created watchlists.Watchlist
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, "/watchlists/"+created.ID, w.Header().Get("Location"))
+
+	w = serveWatchlist(router, http.MethodPost, "/watchlists/"+created.ID+"/tickers", `{"tickers": ["TSM", "AMD"]}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"tickers":["NVDA","AMD","TSM"]`)
+
+	w = serveWatchlist(router, http.MethodDelete, "/watchlists/"+created.ID+"/tickers/AMD", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"tickers":["NVDA","TSM"]`)
+
+	w = serveWatchlist(router, http.MethodPut, "/watchlists/"+created.ID, `{"name": "Foundries", "tickers": ["TSM"]}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"Foundries"`)
+
+	w = serveWatchlist(router, http.MethodGet, "/watchlists", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), created.ID)
+
+	w = serveWatchlist(router, http.MethodDelete, "/watchlists/"+created.ID, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		w = serveWatchlist(router, method, "/watchlists/"+created.ID, "")
+		assert.Equal(t, http.StatusNotFound, w.Code, method)
+	}
+	w = serveWatchlist(router, http.MethodPost, "/watchlists/"+created.ID+"/tickers", `{"tickers": ["TSM"]}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestWatchlistValidation(t *testing.T) {
+	router := setupWatchlistRouter(new(MockNewsProvider), newWatchlistService())
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		error  string
+	}{
+		{"invalid body", http.MethodPost, "/watchlists", `{`, "Invalid request body."},
+		{"invalid ticker", http.MethodPost, "/watchlists", `{"name": "Tech", "tickers": ["aapl"]}`, "Invalid ticker format."},
+		{"no name", http.MethodPost, "/watchlists", `{"tickers": ["AAPL"]}`, "Invalid watchlist."},
+		{"no tickers to add", http.MethodPost, "/watchlists/x/tickers", `{"tickers": []}`, "Invalid tickers parameter."},
+		{"invalid ticker to remove", http.MethodDelete, "/watchlists/x/tickers/aapl", "", "Invalid ticker format."},
+		{"invalid limit", http.MethodGet, "/watchlists/x/news?limit=0", "", "Invalid limit parameter."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveWatchlist(router, tt.method, tt.path, tt.body)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, `{"error": "`+tt.error+`"}`, w.Body.String())
+		})
+	}
+}
+
+func TestWatchlistNews(t *testing.T) {
+	service := newWatchlistService()
+	created, err := service.Create(context.Background(), "", "Mixed", []string{"AAPL", "MSFT", "XYZ", "TSLA"})
+	require.NoError(t, err)
+
+	shared := models.Article{Title: "Apple and Microsoft team up", URL: "https://example.com/shared", PublishedAt: "20250102T090000"}
+	fetcher := new(MockNewsProvider)
+	fetcher.On("GetNewsByTicker", mock.Anything, "AAPL").Return([]models.Article{
+		{Title: "Apple earnings", URL: "https://example.com/aapl", PublishedAt: "20250101T120000"},
+		shared,
+	}, nil)
+	fetcher.On("GetNewsByTicker", mock.Anything, "MSFT").Return([]models.Article{
+		shared,
+		{Title: "Microsoft cloud", URL: "https://example.com/msft", PublishedAt: "20250103T080000"},
+	}, nil)
+	fetcher.On("GetNewsByTicker", mock.Anything, "XYZ").Return(nil, apperrors.ErrNotFound)
+	fetcher.On("GetNewsByTicker", mock.Anything, "TSLA").Return(nil, apperrors.ErrServiceUnavailable)
+
+	router := setupWatchlistRouter(fetcher, service)
+	w := serveWatchlist(router, http.MethodGet, "/watchlists/"+created.ID+"/news?fields=title", "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"watchlist": "`+created.ID+`",
+		"tickers": ["AAPL", "MSFT", "XYZ", "TSLA"],
+		"news": [{"title": "Microsoft cloud"}, {"title": "Apple and Microsoft team up"}, {"title": "Apple earnings"}],
+		"total": 3,
+		"unavailable": ["TSLA"]
+	}`, w.Body.String())
+}
+
+func TestWatchlistNewsBoundsConcurrentFetches(t *testing.T) {
+	var mu sync.Mutex
+	running, peak := 0, 0
+	fetcher := providerFunc(func(ctx context.Context, ticker string) ([]models.Article, error) {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return []models.Article{{Title: ticker, URL: "https://example.com/" + ticker}}, nil
+	})
+
+	tickers := make([]string, watchlists.MaxTickers)
+	for i := range tickers {
+		tickers[i] = fmt.Sprintf("T%d", i)
+	}
+	articles, unavailable, err := fetchWatchlistNews(context.Background(), fetcher, tickers)
+	require.NoError(t, err)
+	assert.Len(t, articles, len(tickers))
+	assert.Empty(t, unavailable)
+	assert.LessOrEqual(t, peak, watchlistFetchers)
+}
+
+func TestWatchlistNewsETagCoversTickers(t *testing.T) {
+	service := newWatchlistService()
+	created, err := service.Create(context.Background(), "", "Chips", []string{"NVDA"})
+	require.NoError(t, err)
+
+	fetcher := new(MockNewsProvider)
+	fetcher.On("GetNewsByTicker", mock.Anything, "NVDA").Return([]models.Article{{Title: "Nvidia ships", URL: "https://example.com/nvda"}}, nil)
+	fetcher.On("GetNewsByTicker", mock.Anything, "AMD").Return(nil, apperrors.ErrNotFound)
+
+	router := setupWatchlistRouter(fetcher, service)
+	path := "/watchlists/" + created.ID + "/news"
+	w := serveWatchlist(router, http.MethodGet, path, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	// The articles stay the same, but the listed tickers don't.
+	_, err = service.AddTickers(context.Background(), created.ID, []string{"AMD"})
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestWatchlistNewsFailsWhenEveryFetchFails(t *testing.T) {
+	service := newWatchlistService()
+	created, err := service.Create(context.Background(), "", "Down", []string{"AAPL"})
+	require.NoError(t, err)
+
+	fetcher := new(MockNewsProvider)
+	fetcher.On("GetNewsByTicker", mock.Anything, "AAPL").Return(nil, apperrors.ErrServiceUnavailable)
+
+	router := setupWatchlistRouter(fetcher, service)
+	w := serveWatchlist(router, http.MethodGet, "/watchlists/"+created.ID+"/news", "")
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.JSONEq(t, `{"error": "External service unavailable."}`, w.Body.String())
+}
+
+func TestWatchlistsNotConfigured(t *testing.T) {
+	router := setupWatchlistRouter(new(MockNewsProvider), nil)
+
+	w := serveWatchlist(router, http.MethodGet, "/watchlists", "")
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.JSONEq(t, `{"error": "Watchlists are not configured."}`, w.Body.String())
+}
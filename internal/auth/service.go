@@ -0,0 +1,376 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/storage"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultCacheTTL is how long a looked-up key is trusted before it is
+	// read again, and so how long a key revoked elsewhere keeps working.
+	DefaultCacheTTL = time.Minute
+
+	// DefaultFlushInterval is how often usage counters are written out.
+	DefaultFlushInterval = 30 * time.Second
+
+	dayLayout = "2006-01-02"
+)
+
+// Store persists API keys and their usage.
+type Store interface {
+	SaveAPIKey(ctx context.Context, key storage.APIKey) error
+	GetAPIKeyByHash(ctx context.Context, hash string) (storage.APIKey, bool, error)
+	GetAPIKeys(ctx context.Context) ([]storage.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string, at time.Time) (bool, error)
+	AddAPIKeyUsage(ctx context.Context, usage []storage.APIKeyUsage) error
+	GetAPIKeyUsage(ctx context.Context, keyID string, since time.Time) ([]storage.APIKeyUsage, error)
+}
+
+// Options tunes the service. Zero values use the defaults.
+type Options struct {
+	CacheTTL      time.Duration
+	FlushInterval time.Duration
+}
+
+type cachedKey struct {
+	key     Key
+	fetched time.Time
+}
+
+// bucket is a token bucket holding up to a minute's worth of requests.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type scopeKey struct {
+	keyID string
+	scope string
+}
+
+type usageKey struct {
+	keyID    string
+	day      string
+	scope    string
+	endpoint string
+}
+
+// Service authenticates API keys and meters their requests. Rate limits and
+// daily quotas are enforced per process; daily counts start from the stored
+// usage, so they survive restarts.
+type Service struct {
+	store Store
+	opts  Options
+
+	mu      sync.Mutex
+	keys    map[string]cachedKey
+	buckets map[scopeKey]*bucket
+	day     string
+	daily   map[scopeKey]int
+	pending map[usageKey]int64
+}
+
+func NewService(store Store, opts Options) *Service {
+	if opts.CacheTTL <= 0 {
+		opts.CacheTTL = DefaultCacheTTL
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = DefaultFlushInterval
+	}
+
+	return &Service{
+		store:   store,
+		opts:    opts,
+		keys:    make(map[string]cachedKey),
+		buckets: make(map[scopeKey]*bucket),
+		daily:   make(map[scopeKey]int),
+		pending: make(map[usageKey]int64),
+	}
+}
+
+// Create stores a new key and returns it with its secret, which is not kept
+// and can't be shown again.
+func (s *Service) Create(ctx context.Context, name string, scopes map[string]Limit) (Key, string, error) {
+	if err := validateScopes(scopes); err != nil {
+		return Key{}, "", err
+	}
+
+	id, err := randomID()
+	if err != nil {
+		return Key{}, "", err
+	}
+	secret, err := newSecret()
+	if err != nil {
+		return Key{}, "", err
+	}
+
+	key := Key{ID: id, Name: name, Scopes: scopes, CreatedAt: time.Now().UTC()}
+	data, err := json.Marshal(key)
+	if err != nil {
+		return Key{}, "", err
+	}
+
+	stored := storage.APIKey{ID: key.ID, Hash: hashSecret(secret), Data: data, CreatedAt: key.CreatedAt}
+	if err := s.store.SaveAPIKey(ctx, stored); err != nil {
+		return Key{}, "", err
+	}
+
+	log.Info().Str("key_id", key.ID).Strs("scopes", key.ScopeNames()).Msg("API key created")
+	return key, secret, nil
+}
+
+// Revoke disables a key. Other processes notice within the cache TTL.
+func (s *Service) Revoke(ctx context.Context, id string) (bool, error) {
+	revoked, err := s.store.RevokeAPIKey(ctx, id, time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	for hash, cached := range s.keys {
+		if cached.key.ID == id {
+			delete(s.keys, hash)
+		}
+	}
+	s.mu.Unlock()
+
+	if revoked {
+		log.Info().Str("key_id", id).Msg("API key revoked")
+	}
+	return revoked, nil
+}
+
+// List returns every key, revoked ones included, oldest first.
+func (s *Service) List(ctx context.Context) ([]Key, error) {
+	stored, err := s.store.GetAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]Key, 0, len(stored))
+	for _, k := range stored {
+		key, err := decodeKey(k)
+		if err != nil {
+			log.Warn().Err(err).Str("key_id", k.ID).Msg("Skipping stored API key")
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Usage returns a key's request counts by day, scope and endpoint from the
+// day of since on, including counts not written out yet.
+func (s *Service) Usage(ctx context.Context, keyID string, since time.Time) ([]storage.APIKeyUsage, error) {
+	if err := s.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return s.store.GetAPIKeyUsage(ctx, keyID, startOfDay(since))
+}
+
+// Authenticate returns the active key with the given secret.
+func (s *Service) Authenticate(ctx context.Context, secret string) (Key, error) {
+	hash := hashSecret(secret)
+	now := time.Now()
+
+	s.mu.Lock()
+	cached, ok := s.keys[hash]
+	s.mu.Unlock()
+
+	if !ok || now.Sub(cached.fetched) > s.opts.CacheTTL {
+		// Unknown secrets aren't cached, so guessing can't grow the cache.
+		stored, found, err := s.store.GetAPIKeyByHash(ctx, hash)
+		if err != nil {
+			return Key{}, err
+		}
+		if !found {
+			s.mu.Lock()
+			delete(s.keys, hash)
+			s.mu.Unlock()
+			return Key{}, ErrInvalidKey
+		}
+
+		cached = cachedKey{fetched: now}
+		if cached.key, err = decodeKey(stored); err != nil {
+			return Key{}, err
+		}
+
+		s.mu.Lock()
+		s.keys[hash] = cached
+		s.mu.Unlock()
+	}
+
+	if cached.key.Revoked() {
+		return Key{}, ErrInvalidKey
+	}
+	return cached.key, nil
+}
+
+// prune drops keys looked up more than the cache TTL before now.
+func (s *Service) prune(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for hash, cached := range s.keys {
+		if now.Sub(cached.fetched) > s.opts.CacheTTL {
+			delete(s.keys, hash)
+		}
+	}
+}
+
+// Allow meters a request of key in scope to endpoint. It returns
+// ErrScopeDenied, or ErrRateLimited or ErrQuotaExceeded with how long to wait
+// before retrying. Allowed requests are counted towards the key's usage.
+func (s *Service) Allow(ctx context.Context, key Key, scope, endpoint string) (time.Duration, error) {
+	return s.allow(ctx, key, scope, endpoint, time.Now().UTC())
+}
+
+func (s *Service) allow(ctx context.Context, key Key, scope, endpoint string, now time.Time) (time.Duration, error) {
+	limit, ok := key.Scopes[scope]
+	if !ok {
+		return 0, ErrScopeDenied
+	}
+
+	sk := scopeKey{keyID: key.ID, scope: scope}
+	day := now.Format(dayLayout)
+
+	if limit.Daily > 0 {
+		if err := s.seedDaily(ctx, sk, now); err != nil {
+			return 0, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if limit.Daily > 0 && s.daily[sk] >= limit.Daily {
+		return startOfDay(now).Add(24 * time.Hour).Sub(now), ErrQuotaExceeded
+	}
+
+	if limit.PerMinute > 0 {
+		b, ok := s.buckets[sk]
+		if !ok {
+			b = &bucket{tokens: float64(limit.PerMinute), last: now}
+			s.buckets[sk] = b
+		}
+		perSecond := float64(limit.PerMinute) / 60
+		b.tokens = math.Min(float64(limit.PerMinute), b.tokens+now.Sub(b.last).Seconds()*perSecond)
+		b.last = now
+		if b.tokens < 1 {
+			wait := time.Duration((1 - b.tokens) / perSecond * float64(time.Second))
+			return wait, ErrRateLimited
+		}
+		b.tokens--
+	}
+
+	if limit.Daily > 0 && s.day == day {
+		s.daily[sk]++
+	}
+	s.pending[usageKey{keyID: key.ID, day: day, scope: scope, endpoint: endpoint}]++
+	return 0, nil
+}
+
+// seedDaily starts a key's count for the day of now from its stored usage,
+// unless it is already counted. Counts of earlier days are dropped.
+func (s *Service) seedDaily(ctx context.Context, sk scopeKey, now time.Time) error {
+	day := now.Format(dayLayout)
+
+	s.mu.Lock()
+	if s.day != day {
+		s.day = day
+		s.daily = make(map[scopeKey]int)
+	}
+	_, seeded := s.daily[sk]
+	s.mu.Unlock()
+	if seeded {
+		return nil
+	}
+
+	usage, err := s.store.GetAPIKeyUsage(ctx, sk.keyID, startOfDay(now))
+	if err != nil {
+		return err
+	}
+	count := 0
+	for _, u := range usage {
+		if u.Scope == sk.scope && u.Day.Format(dayLayout) == day {
+			count += int(u.Requests)
+		}
+	}
+
+	s.mu.Lock()
+	if _, seeded := s.daily[sk]; !seeded && s.day == day {
+		s.daily[sk] = count
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// Run writes out usage counters and drops expired cached keys every flush
+// interval until ctx is done.
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.Flush(context.Background()); err != nil {
+				log.Error().Err(err).Msg("Failed to write API key usage")
+			}
+			return
+		case <-ticker.C:
+			if err := s.Flush(ctx); err != nil {
+				log.Error().Err(err).Msg("Failed to write API key usage")
+			}
+			s.prune(time.Now())
+		}
+	}
+}
+
+// Flush writes out the usage counted since the last flush. Counts that fail
+// to be written are kept for the next one.
+func (s *Service) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[usageKey]int64)
+	s.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	usage := make([]storage.APIKeyUsage, 0, len(pending))
+	for k, requests := range pending {
+		day, _ := time.Parse(dayLayout, k.day)
+		usage = append(usage, storage.APIKeyUsage{KeyID: k.keyID, Day: day, Scope: k.scope, Endpoint: k.endpoint, Requests: requests})
+	}
+
+	if err := s.store.AddAPIKeyUsage(ctx, usage); err != nil {
+		s.mu.Lock()
+		for k, requests := range pending {
+			s.pending[k] += requests
+		}
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func decodeKey(stored storage.APIKey) (Key, error) {
+	var key Key
+	if err := json.Unmarshal(stored.Data, &key); err != nil {
+		return Key{}, err
+	}
+	key.RevokedAt = stored.RevokedAt
+	return key, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,229 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	mu    sync.Mutex
+	keys  map[string]storage.APIKey
+	usage []storage.APIKeyUsage
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{keys: make(map[string]storage.APIKey)}
+}
+
+func (s *memoryStore) SaveAPIKey(ctx context.Context, key storage.APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[key.ID] = key
+	return nil
+}
+
+func (s *memoryStore) GetAPIKeyByHash(ctx context.Context, hash string) (storage.APIKey, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range s.keys {
+		if key.Hash == hash {
+			return key, true, nil
+		}
+	}
+	return storage.APIKey{}, false, nil
+}
+
+func (s *memoryStore) GetAPIKeys(ctx context.Context) ([]storage.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []storage.APIKey
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (s *memoryStore) RevokeAPIKey(ctx context.Context, id string, at time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := s.keys[id]
+	if !ok || key.RevokedAt != nil {
+		return false, nil
+	}
+	key.RevokedAt = &at
+	s.keys[id] = key
+	return true, nil
+}
+
+func (s *memoryStore) AddAPIKeyUsage(ctx context.Context, usage []storage.APIKeyUsage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.usage = append(s.usage, usage...)
+	return nil
+}
+
+func (s *memoryStore) GetAPIKeyUsage(ctx context.Context, keyID string, since time.Time) ([]storage.APIKeyUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var usage []storage.APIKeyUsage
+	for _, u := range s.usage {
+		if u.KeyID == keyID && !u.Day.Before(since) {
+			usage = append(usage, u)
+		}
+	}
+	return usage, nil
+}
+
+func TestServiceAuthenticatesAndRevokes(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	service := NewService(store, Options{})
+
+	key, secret, err := service.Create(ctx, "desk", map[string]Limit{ScopeNews: {}})
+	require.NoError(t, err)
+	assert.Contains(t, secret, secretPrefix)
+	assert.NotContains(t, string(store.keys[key.ID].Data), secret, "the secret must not be stored")
+	assert.Equal(t, hashSecret(secret), store.keys[key.ID].Hash)
+
+	got, err := service.Authenticate(ctx, secret)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, got.ID)
+
+	_, err = service.Authenticate(ctx, secret+"x")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	revoked, err := service.Revoke(ctx, key.ID)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	_, err = service.Authenticate(ctx, secret)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	keys, err := service.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.True(t, keys[0].Revoked())
+}
+
+func TestServiceCachesOnlyKnownKeys(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newMemoryStore(), Options{CacheTTL: time.Minute})
+
+	_, secret, err := service.Create(ctx, "desk", map[string]Limit{ScopeNews: {}})
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		_, err := service.Authenticate(ctx, secret+string(rune('a'+i)))
+		assert.ErrorIs(t, err, ErrInvalidKey)
+	}
+	_, err = service.Authenticate(ctx, secret)
+	require.NoError(t, err)
+	assert.Len(t, service.keys, 1, "unknown secrets are not cached")
+
+	service.prune(time.Now().Add(2 * time.Minute))
+	assert.Empty(t, service.keys, "expired keys are pruned")
+}
+
+func TestServiceRejectsInvalidScopes(t *testing.T) {
+	service := NewService(newMemoryStore(), Options{})
+
+	_, _, err := service.Create(context.Background(), "desk", nil)
+	assert.ErrorIs(t, err, ErrInvalidLimits)
+
+	_, _, err = service.Create(context.Background(), "desk", map[string]Limit{"admin": {}})
+	assert.ErrorIs(t, err, ErrInvalidLimits)
+
+	_, _, err = service.Create(context.Background(), "desk", map[string]Limit{ScopeNews: {PerMinute: -1}})
+	assert.ErrorIs(t, err, ErrInvalidLimits)
+}
+
+func TestParseScopes(t *testing.T) {
+	scopes, err := ParseScopes(" news, Summarize ")
+	require.NoError(t, err)
+	assert.Equal(t, []string{ScopeNews, ScopeSummarize}, scopes)
+
+	_, err = ParseScopes("news,admin")
+	assert.ErrorIs(t, err, ErrInvalidLimits)
+
+	_, err = ParseScopes("")
+	assert.ErrorIs(t, err, ErrInvalidLimits)
+}
+
+func TestServiceAllowChecksScope(t *testing.T) {
+	service := NewService(newMemoryStore(), Options{})
+	key := Key{ID: "k1", Scopes: map[string]Limit{ScopeNews: {}}}
+
+	_, err := service.allow(context.Background(), key, ScopeSummarize, "POST /summaries", time.Now())
+	assert.ErrorIs(t, err, ErrScopeDenied)
+}
+
+func TestServiceAllowRateLimitsPerScope(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newMemoryStore(), Options{})
+	key := Key{ID: "k1", Scopes: map[string]Limit{ScopeNews: {PerMinute: 60}, ScopeSummarize: {PerMinute: 2}}}
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 2; i++ {
+		_, err := service.allow(ctx, key, ScopeSummarize, "POST /summaries", now)
+		require.NoError(t, err)
+	}
+	wait, err := service.allow(ctx, key, ScopeSummarize, "POST /summaries", now)
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.Equal(t, 30*time.Second, wait)
+
+	_, err = service.allow(ctx, key, ScopeNews, "GET /news/:ticker", now)
+	assert.NoError(t, err, "scopes have separate buckets")
+
+	_, err = service.allow(ctx, key, ScopeSummarize, "POST /summaries", now.Add(30*time.Second))
+	assert.NoError(t, err, "the bucket refills over time")
+}
+
+func TestServiceAllowEnforcesDailyQuota(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	now := time.Date(2025, 1, 2, 18, 0, 0, 0, time.UTC)
+	store.usage = []storage.APIKeyUsage{
+		{KeyID: "k1", Day: startOfDay(now), Scope: ScopeSummarize, Endpoint: "POST /summaries", Requests: 2},
+		{KeyID: "k1", Day: startOfDay(now).AddDate(0, 0, -1), Scope: ScopeSummarize, Endpoint: "POST /summaries", Requests: 5},
+	}
+	service := NewService(store, Options{})
+	key := Key{ID: "k1", Scopes: map[string]Limit{ScopeSummarize: {Daily: 3}}}
+
+	_, err := service.allow(ctx, key, ScopeSummarize, "POST /summaries", now)
+	require.NoError(t, err)
+
+	wait, err := service.allow(ctx, key, ScopeSummarize, "POST /summaries", now)
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+	assert.Equal(t, 6*time.Hour, wait, "quotas reset at UTC midnight")
+
+	_, err = service.allow(ctx, key, ScopeSummarize, "POST /summaries", now.Add(6*time.Hour))
+	assert.NoError(t, err)
+}
+
+func TestServiceFlushesUsageByEndpoint(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	service := NewService(store, Options{})
+	key := Key{ID: "k1", Scopes: map[string]Limit{ScopeNews: {}}}
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	for _, endpoint := range []string{"GET /news/:ticker", "GET /news/:ticker", "GET /stream"} {
+		_, err := service.allow(ctx, key, ScopeNews, endpoint, now)
+		require.NoError(t, err)
+	}
+	require.NoError(t, service.Flush(ctx))
+	require.NoError(t, service.Flush(ctx))
+
+	counts := make(map[string]int64)
+	for _, u := range store.usage {
+		assert.Equal(t, startOfDay(now), u.Day)
+		counts[u.Endpoint] += u.Requests
+	}
+	assert.Equal(t, map[string]int64{"GET /news/:ticker": 2, "GET /stream": 1}, counts)
+}
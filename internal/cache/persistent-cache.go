@@ -10,11 +10,37 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// ArticleSource says which tier of the cache served articles.
+type ArticleSource string
+
+const (
+	SourceMemory  ArticleSource = "memory"
+	SourceStorage ArticleSource = "storage"
+)
+
+// CachedArticles is a cache hit.
+type CachedArticles struct {
+	Articles []models.Article
+	Source   ArticleSource
+
+	// FetchedAt is when the articles were fetched from upstream.
+	FetchedAt time.Time
+
+	// Expiration is when the articles expire, which may have passed for
+	// StaleArticles.
+	Expiration time.Time
+}
+
 type PersistentCache struct {
-	storage         storage.Storage
-	memory          map[string]CacheItem
-	mu              sync.RWMutex
-	ttl             time.Duration
+	storage storage.Storage
+	memory  map[string]CacheItem
+	mu      sync.RWMutex
+	ttl     time.Duration
+
+	// staleFor is how long expired articles stay in memory to be served by
+	// StaleArticles.
+	staleFor time.Duration
+
 	cleanupInterval time.Duration
 	stopCleanup     chan struct{}
 }
@@ -24,6 +50,7 @@ func NewPersistentCache(storage storage.Storage, ttl time.Duration) *PersistentC
 		storage:         storage,
 		memory:          make(map[string]CacheItem),
 		ttl:             ttl,
+		staleFor:        ttl,
 		cleanupInterval: ttl / 2,
 		stopCleanup:     make(chan struct{}),
 	}
@@ -51,16 +78,15 @@ func (c *PersistentCache) cleanup() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	// Clean up memory cache
-	c.mu.RLock()
+	// Clean up memory cache, keeping expired articles while they can be
+	// served stale.
+	c.mu.Lock()
 	now := time.Now()
 	for k, v := range c.memory {
-		if now.After(v.Expiration) {
+		if now.After(v.Expiration.Add(c.staleFor)) {
 			delete(c.memory, k)
 		}
 	}
-
-	c.mu.RUnlock()
 	c.mu.Unlock()
 
 	if err := c.storage.DeleteExpired(ctx); err != nil {
@@ -77,6 +103,12 @@ func (c *PersistentCache) Clear() {
 }
 
 func (c *PersistentCache) GetArticles(ctx context.Context, ticker string) ([]models.Article, bool) {
+	cached, ok := c.LookupArticles(ctx, ticker)
+	return cached.Articles, ok
+}
+
+// LookupArticles is GetArticles, also reporting where the articles came from.
+func (c *PersistentCache) LookupArticles(ctx context.Context, ticker string) (CachedArticles, bool) {
 	cacheKey := "news_" + ticker
 
 	// Try memory cache first (Fast path)
@@ -88,7 +120,7 @@ func (c *PersistentCache) GetArticles(ctx context.Context, ticker string) ([]mod
 		articles, ok := item.Value.([]models.Article)
 		if ok {
 			log.Debug().Str("ticker", ticker).Msg("Cache hit (memory)")
-			return articles, true
+			return CachedArticles{Articles: articles, Source: SourceMemory, FetchedAt: item.Expiration.Add(-c.ttl), Expiration: item.Expiration}, true
 		}
 	}
 
@@ -96,7 +128,7 @@ func (c *PersistentCache) GetArticles(ctx context.Context, ticker string) ([]mod
 	articlesData, expiration, found, err := c.storage.GetArticles(ctx, ticker)
 	if err != nil {
 		log.Error().Err(err).Str("ticker", ticker).Msg("Error retrieving articles from storage")
-		return nil, false
+		return CachedArticles{}, false
 	}
 
 	if found {
@@ -104,7 +136,7 @@ func (c *PersistentCache) GetArticles(ctx context.Context, ticker string) ([]mod
 		articles, err = models.UnmarshalArticles(articlesData)
 		if err != nil {
 			log.Error().Err(err).Str("ticker", ticker).Msg("Error unmarshalling articles from storage")
-			return nil, false
+			return CachedArticles{}, false
 		}
 
 		c.mu.Lock()
@@ -115,11 +147,31 @@ func (c *PersistentCache) GetArticles(ctx context.Context, ticker string) ([]mod
 		c.mu.Unlock()
 
 		log.Debug().Str("ticker", ticker).Msg("Cache hit (storage)")
-		return articles, true
+		return CachedArticles{Articles: articles, Source: SourceStorage, FetchedAt: expiration.Add(-c.ttl), Expiration: expiration}, true
 	}
 
 	log.Debug().Str("ticker", ticker).Msg("Cache miss: not found in memory or storage")
-	return nil, false
+	return CachedArticles{}, false
+}
+
+// StaleArticles returns articles held in memory even if they have expired, as
+// a fallback when they can't be refreshed.
+func (c *PersistentCache) StaleArticles(ticker string) (CachedArticles, bool) {
+	c.mu.RLock()
+	item, found := c.memory["news_"+ticker]
+	c.mu.RUnlock()
+
+	articles, ok := item.Value.([]models.Article)
+	if !found || !ok {
+		return CachedArticles{}, false
+	}
+
+	return CachedArticles{Articles: articles, Source: SourceMemory, FetchedAt: item.Expiration.Add(-c.ttl), Expiration: item.Expiration}, true
+}
+
+// TTL is how long articles are cached.
+func (c *PersistentCache) TTL() time.Duration {
+	return c.ttl
 }
 
 func (c *PersistentCache) SetArticles(ctx context.Context, ticker string, articles []models.Article) {
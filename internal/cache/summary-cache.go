@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// SummaryCache keeps AI summaries in memory and in persistent storage, keyed
+// by the fingerprint of the article set they were generated from.
+type SummaryCache struct {
+	storage storage.Storage
+	memory  map[string]CacheItem
+	mu      sync.RWMutex
+	ttl     time.Duration
+}
+
+func NewSummaryCache(storage storage.Storage, ttl time.Duration) *SummaryCache {
+	return &SummaryCache{
+		storage: storage,
+		memory:  make(map[string]CacheItem),
+		ttl:     ttl,
+	}
+}
+
+func (c *SummaryCache) GetSummary(ctx context.Context, key string) (string, bool) {
+	c.mu.RLock()
+	item, found := c.memory[key]
+	c.mu.RUnlock()
+
+	if found && time.Now().Before(item.Expiration) {
+		if summary, ok := item.Value.(string); ok {
+			return summary, true
+		}
+	}
+
+	data, expiration, found, err := c.storage.GetSummary(ctx, key)
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("Error retrieving summary from storage")
+		return "", false
+	}
+	if !found {
+		return "", false
+	}
+
+	summary := string(data)
+
+	c.mu.Lock()
+	c.memory[key] = CacheItem{Value: summary, Expiration: expiration}
+	c.mu.Unlock()
+
+	return summary, true
+}
+
+func (c *SummaryCache) SetSummary(ctx context.Context, key string, summary string) {
+	expiration := time.Now().Add(c.ttl)
+
+	c.mu.Lock()
+	c.memory[key] = CacheItem{Value: summary, Expiration: expiration}
+	for k, v := range c.memory {
+		if time.Now().After(v.Expiration) {
+			delete(c.memory, k)
+		}
+	}
+	c.mu.Unlock()
+
+	if err := c.storage.SaveSummary(ctx, key, []byte(summary), expiration); err != nil {
+		log.Error().Err(err).Str("key", key).Msg("Error saving summary to storage")
+	}
+}
@@ -0,0 +1,66 @@
+package entities
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/akhlexe/stocknews-api/internal/apperrors"
+)
+
+//go:embed symbols.json
+var defaultSymbols []byte
+
+// Symbol is one entry of a symbol directory. Names are the company's own
+// names; aliases are brands and nicknames, which are weaker evidence.
+type Symbol struct {
+	Ticker  string   `json:"ticker"`
+	Names   []string `json:"names"`
+	Aliases []string `json:"aliases"`
+}
+
+// Directory maps company names and aliases to tickers.
+type Directory struct {
+	Symbols []Symbol
+}
+
+// DefaultDirectory returns the embedded directory of large US companies.
+func DefaultDirectory() *Directory {
+	dir, err := ParseDirectory(bytes.NewReader(defaultSymbols))
+	if err != nil {
+		panic(err)
+	}
+	return dir
+}
+
+// LoadDirectory reads a JSON symbol directory from path.
+func LoadDirectory(path string) (*Directory, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: error opening symbol directory: %v", apperrors.ErrConfiguration, err)
+	}
+	defer f.Close()
+
+	return ParseDirectory(f)
+}
+
+// ParseDirectory decodes a JSON array of symbols.
+func ParseDirectory(r io.Reader) (*Directory, error) {
+	var symbols []Symbol
+	if err := json.NewDecoder(r).Decode(&symbols); err != nil {
+		return nil, fmt.Errorf("%w: invalid symbol directory: %v", apperrors.ErrConfiguration, err)
+	}
+
+	for i, s := range symbols {
+		if strings.TrimSpace(s.Ticker) == "" {
+			return nil, fmt.Errorf("%w: symbol directory entry %d has no ticker", apperrors.ErrConfiguration, i)
+		}
+		symbols[i].Ticker = strings.ToUpper(strings.TrimSpace(s.Ticker))
+	}
+
+	return &Directory{Symbols: symbols}, nil
+}
@@ -0,0 +1,232 @@
+package entities
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+)
+
+// Confidence for each kind of evidence. A mention in the title adds
+// titleBonus, capped at 1.
+const (
+	ConfidenceCashtag = 0.95
+	ConfidenceName    = 0.9
+	ConfidenceAlias   = 0.7
+	ConfidenceTicker  = 0.6
+
+	// ConfidenceProvider is recorded for tickers the provider tagged itself.
+	ConfidenceProvider = 1.0
+
+	// DefaultMinConfidence drops weaker mentions.
+	DefaultMinConfidence = 0.5
+
+	titleBonus = 0.05
+
+	// minBareTickerLen keeps short tickers such as "V" or "MA" from matching
+	// ordinary words; they are only recognised as cashtags ("$V").
+	minBareTickerLen = 3
+)
+
+var wordPattern = regexp.MustCompile(`\$?[\p{L}\p{N}][\p{L}\p{N}&'.-]*`)
+
+// Mention is a ticker found in text.
+type Mention struct {
+	Ticker     string
+	Confidence float64
+}
+
+type phrase struct {
+	words      []string
+	ticker     string
+	confidence float64
+}
+
+// Extractor finds company mentions using a Directory. It is safe for
+// concurrent use.
+type Extractor struct {
+	// phrases are indexed by their lowercased first word and sorted longest
+	// first so "Bank of America" wins over a shorter match.
+	phrases map[string][]phrase
+	tickers map[string]bool
+
+	// MinConfidence is the lowest confidence added to articles.
+	MinConfidence float64
+}
+
+// NewExtractor builds an Extractor for dir.
+func NewExtractor(dir *Directory) *Extractor {
+	e := &Extractor{
+		phrases:       make(map[string][]phrase),
+		tickers:       make(map[string]bool),
+		MinConfidence: DefaultMinConfidence,
+	}
+
+	for _, s := range dir.Symbols {
+		e.tickers[s.Ticker] = true
+		for _, name := range s.Names {
+			e.addPhrase(name, s.Ticker, ConfidenceName)
+		}
+		for _, alias := range s.Aliases {
+			e.addPhrase(alias, s.Ticker, ConfidenceAlias)
+		}
+	}
+
+	for first := range e.phrases {
+		list := e.phrases[first]
+		sort.SliceStable(list, func(i, j int) bool { return len(list[i].words) > len(list[j].words) })
+	}
+
+	return e
+}
+
+func (e *Extractor) addPhrase(text, ticker string, confidence float64) {
+	words := words(text)
+	if len(words) == 0 {
+		return
+	}
+	for i := range words {
+		words[i] = strings.ToLower(words[i])
+	}
+	e.phrases[words[0]] = append(e.phrases[words[0]], phrase{words: words, ticker: ticker, confidence: confidence})
+}
+
+// Extract returns the tickers mentioned in text, highest confidence first.
+func (e *Extractor) Extract(text string) []Mention {
+	found := make(map[string]float64)
+	e.scan(text, found)
+	return sortedMentions(found)
+}
+
+// ExtractArticle returns the tickers mentioned in an article's title and
+// summary. Mentions in the title score higher.
+func (e *Extractor) ExtractArticle(a models.Article) []Mention {
+	title := make(map[string]float64)
+	e.scan(a.Title, title)
+
+	found := make(map[string]float64)
+	e.scan(a.Summary, found)
+
+	for ticker, confidence := range title {
+		confidence = math.Round(math.Min(confidence+titleBonus, 1)*100) / 100
+		if confidence > found[ticker] {
+			found[ticker] = confidence
+		}
+	}
+
+	return sortedMentions(found)
+}
+
+// Enrich adds mentioned tickers to each article's Tickers, recording the
+// confidence of every ticker in TickerConfidence.
+func (e *Extractor) Enrich(articles []models.Article) []models.Article {
+	for i := range articles {
+		a := &articles[i]
+
+		confidence := make(map[string]float64, len(a.Tickers))
+		for _, ticker := range a.Tickers {
+			confidence[ticker] = ConfidenceProvider
+		}
+		// Copy so the provider's slice, which may be cached, is not modified.
+		tickers := append([]string(nil), a.Tickers...)
+
+		for _, m := range e.ExtractArticle(*a) {
+			if m.Confidence < e.MinConfidence {
+				continue
+			}
+			if _, ok := confidence[m.Ticker]; ok {
+				continue
+			}
+			confidence[m.Ticker] = m.Confidence
+			tickers = append(tickers, m.Ticker)
+		}
+
+		if len(tickers) > len(a.Tickers) {
+			a.Tickers = tickers
+			a.TickerConfidence = confidence
+		}
+	}
+	return articles
+}
+
+func (e *Extractor) scan(text string, found map[string]float64) {
+	tokens := words(text)
+
+	record := func(ticker string, confidence float64) {
+		if confidence > found[ticker] {
+			found[ticker] = confidence
+		}
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+
+		if strings.HasPrefix(token, "$") {
+			if ticker := strings.ToUpper(token[1:]); e.tickers[ticker] {
+				record(ticker, ConfidenceCashtag)
+			}
+			continue
+		}
+
+		if len(token) >= minBareTickerLen && e.tickers[token] {
+			record(token, ConfidenceTicker)
+		}
+
+		// Names are proper nouns; "apple pie" is not a mention of Apple.
+		if !unicode.IsUpper([]rune(token)[0]) {
+			continue
+		}
+
+		for _, p := range e.phrases[strings.ToLower(token)] {
+			if matchesAt(tokens, i, p.words) {
+				record(p.ticker, p.confidence)
+				i += len(p.words) - 1
+				break
+			}
+		}
+	}
+}
+
+func matchesAt(tokens []string, i int, words []string) bool {
+	if i+len(words) > len(tokens) {
+		return false
+	}
+	for j, w := range words {
+		if strings.ToLower(tokens[i+j]) != w {
+			return false
+		}
+	}
+	return true
+}
+
+// words splits text into words, dropping trailing punctuation and
+// possessives so "Nvidia's" matches "Nvidia".
+func words(text string) []string {
+	matches := wordPattern.FindAllString(text, -1)
+	result := make([]string, 0, len(matches))
+	for _, w := range matches {
+		w = strings.TrimRight(w, ".-'")
+		w = strings.TrimSuffix(w, "'s")
+		if w != "" && w != "$" {
+			result = append(result, w)
+		}
+	}
+	return result
+}
+
+func sortedMentions(found map[string]float64) []Mention {
+	mentions := make([]Mention, 0, len(found))
+	for ticker, confidence := range found {
+		mentions = append(mentions, Mention{Ticker: ticker, Confidence: confidence})
+	}
+	sort.Slice(mentions, func(i, j int) bool {
+		if mentions[i].Confidence != mentions[j].Confidence {
+			return mentions[i].Confidence > mentions[j].Confidence
+		}
+		return mentions[i].Ticker < mentions[j].Ticker
+	})
+	return mentions
+}
@@ -0,0 +1,107 @@
+package entities
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDirectory = `[
+  {"ticker": "googl", "names": ["Alphabet", "Google"], "aliases": ["YouTube"]},
+  {"ticker": "NVDA", "names": ["Nvidia"], "aliases": []},
+  {"ticker": "AAPL", "names": ["Apple"], "aliases": []},
+  {"ticker": "BAC", "names": ["Bank of America"], "aliases": []},
+  {"ticker": "V", "names": ["Visa"], "aliases": []}
+]`
+
+func newTestExtractor(t *testing.T) *Extractor {
+	dir, err := ParseDirectory(strings.NewReader(testDirectory))
+	require.NoError(t, err)
+	return NewExtractor(dir)
+}
+
+func TestParseDirectory(t *testing.T) {
+	dir, err := ParseDirectory(strings.NewReader(testDirectory))
+	require.NoError(t, err)
+	assert.Equal(t, "GOOGL", dir.Symbols[0].Ticker)
+
+	_, err = ParseDirectory(strings.NewReader(`[{"names": ["Nameless"]}]`))
+	assert.Error(t, err)
+
+	_, err = ParseDirectory(strings.NewReader(`not json`))
+	assert.Error(t, err)
+}
+
+func TestDefaultDirectory(t *testing.T) {
+	mentions := NewExtractor(DefaultDirectory()).Extract("Alphabet and Nvidia rallied.")
+	assert.Equal(t, []Mention{{"GOOGL", ConfidenceName}, {"NVDA", ConfidenceName}}, mentions)
+}
+
+func TestExtract(t *testing.T) {
+	extractor := newTestExtractor(t)
+
+	testCases := []struct {
+		name     string
+		text     string
+		expected []Mention
+	}{
+		{"Company name", "Nvidia unveils new chips", []Mention{{"NVDA", ConfidenceName}}},
+		{"Possessive", "Nvidia's revenue doubled", []Mention{{"NVDA", ConfidenceName}}},
+		{"Alias", "YouTube ad sales grow", []Mention{{"GOOGL", ConfidenceAlias}}},
+		{"Cashtag", "Buying $nvda on the dip", []Mention{{"NVDA", ConfidenceCashtag}}},
+		{"Bare ticker", "NVDA closed higher", []Mention{{"NVDA", ConfidenceTicker}}},
+		{"Best evidence wins", "Google and YouTube", []Mention{{"GOOGL", ConfidenceName}}},
+		{"Multi-word name", "Bank of America raises targets", []Mention{{"BAC", ConfidenceName}}},
+		{"Lowercase is not a name", "an apple a day", []Mention{}},
+		{"Short ticker needs cashtag", "V shaped recovery", []Mention{}},
+		{"Short ticker cashtag", "$V hits a high", []Mention{{"V", ConfidenceCashtag}}},
+		{"Several companies", "Apple and Alphabet", []Mention{{"AAPL", ConfidenceName}, {"GOOGL", ConfidenceName}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, extractor.Extract(tc.text))
+		})
+	}
+}
+
+func TestExtractArticleTitleBonus(t *testing.T) {
+	extractor := newTestExtractor(t)
+
+	mentions := extractor.ExtractArticle(models.Article{Title: "Alphabet beats", Summary: "Shares of Apple also rose."})
+
+	assert.Equal(t, []Mention{{"GOOGL", 0.95}, {"AAPL", ConfidenceName}}, mentions)
+}
+
+func TestEnrich(t *testing.T) {
+	extractor := newTestExtractor(t)
+	providerTickers := []string{"AAPL"}
+
+	articles := extractor.Enrich([]models.Article{
+		{Title: "Alphabet and Apple sign a deal", Tickers: providerTickers},
+		{Title: "Markets are quiet"},
+		{Title: "Apple launches a phone", Tickers: []string{"AAPL"}},
+	})
+
+	assert.Equal(t, []string{"AAPL", "GOOGL"}, articles[0].Tickers)
+	assert.Equal(t, map[string]float64{"AAPL": ConfidenceProvider, "GOOGL": 0.95}, articles[0].TickerConfidence)
+	assert.Equal(t, []string{"AAPL"}, providerTickers, "provider slice is not modified")
+
+	assert.Empty(t, articles[1].Tickers)
+	assert.Nil(t, articles[1].TickerConfidence)
+
+	assert.Equal(t, []string{"AAPL"}, articles[2].Tickers)
+	assert.Nil(t, articles[2].TickerConfidence)
+}
+
+func TestEnrichMinConfidence(t *testing.T) {
+	extractor := newTestExtractor(t)
+	extractor.MinConfidence = 0.8
+
+	articles := extractor.Enrich([]models.Article{{Summary: "YouTube and NVDA"}})
+
+	assert.Empty(t, articles[0].Tickers)
+}
@@ -0,0 +1,196 @@
+package filter
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/akhlexe/stocknews-api/internal/sentiment"
+)
+
+// ClusterOptions tunes story clustering.
+type ClusterOptions struct {
+	// Threshold is the lowest headline similarity (0-1) at which an article
+	// joins a story.
+	Threshold float64
+
+	// Window is how long after a story's latest article a related article
+	// still belongs to it.
+	Window time.Duration
+}
+
+var DefaultClusterOptions = ClusterOptions{
+	Threshold: 0.3,
+	Window:    48 * time.Hour,
+}
+
+// Story is a group of articles covering the same event.
+type Story struct {
+	ID           string           `json:"id"`
+	Headline     string           `json:"headline"`
+	FirstSeen    string           `json:"first_seen"`
+	LastSeen     string           `json:"last_seen"`
+	Sentiment    StorySentiment   `json:"sentiment"`
+	ArticleCount int              `json:"article_count"`
+	Articles     []models.Article `json:"articles"`
+
+	terms  []map[string]bool
+	latest time.Time
+}
+
+// StorySentiment is the mean sentiment of a story's articles.
+type StorySentiment struct {
+	Score float64 `json:"score"`
+	Label string  `json:"label"`
+}
+
+// stopWords carry no information about which event a headline covers.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "has": true, "in": true,
+	"is": true, "it": true, "its": true, "of": true, "on": true, "or": true,
+	"s": true, "says": true, "stock": true, "shares": true, "that": true,
+	"the": true, "to": true, "with": true, "after": true, "over": true,
+}
+
+// ClusterStories groups articles into stories by headline similarity and
+// publish time. Articles are assigned oldest first to the most similar story
+// whose latest article is within the window; stories are returned largest
+// first, then most recent first. Undated articles are clustered on headline
+// similarity alone.
+func ClusterStories(articles []models.Article, opts ClusterOptions) []Story {
+	if opts.Threshold <= 0 {
+		opts.Threshold = DefaultClusterOptions.Threshold
+	}
+	if opts.Window <= 0 {
+		opts.Window = DefaultClusterOptions.Window
+	}
+
+	ordered := make([]models.Article, len(articles))
+	copy(ordered, articles)
+	SortArticles(ordered, SortOldest)
+
+	var stories []*Story
+	for _, a := range ordered {
+		terms := headlineTerms(a)
+		published, dated := a.PublishedTime()
+
+		var best *Story
+		bestSimilarity := 0.0
+		for _, story := range stories {
+			if dated && !story.latest.IsZero() && published.Sub(story.latest) > opts.Window {
+				continue
+			}
+			if similarity := story.similarity(terms); similarity >= opts.Threshold && similarity > bestSimilarity {
+				best, bestSimilarity = story, similarity
+			}
+		}
+
+		if best == nil {
+			best = &Story{}
+			stories = append(stories, best)
+		}
+		best.Articles = append(best.Articles, a)
+		best.terms = append(best.terms, terms)
+		if dated && published.After(best.latest) {
+			best.latest = published
+		}
+	}
+
+	result := make([]Story, 0, len(stories))
+	for _, story := range stories {
+		story.summarize()
+		result = append(result, *story)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].ArticleCount != result[j].ArticleCount {
+			return result[i].ArticleCount > result[j].ArticleCount
+		}
+		return result[i].latest.After(result[j].latest)
+	})
+
+	return result
+}
+
+// similarity is the highest similarity between terms and any member headline.
+func (s *Story) similarity(terms map[string]bool) float64 {
+	best := 0.0
+	for _, member := range s.terms {
+		if j := jaccard(terms, member); j > best {
+			best = j
+		}
+	}
+	return best
+}
+
+// summarize fills in the fields derived from the members. The representative
+// headline is the one most similar to the other members.
+func (s *Story) summarize() {
+	representative, bestScore := 0, -1.0
+	for i := range s.Articles {
+		score := 0.0
+		for j := range s.Articles {
+			if i != j {
+				score += jaccard(s.terms[i], s.terms[j])
+			}
+		}
+		if score > bestScore {
+			representative, bestScore = i, score
+		}
+	}
+
+	s.ID = s.Articles[representative].ID()
+	s.Headline = s.Articles[representative].Title
+	s.ArticleCount = len(s.Articles)
+
+	// Members are in publish order, with undated articles first.
+	for _, a := range s.Articles {
+		if a.PublishedAt != "" {
+			if s.FirstSeen == "" {
+				s.FirstSeen = a.PublishedAt
+			}
+			s.LastSeen = a.PublishedAt
+		}
+	}
+
+	total := 0.0
+	for _, a := range s.Articles {
+		total += SentimentScore(a)
+	}
+	score := math.Round(total/float64(len(s.Articles))*1000) / 1000
+	s.Sentiment = StorySentiment{Score: score, Label: sentiment.Label(score)}
+}
+
+// SentimentScore is an article's sentiment score. It prefers the provider's
+// score and otherwise places the label in the middle of its score range.
+func SentimentScore(a models.Article) float64 {
+	if a.SentimentScore != 0 {
+		return a.SentimentScore
+	}
+	return float64(SentimentRank(a.Sentiment)) * 0.25
+}
+
+func headlineTerms(a models.Article) map[string]bool {
+	terms := make(map[string]bool)
+	for _, t := range tokenize(a.Title) {
+		if !stopWords[t] {
+			terms[t] = true
+		}
+	}
+	return terms
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range a {
+		if b[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
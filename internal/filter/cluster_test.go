@@ -0,0 +1,58 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/akhlexe/stocknews-api/internal/sentiment"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterStories(t *testing.T) {
+	articles := []models.Article{
+		{Title: "Apple beats Q3 earnings estimates", URL: "1", PublishedAt: "20240801T203000", Sentiment: sentiment.LabelBullish, SentimentScore: 0.5},
+		{Title: "Apple Q3 earnings beat estimates on iPhone sales", URL: "2", PublishedAt: "20240801T210000", Sentiment: sentiment.LabelSomewhatBullish, SentimentScore: 0.3},
+		{Title: "Apple faces EU antitrust fine", URL: "3", PublishedAt: "20240801T120000", Sentiment: sentiment.LabelBearish},
+		{Title: "Why Apple Q3 earnings beat estimates", URL: "4", PublishedAt: "20240802T090000", SentimentScore: 0.1},
+		{Title: "Apple Q3 earnings beat estimates again", URL: "5", PublishedAt: "20240901T090000"},
+	}
+
+	stories := ClusterStories(articles, DefaultClusterOptions)
+	require.Len(t, stories, 3)
+
+	earnings := stories[0]
+	assert.Equal(t, 3, earnings.ArticleCount)
+	assert.Equal(t, []string{"1", "2", "4"}, articleURLs(earnings.Articles))
+	assert.Equal(t, "Why Apple Q3 earnings beat estimates", earnings.Headline)
+	assert.Equal(t, articles[3].ID(), earnings.ID)
+	assert.Equal(t, "20240801T203000", earnings.FirstSeen)
+	assert.Equal(t, "20240802T090000", earnings.LastSeen)
+	assert.Equal(t, StorySentiment{Score: 0.3, Label: sentiment.LabelSomewhatBullish}, earnings.Sentiment)
+
+	// Outside the window, the same headline starts a new story.
+	assert.Equal(t, []string{"5"}, articleURLs(stories[1].Articles))
+
+	assert.Equal(t, []string{"3"}, articleURLs(stories[2].Articles))
+	assert.Equal(t, StorySentiment{Score: -0.5, Label: sentiment.LabelBearish}, stories[2].Sentiment)
+}
+
+func TestClusterStoriesWindow(t *testing.T) {
+	articles := []models.Article{
+		{Title: "Tesla recalls Model Y", URL: "1", PublishedAt: "20240801T000000"},
+		{Title: "Tesla recalls Model Y vehicles", URL: "2", PublishedAt: "20240801T120000"},
+	}
+
+	assert.Len(t, ClusterStories(articles, DefaultClusterOptions), 1)
+	assert.Len(t, ClusterStories(articles, ClusterOptions{Window: time.Hour}), 2)
+	assert.Empty(t, ClusterStories(nil, DefaultClusterOptions))
+}
+
+func articleURLs(articles []models.Article) []string {
+	var urls []string
+	for _, a := range articles {
+		urls = append(urls, a.URL)
+	}
+	return urls
+}
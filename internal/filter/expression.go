@@ -0,0 +1,286 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+)
+
+var ErrInvalidExpression = errors.New("invalid query expression")
+
+// Expression is a boolean keyword query over an article's title and summary,
+// e.g. `(recall OR "safety probe") AND NOT rumor*`.
+//
+// Terms match whole words case-insensitively; a trailing * matches any word
+// with that prefix and quotes match a phrase. Terms next to each other must
+// all match. AND, OR and NOT must be upper case; -term is short for NOT term.
+type Expression struct {
+	source string
+	root   node
+}
+
+type node interface {
+	match(words []string) bool
+}
+
+type termNode struct {
+	words  []string
+	prefix bool
+}
+
+type notNode struct{ operand node }
+
+type andNode struct{ operands []node }
+
+type orNode struct{ operands []node }
+
+// ParseExpression compiles a query expression.
+func ParseExpression(source string) (*Expression, error) {
+	tokens, err := lexExpression(source)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%w: empty expression", ErrInvalidExpression)
+	}
+
+	p := &expressionParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidExpression, p.tokens[p.pos].text)
+	}
+
+	return &Expression{source: source, root: root}, nil
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Match reports whether the article's title or summary satisfies the
+// expression.
+func (e *Expression) Match(a models.Article) bool {
+	return e.root.match(tokenize(a.Title + " " + a.Summary))
+}
+
+func (n termNode) match(words []string) bool {
+	for i := 0; i+len(n.words) <= len(words); i++ {
+		matched := true
+		for j, w := range n.words {
+			last := j == len(n.words)-1
+			if words[i+j] != w && !(last && n.prefix && strings.HasPrefix(words[i+j], w)) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (n notNode) match(words []string) bool {
+	return !n.operand.match(words)
+}
+
+func (n andNode) match(words []string) bool {
+	for _, o := range n.operands {
+		if !o.match(words) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n orNode) match(words []string) bool {
+	for _, o := range n.operands {
+		if o.match(words) {
+			return true
+		}
+	}
+	return false
+}
+
+type tokenKind int
+
+const (
+	tokenTerm tokenKind = iota
+	tokenPhrase
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenOpen
+	tokenClose
+)
+
+type expressionToken struct {
+	kind tokenKind
+	text string
+}
+
+func lexExpression(source string) ([]expressionToken, error) {
+	var tokens []expressionToken
+	runes := []rune(source)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, expressionToken{kind: tokenOpen, text: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, expressionToken{kind: tokenClose, text: ")"})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("%w: unterminated quote", ErrInvalidExpression)
+			}
+			tokens = append(tokens, expressionToken{kind: tokenPhrase, text: string(runes[i+1 : end])})
+			i = end + 1
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]):
+			tokens = append(tokens, expressionToken{kind: tokenNot, text: "-"})
+			i++
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '(' && runes[end] != ')' && runes[end] != '"' {
+				end++
+			}
+			text := string(runes[i:end])
+			switch text {
+			case "AND":
+				tokens = append(tokens, expressionToken{kind: tokenAnd, text: text})
+			case "OR":
+				tokens = append(tokens, expressionToken{kind: tokenOr, text: text})
+			case "NOT":
+				tokens = append(tokens, expressionToken{kind: tokenNot, text: text})
+			default:
+				tokens = append(tokens, expressionToken{kind: tokenTerm, text: text})
+			}
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
+
+type expressionParser struct {
+	tokens []expressionToken
+	pos    int
+}
+
+func (p *expressionParser) peek() (expressionToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return expressionToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *expressionParser) parseOr() (node, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	operands := []node{first}
+
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind != tokenOr {
+			break
+		}
+		p.pos++
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, next)
+	}
+
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return orNode{operands: operands}, nil
+}
+
+func (p *expressionParser) parseAnd() (node, error) {
+	first, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	operands := []node{first}
+
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind == tokenOr || t.kind == tokenClose {
+			break
+		}
+		if t.kind == tokenAnd {
+			p.pos++
+		}
+		next, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, next)
+	}
+
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return andNode{operands: operands}, nil
+}
+
+func (p *expressionParser) parseNot() (node, error) {
+	t, ok := p.peek()
+	if ok && t.kind == tokenNot {
+		p.pos++
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *expressionParser) parsePrimary() (node, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("%w: unexpected end of expression", ErrInvalidExpression)
+	}
+	p.pos++
+
+	switch t.kind {
+	case tokenOpen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing, ok := p.peek(); !ok || closing.kind != tokenClose {
+			return nil, fmt.Errorf("%w: missing )", ErrInvalidExpression)
+		}
+		p.pos++
+		return inner, nil
+	case tokenTerm, tokenPhrase:
+		prefix := t.kind == tokenTerm && strings.HasSuffix(t.text, "*")
+		words := tokenize(strings.TrimSuffix(t.text, "*"))
+		if len(words) == 0 {
+			return nil, fmt.Errorf("%w: %q has no words", ErrInvalidExpression, t.text)
+		}
+		return termNode{words: words, prefix: prefix}, nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidExpression, t.text)
+	}
+}
@@ -0,0 +1,53 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpressionMatch(t *testing.T) {
+	article := models.Article{
+		Title:   "Tesla recalls 2 million vehicles",
+		Summary: "Regulators opened a safety probe into Autopilot.",
+	}
+
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{"recall", false},
+		{"recall*", true},
+		{"TESLA", true},
+		{"tesla recalls", true},
+		{"tesla earnings", false},
+		{"tesla AND earnings", false},
+		{"earnings OR recalls", true},
+		{`"safety probe"`, true},
+		{`"probe safety"`, false},
+		{"tesla -autopilot", false},
+		{"tesla NOT earnings", true},
+		{"(earnings OR guidance) AND tesla", false},
+		{"(earnings OR recall*) AND NOT (lawsuit OR rumor)", true},
+		{"NOT NOT tesla", true},
+		{"auto*", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			expr, err := ParseExpression(tt.expression)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, expr.Match(article))
+			assert.Equal(t, tt.expression, expr.String())
+		})
+	}
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+	for _, source := range []string{"", "   ", "(tesla", "tesla)", `"open quote`, "tesla OR", "AND tesla", "NOT", "()", `""`, "*"} {
+		_, err := ParseExpression(source)
+		assert.ErrorIs(t, err, ErrInvalidExpression, source)
+	}
+}
@@ -0,0 +1,235 @@
+package filter
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+)
+
+type SortOrder string
+
+const (
+	SortNone      SortOrder = ""
+	SortRelevance SortOrder = "relevance"
+	SortNewest    SortOrder = "newest"
+	SortOldest    SortOrder = "oldest"
+	SortSentiment SortOrder = "sentiment"
+)
+
+var ErrInvalidSortOrder = errors.New("invalid sort order")
+
+// ParseSortOrder validates the value of the sort query parameter.
+func ParseSortOrder(value string) (SortOrder, error) {
+	switch order := SortOrder(strings.ToLower(value)); order {
+	case SortNone, SortRelevance, SortNewest, SortOldest, SortSentiment:
+		return order, nil
+	default:
+		return SortNone, ErrInvalidSortOrder
+	}
+}
+
+// RankOptions tunes the BM25 scoring. Zero values fall back to DefaultRankOptions.
+type RankOptions struct {
+	K1            float64
+	B             float64
+	TitleWeight   float64
+	SummaryWeight float64
+
+	// HalfLife enables recency decay: an article HalfLife old scores half as
+	// much as a brand new one. Zero disables decay.
+	HalfLife time.Duration
+	Now      time.Time
+}
+
+var DefaultRankOptions = RankOptions{
+	K1:            1.2,
+	B:             0.75,
+	TitleWeight:   2.0,
+	SummaryWeight: 1.0,
+}
+
+type document struct {
+	title   map[string]int
+	summary map[string]int
+	titleN  int
+	summN   int
+}
+
+// ScoreBM25 returns a copy of articles with Score set to the BM25F relevance of
+// each article for query. Corpus statistics are computed over all articles, so
+// callers should score before filtering. Order is preserved.
+func ScoreBM25(articles []models.Article, query string, opts RankOptions) []models.Article {
+	opts = opts.withDefaults()
+	terms := uniqueTerms(tokenize(query))
+
+	scored := make([]models.Article, len(articles))
+	copy(scored, articles)
+
+	if len(terms) == 0 || len(articles) == 0 {
+		return scored
+	}
+
+	docs := make([]document, len(articles))
+	var totalTitle, totalSumm int
+	df := make(map[string]int)
+
+	for i, a := range articles {
+		titleTokens := tokenize(a.Title)
+		summTokens := tokenize(a.Summary)
+		docs[i] = document{
+			title:   termFrequencies(titleTokens),
+			summary: termFrequencies(summTokens),
+			titleN:  len(titleTokens),
+			summN:   len(summTokens),
+		}
+		totalTitle += len(titleTokens)
+		totalSumm += len(summTokens)
+
+		for _, t := range terms {
+			if docs[i].title[t] > 0 || docs[i].summary[t] > 0 {
+				df[t]++
+			}
+		}
+	}
+
+	n := float64(len(articles))
+	avgTitle := math.Max(float64(totalTitle)/n, 1)
+	avgSumm := math.Max(float64(totalSumm)/n, 1)
+
+	for i, d := range docs {
+		var score float64
+		for _, t := range terms {
+			tf := opts.TitleWeight*normalizedTF(d.title[t], d.titleN, avgTitle, opts.B) +
+				opts.SummaryWeight*normalizedTF(d.summary[t], d.summN, avgSumm, opts.B)
+			if tf == 0 {
+				continue
+			}
+			idf := math.Log(1 + (n-float64(df[t])+0.5)/(float64(df[t])+0.5))
+			score += idf * tf / (opts.K1 + tf)
+		}
+
+		if opts.HalfLife > 0 {
+			score *= recencyFactor(scored[i], opts.Now, opts.HalfLife)
+		}
+
+		scored[i].Score = score
+	}
+
+	return scored
+}
+
+// SortArticles orders articles in place. SortNone keeps upstream order and
+// ties always keep their relative order.
+func SortArticles(articles []models.Article, order SortOrder) {
+	switch order {
+	case SortRelevance:
+		sort.SliceStable(articles, func(i, j int) bool {
+			return articles[i].Score > articles[j].Score
+		})
+	case SortNewest:
+		sort.SliceStable(articles, func(i, j int) bool {
+			return publishedUnix(articles[i]) > publishedUnix(articles[j])
+		})
+	case SortOldest:
+		sort.SliceStable(articles, func(i, j int) bool {
+			return publishedUnix(articles[i]) < publishedUnix(articles[j])
+		})
+	case SortSentiment:
+		sort.SliceStable(articles, func(i, j int) bool {
+			return SentimentRank(articles[i].Sentiment) > SentimentRank(articles[j].Sentiment)
+		})
+	}
+}
+
+// SentimentRank maps AlphaVantage sentiment labels onto a bullish-to-bearish scale.
+func SentimentRank(label string) int {
+	switch strings.ToLower(label) {
+	case "bullish":
+		return 2
+	case "somewhat-bullish":
+		return 1
+	case "somewhat-bearish":
+		return -1
+	case "bearish":
+		return -2
+	default:
+		return 0
+	}
+}
+
+func (o RankOptions) withDefaults() RankOptions {
+	if o.K1 == 0 {
+		o.K1 = DefaultRankOptions.K1
+	}
+	if o.B == 0 {
+		o.B = DefaultRankOptions.B
+	}
+	if o.TitleWeight == 0 {
+		o.TitleWeight = DefaultRankOptions.TitleWeight
+	}
+	if o.SummaryWeight == 0 {
+		o.SummaryWeight = DefaultRankOptions.SummaryWeight
+	}
+	if o.Now.IsZero() {
+		o.Now = time.Now()
+	}
+	return o
+}
+
+func normalizedTF(tf, length int, avgLength, b float64) float64 {
+	if tf == 0 {
+		return 0
+	}
+	return float64(tf) / (1 - b + b*float64(length)/avgLength)
+}
+
+func recencyFactor(a models.Article, now time.Time, halfLife time.Duration) float64 {
+	published, ok := a.PublishedTime()
+	if !ok {
+		return 1
+	}
+	age := now.Sub(published)
+	if age <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, float64(age)/float64(halfLife))
+}
+
+func publishedUnix(a models.Article) int64 {
+	t, ok := a.PublishedTime()
+	if !ok {
+		return 0
+	}
+	return t.Unix()
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func termFrequencies(tokens []string) map[string]int {
+	freq := make(map[string]int, len(tokens))
+	for _, t := range tokens {
+		freq[t]++
+	}
+	return freq
+}
+
+func uniqueTerms(tokens []string) []string {
+	seen := make(map[string]bool, len(tokens))
+	var terms []string
+	for _, t := range tokens {
+		if !seen[t] {
+			seen[t] = true
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
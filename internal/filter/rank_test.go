@@ -0,0 +1,82 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScoreBM25(t *testing.T) {
+	articles := []models.Article{
+		{Title: "Quarterly earnings beat", Summary: "Revenue grew across segments."},
+		{Title: "New product launch", Summary: "Analysts discuss earnings impact of the launch."},
+		{Title: "CEO interview", Summary: "Nothing about results."},
+	}
+
+	scored := ScoreBM25(articles, "earnings", DefaultRankOptions)
+
+	assert.Greater(t, scored[0].Score, scored[1].Score, "Title match should outrank summary match")
+	assert.Greater(t, scored[1].Score, 0.0)
+	assert.Equal(t, 0.0, scored[2].Score)
+	assert.Equal(t, 0.0, articles[0].Score, "Input should not be modified")
+}
+
+func TestScoreBM25RecencyDecay(t *testing.T) {
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	articles := []models.Article{
+		{Title: "Earnings report", PublishedAt: "20240101T000000"},
+		{Title: "Earnings report", PublishedAt: "20240109T000000"},
+	}
+
+	opts := DefaultRankOptions
+	opts.HalfLife = 24 * time.Hour
+	opts.Now = now
+
+	scored := ScoreBM25(articles, "earnings", opts)
+	assert.Greater(t, scored[1].Score, scored[0].Score)
+	// Identical text, eight days apart with a one-day half-life.
+	assert.InDelta(t, 1.0/256, scored[0].Score/scored[1].Score, 1e-9)
+}
+
+func TestSortArticles(t *testing.T) {
+	testCases := []struct {
+		name     string
+		order    SortOrder
+		expected []string
+	}{
+		{"None keeps order", SortNone, []string{"a", "b", "c"}},
+		{"Relevance", SortRelevance, []string{"b", "c", "a"}},
+		{"Newest", SortNewest, []string{"c", "a", "b"}},
+		{"Oldest", SortOldest, []string{"b", "a", "c"}},
+		{"Sentiment", SortSentiment, []string{"c", "a", "b"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			articles := []models.Article{
+				{Title: "a", Score: 1, PublishedAt: "20240102T000000", Sentiment: "Neutral"},
+				{Title: "b", Score: 3, PublishedAt: "20240101T000000", Sentiment: "Bearish"},
+				{Title: "c", Score: 2, PublishedAt: "20240103T000000", Sentiment: "Somewhat-Bullish"},
+			}
+
+			SortArticles(articles, tc.order)
+
+			var titles []string
+			for _, a := range articles {
+				titles = append(titles, a.Title)
+			}
+			assert.Equal(t, tc.expected, titles)
+		})
+	}
+}
+
+func TestParseSortOrder(t *testing.T) {
+	order, err := ParseSortOrder("Newest")
+	assert.NoError(t, err)
+	assert.Equal(t, SortNewest, order)
+
+	_, err = ParseSortOrder("popular")
+	assert.ErrorIs(t, err, ErrInvalidSortOrder)
+}
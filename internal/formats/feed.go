@@ -0,0 +1,196 @@
+package formats
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+)
+
+// Content types of the formats.
+const (
+	ContentTypeRSS    = "application/rss+xml; charset=utf-8"
+	ContentTypeAtom   = "application/atom+xml; charset=utf-8"
+	ContentTypeCSV    = "text/csv; charset=utf-8"
+	ContentTypeNDJSON = "application/x-ndjson"
+)
+
+// articleIDPrefix makes article IDs into the URIs Atom requires.
+const articleIDPrefix = "urn:stocknews:article:"
+
+// Feed describes a feed of articles.
+type Feed struct {
+	Title       string
+	Description string
+
+	// URL is the feed's own address.
+	URL string
+
+	// Updated is when the articles were last fetched.
+	Updated time.Time
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	DC      string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Self          atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link,omitempty"`
+	Description string   `xml:"description,omitempty"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate,omitempty"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// WriteRSS writes articles as an RSS 2.0 feed. Articles are identified by URL,
+// or by ID when they have none; sources become dc:creator and tickers
+// categories.
+func WriteRSS(w io.Writer, feed Feed, articles []models.Article) error {
+	channel := rssChannel{
+		Title:         feed.Title,
+		Link:          feed.URL,
+		Description:   feed.Description,
+		LastBuildDate: feed.Updated.UTC().Format(time.RFC1123Z),
+		Self:          atomLink{Href: feed.URL, Rel: "self", Type: "application/rss+xml"},
+		Items:         make([]rssItem, 0, len(articles)),
+	}
+
+	for _, a := range articles {
+		item := rssItem{
+			Title:       a.Title,
+			Link:        a.URL,
+			Description: a.Summary,
+			GUID:        rssGUID{IsPermaLink: a.URL != "", Value: a.URL},
+			Creator:     a.Source,
+			Categories:  a.Tickers,
+		}
+		if a.URL == "" {
+			item.GUID.Value = articleIDPrefix + a.ID()
+		}
+		if published, ok := a.PublishedTime(); ok {
+			item.PubDate = published.Format(time.RFC1123Z)
+		}
+		channel.Items = append(channel.Items, item)
+	}
+
+	doc := rssDocument{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		DC:      "http://purl.org/dc/elements/1.1/",
+		Channel: channel,
+	}
+	return writeXML(w, doc)
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomPerson  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Author     *atomPerson    `xml:"author"`
+	Links      []atomLink     `xml:"link"`
+	Summary    string         `xml:"summary,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// WriteAtom writes articles as an Atom feed. Entries are identified by article
+// ID, so they keep their identity if a URL changes; articles without a publish
+// time are dated with the feed.
+func WriteAtom(w io.Writer, feed Feed, articles []models.Article) error {
+	updated := feed.Updated.UTC().Format(time.RFC3339)
+	doc := atomFeed{
+		ID:      feed.URL,
+		Title:   feed.Title,
+		Updated: updated,
+		Author:  atomPerson{Name: "Stock News API"},
+		Links:   []atomLink{{Href: feed.URL, Rel: "self", Type: "application/atom+xml"}},
+		Entries: make([]atomEntry, 0, len(articles)),
+	}
+
+	for _, a := range articles {
+		entry := atomEntry{
+			ID:      articleIDPrefix + a.ID(),
+			Title:   a.Title,
+			Updated: updated,
+			Summary: a.Summary,
+		}
+		if published, ok := a.PublishedTime(); ok {
+			entry.Published = published.Format(time.RFC3339)
+			entry.Updated = entry.Published
+		}
+		if a.Source != "" {
+			entry.Author = &atomPerson{Name: a.Source}
+		}
+		if a.URL != "" {
+			entry.Links = append(entry.Links, atomLink{Href: a.URL, Rel: "alternate"})
+		}
+		if a.Image != "" {
+			entry.Links = append(entry.Links, atomLink{Href: a.Image, Rel: "enclosure"})
+		}
+		for _, ticker := range a.Tickers {
+			entry.Categories = append(entry.Categories, atomCategory{Term: ticker})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return writeXML(w, doc)
+}
+
+// writeXML writes v as an indented XML document.
+func writeXML(w io.Writer, v interface{}) error {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
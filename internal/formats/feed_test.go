@@ -0,0 +1,130 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var feedArticles = []models.Article{
+	{
+		Title:       "Apple <beats> & \"raises\"",
+		URL:         "https://example.com/apple?a=1&b=2",
+		Summary:     "Revenue up\x00 <b>10%</b>",
+		Image:       "https://example.com/apple.png",
+		PublishedAt: "20250102T150405",
+		Source:      "Reuters",
+		Tickers:     []string{"AAPL", "MSFT"},
+	},
+	{Title: "Untimed note"},
+}
+
+var testFeed = Feed{
+	Title:       "AAPL news",
+	Description: "Financial news for AAPL.",
+	URL:         "https://api.example.com/news/AAPL?format=rss",
+	Updated:     time.Date(2025, 1, 3, 8, 0, 0, 0, time.UTC),
+}
+
+func TestWriteRSS(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteRSS(&buf, testFeed, feedArticles))
+	assert.True(t, strings.HasPrefix(buf.String(), xml.Header))
+
+	var doc struct {
+		Version string `xml:"version,attr"`
+		Channel struct {
+			Title         string `xml:"title"`
+			LastBuildDate string `xml:"lastBuildDate"`
+			Items         []struct {
+				Title       string   `xml:"title"`
+				Link        string   `xml:"link"`
+				Description string   `xml:"description"`
+				GUID        string   `xml:"guid"`
+				PubDate     string   `xml:"pubDate"`
+				Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+				Categories  []string `xml:"category"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+
+	assert.Equal(t, "2.0", doc.Version)
+	assert.Equal(t, "AAPL news", doc.Channel.Title)
+	// Unmarshalling can't tell <link> from <atom:link>.
+	assert.Contains(t, buf.String(), "<link>"+testFeed.URL+"</link>")
+	assert.Equal(t, "Fri, 03 Jan 2025 08:00:00 +0000", doc.Channel.LastBuildDate)
+	require.Len(t, doc.Channel.Items, 2)
+
+	item := doc.Channel.Items[0]
+	assert.Equal(t, feedArticles[0].Title, item.Title, "markup in text is escaped, not interpreted")
+	assert.Equal(t, feedArticles[0].URL, item.Link)
+	assert.Equal(t, "Revenue up� <b>10%</b>", item.Description, "invalid XML characters are replaced")
+	assert.Equal(t, feedArticles[0].URL, item.GUID)
+	assert.Equal(t, "Thu, 02 Jan 2025 15:04:05 +0000", item.PubDate)
+	assert.Equal(t, "Reuters", item.Creator)
+	assert.Equal(t, []string{"AAPL", "MSFT"}, item.Categories)
+
+	assert.Equal(t, "urn:stocknews:article:"+feedArticles[1].ID(), doc.Channel.Items[1].GUID)
+	assert.Empty(t, doc.Channel.Items[1].PubDate)
+	assert.Contains(t, buf.String(), `<guid isPermaLink="false">`)
+}
+
+func TestWriteAtom(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteAtom(&buf, testFeed, feedArticles))
+
+	var doc struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		ID      string   `xml:"id"`
+		Updated string   `xml:"updated"`
+		Links   []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Entries []struct {
+			ID        string `xml:"id"`
+			Title     string `xml:"title"`
+			Updated   string `xml:"updated"`
+			Published string `xml:"published"`
+			Author    struct {
+				Name string `xml:"name"`
+			} `xml:"author"`
+			Links []struct {
+				Href string `xml:"href,attr"`
+				Rel  string `xml:"rel,attr"`
+			} `xml:"link"`
+			Categories []struct {
+				Term string `xml:"term,attr"`
+			} `xml:"category"`
+		} `xml:"entry"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+
+	assert.Equal(t, testFeed.URL, doc.ID)
+	assert.Equal(t, "2025-01-03T08:00:00Z", doc.Updated)
+	require.Len(t, doc.Links, 1)
+	assert.Equal(t, "self", doc.Links[0].Rel)
+	require.Len(t, doc.Entries, 2)
+
+	entry := doc.Entries[0]
+	assert.Equal(t, "urn:stocknews:article:"+feedArticles[0].ID(), entry.ID)
+	assert.Equal(t, feedArticles[0].Title, entry.Title)
+	assert.Equal(t, "2025-01-02T15:04:05Z", entry.Published)
+	assert.Equal(t, entry.Published, entry.Updated)
+	assert.Equal(t, "Reuters", entry.Author.Name)
+	require.Len(t, entry.Links, 2)
+	assert.Equal(t, feedArticles[0].URL, entry.Links[0].Href)
+	assert.Equal(t, "alternate", entry.Links[0].Rel)
+	assert.Equal(t, "enclosure", entry.Links[1].Rel)
+	require.Len(t, entry.Categories, 2)
+	assert.Equal(t, "AAPL", entry.Categories[0].Term)
+
+	assert.Equal(t, doc.Updated, doc.Entries[1].Updated, "untimed entries are dated with the feed")
+}
@@ -0,0 +1,146 @@
+package formats
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+)
+
+// DefaultCSVColumns are the article fields written when no columns are given.
+var DefaultCSVColumns = []string{
+	"id",
+	"time_published",
+	"title",
+	"source",
+	"url",
+	"tickers",
+	"overall_sentiment_label",
+	"overall_sentiment_score",
+	"summary",
+}
+
+// WriteCSV writes articles as CSV with a header row, one column per article
+// field. Lists are joined with ";", ticker confidences are written as
+// "TICKER:confidence", and publish times as RFC 3339 so spreadsheets parse
+// them. Text starting with a formula character is prefixed with "'" so
+// spreadsheets don't evaluate it.
+func WriteCSV(w io.Writer, articles []models.Article, columns []string) error {
+	if len(columns) == 0 {
+		columns = DefaultCSVColumns
+	}
+	if err := models.ValidateArticleFields(columns); err != nil {
+		return err
+	}
+
+	out := csv.NewWriter(w)
+	if err := out.Write(columns); err != nil {
+		return err
+	}
+
+	row := make([]string, len(columns))
+	for _, a := range articles {
+		for i, column := range columns {
+			value, ok := csvValue(a, column)
+			if !ok {
+				return fmt.Errorf("no CSV column for article field %q", column)
+			}
+			row[i] = value
+		}
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// csvValue formats an article field for CSV. It returns false for fields it
+// doesn't know.
+func csvValue(a models.Article, column string) (string, bool) {
+	switch column {
+	case "id":
+		return a.ID(), true
+	case "time_published":
+		if published, ok := a.PublishedTime(); ok {
+			return published.Format(time.RFC3339), true
+		}
+		return csvText(a.PublishedAt), true
+	case "tickers":
+		return csvText(strings.Join(a.Tickers, ";")), true
+	case "ticker_confidence":
+		tickers := make([]string, 0, len(a.TickerConfidence))
+		for ticker := range a.TickerConfidence {
+			tickers = append(tickers, ticker)
+		}
+		sort.Strings(tickers)
+		pairs := make([]string, 0, len(tickers))
+		for _, ticker := range tickers {
+			pairs = append(pairs, ticker+":"+formatFloat(a.TickerConfidence[ticker]))
+		}
+		return csvText(strings.Join(pairs, ";")), true
+	case "score":
+		return formatFloat(a.Score), true
+	case "overall_sentiment_score":
+		return formatFloat(a.SentimentScore), true
+	case "title":
+		return csvText(a.Title), true
+	case "url":
+		return csvText(a.URL), true
+	case "summary":
+		return csvText(a.Summary), true
+	case "banner_image":
+		return csvText(a.Image), true
+	case "source":
+		return csvText(a.Source), true
+	case "overall_sentiment_label":
+		return csvText(a.Sentiment), true
+	default:
+		return "", false
+	}
+}
+
+// csvText neutralizes text a spreadsheet would read as a formula.
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// WriteNDJSON writes one article per line as JSON, reduced to fields when
+// any are given.
+func WriteNDJSON(w io.Writer, articles []models.Article, fields []string) error {
+	enc := json.NewEncoder(w)
+
+	if len(fields) == 0 {
+		for _, a := range articles {
+			if err := enc.Encode(a); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	projected, err := models.ProjectArticles(articles, fields)
+	if err != nil {
+		return err
+	}
+	for _, item := range projected {
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package formats
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCSV(t *testing.T) {
+	articles := []models.Article{
+		{
+			Title:            `Apple, "the" company`,
+			URL:              "https://example.com/apple",
+			Summary:          "Line one\nline two",
+			PublishedAt:      "20250102T150405",
+			Source:           "=HYPERLINK(\"http://evil\")",
+			Sentiment:        "Bullish",
+			SentimentScore:   -0.25,
+			Tickers:          []string{"AAPL", "MSFT"},
+			TickerConfidence: map[string]float64{"MSFT": 0.5, "AAPL": 1},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, articles, nil))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, DefaultCSVColumns, records[0])
+	assert.Equal(t, []string{
+		articles[0].ID(),
+		"2025-01-02T15:04:05Z",
+		`Apple, "the" company`,
+		`'=HYPERLINK("http://evil")`,
+		"https://example.com/apple",
+		"AAPL;MSFT",
+		"Bullish",
+		"-0.25",
+		"Line one\nline two",
+	}, records[1])
+
+	buf.Reset()
+	require.NoError(t, WriteCSV(&buf, articles, []string{"title", "ticker_confidence"}))
+	records, err = csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"title", "ticker_confidence"}, {`Apple, "the" company`, "AAPL:1;MSFT:0.5"}}, records)
+
+	assert.Error(t, WriteCSV(&buf, articles, []string{"nope"}))
+}
+
+// TestCSVCoversArticleFields fails when an article field has no CSV column.
+func TestCSVCoversArticleFields(t *testing.T) {
+	articleType := reflect.TypeOf(models.Article{})
+	for i := 0; i < articleType.NumField(); i++ {
+		field := strings.Split(articleType.Field(i).Tag.Get("json"), ",")[0]
+		_, ok := csvValue(models.Article{}, field)
+		assert.True(t, ok, "no CSV column for %s", field)
+	}
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	articles := []models.Article{
+		{Title: "Line one\nline two", URL: "https://example.com/1", Tickers: []string{"AAPL"}},
+		{Title: "Second", URL: "https://example.com/2"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteNDJSON(&buf, articles, nil))
+
+	var lines []models.Article
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var a models.Article
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &a))
+		lines = append(lines, a)
+	}
+	assert.Equal(t, articles, lines, "newlines in values stay escaped within their line")
+
+	buf.Reset()
+	require.NoError(t, WriteNDJSON(&buf, articles, []string{"id", "title"}))
+	assert.Equal(t,
+		`{"id":"`+articles[0].ID()+`","title":"Line one\nline two"}`+"\n"+`{"id":"`+articles[1].ID()+`","title":"Second"}`+"\n",
+		buf.String())
+}
@@ -0,0 +1,302 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/ai"
+	"github.com/akhlexe/stocknews-api/internal/news"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultWorkers matches the summary service's default concurrency.
+	DefaultWorkers   = ai.DefaultConcurrency
+	DefaultQueueSize = 100
+	DefaultRetention = time.Hour
+
+	fetchTimeout = 10 * time.Second
+
+	// maxEvictInterval bounds how long a job can outlive its retention.
+	maxEvictInterval = time.Minute
+)
+
+// ErrQueueFull is returned by Submit when the queue has no room left.
+var ErrQueueFull = errors.New("summary queue is full")
+
+type Status string
+
+const (
+	StatusQueued  Status = "queued"
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+)
+
+// Request describes the summaries a job produces, one per ticker.
+type Request struct {
+	Tickers    []string `json:"tickers"`
+	Style      string   `json:"style"`
+	Structured bool     `json:"structured"`
+}
+
+// Result is the summary of one ticker, or the reason it failed.
+type Result struct {
+	Ticker           string      `json:"ticker"`
+	Summary          interface{} `json:"summary,omitempty"`
+	Cached           bool        `json:"cached"`
+	PromptVersion    string      `json:"prompt_version,omitempty"`
+	Warnings         []string    `json:"warnings,omitempty"`
+	ArticlesIncluded int         `json:"articles_included"`
+	ArticlesTotal    int         `json:"articles_total"`
+	Error            string      `json:"error,omitempty"`
+
+	err error
+}
+
+// Job is a queued summary request. A job is done once every ticker has been
+// attempted, and failed only if every ticker failed.
+type Job struct {
+	ID         string     `json:"id"`
+	Status     Status     `json:"status"`
+	Request    Request    `json:"request"`
+	Results    []Result   `json:"results"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Options sizes the queue. Zero values use the defaults.
+type Options struct {
+	// Workers is the number of jobs summarized concurrently.
+	Workers int
+
+	// QueueSize bounds the number of jobs waiting for a worker.
+	QueueSize int
+
+	// Retention is how long finished jobs can still be polled.
+	Retention time.Duration
+}
+
+// Queue runs summary jobs on a fixed pool of workers. Jobs are kept in memory.
+type Queue struct {
+	fetcher   news.Provider
+	summaries *ai.SummaryService
+	opts      Options
+
+	pending chan string
+	mu      sync.RWMutex
+	jobs    map[string]*Job
+}
+
+func NewQueue(fetcher news.Provider, summaries *ai.SummaryService, opts Options) *Queue {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultWorkers
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultQueueSize
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = DefaultRetention
+	}
+
+	return &Queue{
+		fetcher:   fetcher,
+		summaries: summaries,
+		opts:      opts,
+		pending:   make(chan string, opts.QueueSize),
+		jobs:      make(map[string]*Job),
+	}
+}
+
+// Start launches the workers and the eviction of finished jobs. They stop
+// when ctx is done.
+func (q *Queue) Start(ctx context.Context) {
+	go q.evictLoop(ctx)
+
+	for i := 0; i < q.opts.Workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-q.pending:
+					q.run(ctx, id)
+				}
+			}
+		}()
+	}
+}
+
+// Submit queues a job, or returns ErrQueueFull.
+func (q *Queue) Submit(req Request) (Job, error) {
+	id, err := newJobID()
+	if err != nil {
+		return Job{}, err
+	}
+
+	job := &Job{
+		ID:        id,
+		Status:    StatusQueued,
+		Request:   req,
+		Results:   []Result{},
+		CreatedAt: time.Now().UTC(),
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	select {
+	case q.pending <- id:
+		q.jobs[id] = job
+	default:
+		return Job{}, ErrQueueFull
+	}
+
+	log.Info().Str("job_id", id).Strs("tickers", req.Tickers).Msg("Summary job queued")
+	return *job, nil
+}
+
+// Get returns a snapshot of a job.
+func (q *Queue) Get(id string) (Job, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+
+	snapshot := *job
+	snapshot.Results = append([]Result{}, job.Results...)
+	return snapshot, true
+}
+
+func (q *Queue) run(ctx context.Context, id string) {
+	q.mu.Lock()
+	job, ok := q.jobs[id]
+	if !ok {
+		q.mu.Unlock()
+		return
+	}
+	started := time.Now().UTC()
+	job.Status = StatusRunning
+	job.StartedAt = &started
+	req := job.Request
+	q.mu.Unlock()
+
+	failures := 0
+	for _, ticker := range req.Tickers {
+		result := q.summarize(ctx, ticker, req)
+		if result.err != nil {
+			failures++
+			log.Warn().Err(result.err).Str("job_id", id).Str("ticker", ticker).Msg("Summary job ticker failed")
+		}
+
+		q.mu.Lock()
+		job.Results = append(job.Results, result)
+		q.mu.Unlock()
+	}
+
+	finished := time.Now().UTC()
+
+	status := StatusDone
+	if failures == len(req.Tickers) {
+		status = StatusFailed
+	}
+
+	q.mu.Lock()
+	job.FinishedAt = &finished
+	job.Status = status
+	q.mu.Unlock()
+
+	log.Info().Str("job_id", id).Str("status", string(status)).Dur("duration", finished.Sub(started)).Msg("Summary job finished")
+}
+
+func (q *Queue) summarize(ctx context.Context, ticker string, req Request) Result {
+	result := Result{Ticker: ticker}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	articles, err := q.fetcher.GetNewsByTicker(fetchCtx, ticker)
+	cancel()
+	if err != nil {
+		return failed(result, err)
+	}
+	result.ArticlesTotal = len(articles)
+
+	if ai.CombineArticles(articles) == "" {
+		result.Summary = ""
+		return result
+	}
+
+	summaryReq := ai.SummaryRequest{Ticker: ticker, Articles: articles, Style: req.Style}
+
+	var summary ai.Summary
+	if req.Structured {
+		summary, err = q.summaries.SummarizeStructured(ctx, summaryReq)
+	} else {
+		summary, err = q.summaries.Summarize(ctx, summaryReq)
+	}
+	if err != nil {
+		return failed(result, err)
+	}
+
+	result.Summary = summary.Text
+	if summary.Structured != nil {
+		result.Summary = summary.Structured
+	}
+	result.Cached = summary.Cached
+	result.PromptVersion = summary.PromptVersion
+	result.Warnings = summary.Warnings
+	result.ArticlesIncluded = summary.ArticlesIncluded
+	return result
+}
+
+func failed(result Result, err error) Result {
+	result.err = err
+	result.Error = err.Error()
+	return result
+}
+
+func (q *Queue) evictLoop(ctx context.Context) {
+	interval := q.opts.Retention
+	if interval > maxEvictInterval {
+		interval = maxEvictInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			q.mu.Lock()
+			q.evictExpired()
+			q.mu.Unlock()
+		}
+	}
+}
+
+// evictExpired drops finished jobs past their retention. The caller holds mu.
+func (q *Queue) evictExpired() {
+	cutoff := time.Now().Add(-q.opts.Retention)
+	for id, job := range q.jobs {
+		if job.FinishedAt != nil && job.FinishedAt.Before(cutoff) {
+			delete(q.jobs, id)
+		}
+	}
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,175 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/ai"
+	"github.com/akhlexe/stocknews-api/internal/apperrors"
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProvider struct{}
+
+func (fakeProvider) GetNewsByTicker(ctx context.Context, ticker string) ([]models.Article, error) {
+	if ticker == "MISSING" {
+		return nil, apperrors.ErrNotFound
+	}
+	return []models.Article{{Title: ticker + " rises", Summary: "Shares of " + ticker + " rose."}}, nil
+}
+
+// blockingSummarizer tracks how many prompts run at once and waits for
+// release before answering.
+type blockingSummarizer struct {
+	mu      sync.Mutex
+	running int
+	peak    int
+	release chan struct{}
+}
+
+func (s *blockingSummarizer) Generate(ctx context.Context, prompt string) (string, error) {
+	s.mu.Lock()
+	s.running++
+	if s.running > s.peak {
+		s.peak = s.running
+	}
+	s.mu.Unlock()
+
+	if s.release != nil {
+		<-s.release
+	}
+
+	s.mu.Lock()
+	s.running--
+	s.mu.Unlock()
+	return "summary", nil
+}
+
+func (s *blockingSummarizer) Model() string { return "test-model" }
+
+func waitForStatus(t *testing.T, q *Queue, id string, status Status) Job {
+	var job Job
+	require.Eventually(t, func() bool {
+		job, _ = q.Get(id)
+		return job.Status == status
+	}, time.Second, 5*time.Millisecond)
+	return job
+}
+
+func TestQueueRunsJobs(t *testing.T) {
+	summaries := ai.NewSummaryService(&blockingSummarizer{}, nil, ai.SummaryOptions{})
+	q := NewQueue(fakeProvider{}, summaries, Options{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q.Start(ctx)
+
+	job, err := q.Submit(Request{Tickers: []string{"AAPL", "MISSING"}, Style: ai.DefaultStyle})
+	require.NoError(t, err)
+	assert.Equal(t, StatusQueued, job.Status)
+	assert.Len(t, job.ID, 32)
+
+	job = waitForStatus(t, q, job.ID, StatusDone)
+	require.Len(t, job.Results, 2)
+	assert.Equal(t, "summary", job.Results[0].Summary)
+	assert.Equal(t, "summary-detailed@v2", job.Results[0].PromptVersion)
+	assert.Equal(t, 1, job.Results[0].ArticlesTotal)
+	assert.Equal(t, "MISSING", job.Results[1].Ticker)
+	assert.Equal(t, apperrors.ErrNotFound.Error(), job.Results[1].Error)
+	assert.NotNil(t, job.StartedAt)
+	assert.NotNil(t, job.FinishedAt)
+
+	failed, err := q.Submit(Request{Tickers: []string{"MISSING"}})
+	require.NoError(t, err)
+	waitForStatus(t, q, failed.ID, StatusFailed)
+
+	_, ok := q.Get("unknown")
+	assert.False(t, ok)
+}
+
+func TestQueueLimitsConcurrency(t *testing.T) {
+	summarizer := &blockingSummarizer{release: make(chan struct{})}
+	summaries := ai.NewSummaryService(summarizer, nil, ai.SummaryOptions{Concurrency: 2})
+	q := NewQueue(fakeProvider{}, summaries, Options{Workers: 2, QueueSize: 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q.Start(ctx)
+
+	var ids []string
+	for i := 0; i < 2; i++ {
+		job, err := q.Submit(Request{Tickers: []string{"AAPL"}})
+		require.NoError(t, err)
+		ids = append(ids, job.ID)
+		waitForStatus(t, q, job.ID, StatusRunning)
+	}
+
+	// Both workers are busy and the queue holds one more job.
+	queued, err := q.Submit(Request{Tickers: []string{"AAPL"}})
+	require.NoError(t, err)
+	_, err = q.Submit(Request{Tickers: []string{"AAPL"}})
+	assert.ErrorIs(t, err, ErrQueueFull)
+
+	close(summarizer.release)
+	for _, id := range append(ids, queued.ID) {
+		waitForStatus(t, q, id, StatusDone)
+	}
+
+	summarizer.mu.Lock()
+	defer summarizer.mu.Unlock()
+	assert.Equal(t, 2, summarizer.peak)
+}
+
+func TestQueueEvictsFinishedJobs(t *testing.T) {
+	summaries := ai.NewSummaryService(&blockingSummarizer{}, nil, ai.SummaryOptions{})
+	q := NewQueue(fakeProvider{}, summaries, Options{Retention: 50 * time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q.Start(ctx)
+
+	job, err := q.Submit(Request{Tickers: []string{"AAPL"}})
+	require.NoError(t, err)
+	waitForStatus(t, q, job.ID, StatusDone)
+
+	// Finished jobs go away without another Submit.
+	require.Eventually(t, func() bool {
+		_, ok := q.Get(job.ID)
+		return !ok
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestQueueSharesModelSlotsWithInlineSummaries(t *testing.T) {
+	summarizer := &blockingSummarizer{release: make(chan struct{})}
+	summaries := ai.NewSummaryService(summarizer, nil, ai.SummaryOptions{})
+	q := NewQueue(fakeProvider{}, summaries, Options{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q.Start(ctx)
+
+	job, err := q.Submit(Request{Tickers: []string{"AAPL"}})
+	require.NoError(t, err)
+	waitForStatus(t, q, job.ID, StatusRunning)
+
+	// A summary requested outside the queue waits for the worker's slot.
+	inline := make(chan error, 1)
+	go func() {
+		articles, _ := fakeProvider{}.GetNewsByTicker(ctx, "TSLA")
+		_, err := summaries.Summarize(ctx, ai.SummaryRequest{Ticker: "TSLA", Articles: articles})
+		inline <- err
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	close(summarizer.release)
+	require.NoError(t, <-inline)
+	waitForStatus(t, q, job.ID, StatusDone)
+
+	summarizer.mu.Lock()
+	defer summarizer.mu.Unlock()
+	assert.Equal(t, 1, summarizer.peak)
+}
@@ -1,6 +1,17 @@
 package models
 
-import "encoding/json"
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// PublishedLayout is the timestamp layout used by AlphaVantage for time_published.
+const PublishedLayout = "20060102T150405"
 
 type Article struct {
 	Title       string   `json:"title"`
@@ -11,6 +22,95 @@ type Article struct {
 	Source      string   `json:"source"`
 	Sentiment   string   `json:"overall_sentiment_label"`
 	Tickers     []string `json:"tickers"`
+	Score       float64  `json:"score,omitempty"`
+
+	SentimentScore float64 `json:"overall_sentiment_score,omitempty"`
+
+	// TickerConfidence is set when tickers were found in the article text,
+	// and maps every ticker to how sure the match is (provider tags are 1).
+	TickerConfidence map[string]float64 `json:"ticker_confidence,omitempty"`
+}
+
+// PublishedTime parses PublishedAt. The second return value is false when the
+// article has no timestamp or it is in an unknown format.
+func (a Article) PublishedTime() (time.Time, bool) {
+	if a.PublishedAt == "" {
+		return time.Time{}, false
+	}
+
+	t, err := time.Parse(PublishedLayout, a.PublishedAt)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+// ID returns a stable identifier for the article derived from its URL, or from
+// its title and publish time when the provider gave no URL.
+func (a Article) ID() string {
+	key := a.URL
+	if key == "" {
+		key = a.Title + "|" + a.PublishedAt
+	}
+	sum := sha1.Sum([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// ProjectArticles reduces each article to the requested JSON fields.
+func ProjectArticles(articles []Article, fields []string) ([]map[string]interface{}, error) {
+	if err := ValidateArticleFields(fields); err != nil {
+		return nil, err
+	}
+
+	projected := make([]map[string]interface{}, 0, len(articles))
+	for _, a := range articles {
+		data, err := json.Marshal(a)
+		if err != nil {
+			return nil, err
+		}
+
+		var full map[string]interface{}
+		if err := json.Unmarshal(data, &full); err != nil {
+			return nil, err
+		}
+
+		item := make(map[string]interface{}, len(fields))
+		for _, f := range fields {
+			if f == "id" {
+				item[f] = a.ID()
+			} else if v, ok := full[f]; ok {
+				item[f] = v
+			}
+		}
+		projected = append(projected, item)
+	}
+
+	return projected, nil
+}
+
+// ValidateArticleFields checks that every name is a JSON field of Article.
+func ValidateArticleFields(fields []string) error {
+	for _, f := range fields {
+		if !isArticleField(f) {
+			return fmt.Errorf("unknown article field %q", f)
+		}
+	}
+	return nil
+}
+
+func isArticleField(name string) bool {
+	if name == "id" {
+		return true
+	}
+	t := reflect.TypeOf(Article{})
+	for i := 0; i < t.NumField(); i++ {
+		tag := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if tag == name {
+			return true
+		}
+	}
+	return false
 }
 
 func MarshalArticles(articles []Article) ([]byte, error) {
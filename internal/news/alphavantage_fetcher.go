@@ -2,13 +2,19 @@ package news
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/akhlexe/stocknews-api/internal/apperrors"
 	"github.com/akhlexe/stocknews-api/internal/cache"
 	"github.com/akhlexe/stocknews-api/internal/models"
 	"github.com/rs/zerolog/log"
 )
 
+// AlphaVantageProvider names AlphaVantage in fetch reports.
+const AlphaVantageProvider = "alphavantage"
+
 type AlphaVantageFetcher struct {
 	apiKey string
 	cache  *cache.PersistentCache
@@ -22,8 +28,13 @@ func NewAlphaVantageFetcher(apiKey string, cache *cache.PersistentCache) *AlphaV
 }
 
 func (f *AlphaVantageFetcher) GetNewsByTicker(ctx context.Context, ticker string) ([]models.Article, error) {
-	if cached, ok := f.cache.GetArticles(ctx, ticker); ok {
-		return cached, nil
+	if cached, ok := f.cache.LookupArticles(ctx, ticker); ok {
+		status := CacheMemory
+		if cached.Source == cache.SourceStorage {
+			status = CacheStorage
+		}
+		RecordFetch(ctx, Fetch{Provider: AlphaVantageProvider, Status: status, FetchedAt: cached.FetchedAt, Expires: cached.Expiration})
+		return cached.Articles, nil
 	}
 
 	log.Info().
@@ -34,10 +45,20 @@ func (f *AlphaVantageFetcher) GetNewsByTicker(ctx context.Context, ticker string
 	resp, err := GetNewsByTicker(ctx, f.apiKey, ticker)
 
 	if err != nil {
+		// Serve expired articles rather than nothing while AlphaVantage is down.
+		if !errors.Is(err, apperrors.ErrNotFound) {
+			if stale, ok := f.cache.StaleArticles(ticker); ok {
+				log.Warn().Err(err).Str("ticker", ticker).Msg("Serving stale articles")
+				RecordFetch(ctx, Fetch{Provider: AlphaVantageProvider, Status: CacheStale, FetchedAt: stale.FetchedAt, Expires: stale.Expiration})
+				return stale.Articles, nil
+			}
+		}
 		return nil, fmt.Errorf("error fetching news: %w", err)
 	}
 
 	f.cache.SetArticles(ctx, ticker, resp)
+	now := time.Now()
+	RecordFetch(ctx, Fetch{Provider: AlphaVantageProvider, Status: CacheUpstream, FetchedAt: now, Expires: now.Add(f.cache.TTL())})
 
 	return resp, nil
 }
@@ -15,13 +15,14 @@ import (
 type apiResponse struct {
 	Items string `json:"items"`
 	Feed  []struct {
-		Title       string `json:"title"`
-		URL         string `json:"url"`
-		Summary     string `json:"summary"`
-		BannerImage string `json:"banner_image"`
-		Time        string `json:"time_published"`
-		Source      string `json:"source"`
-		Sentiment   string `json:"overall_sentiment_label"`
+		Title       string  `json:"title"`
+		URL         string  `json:"url"`
+		Summary     string  `json:"summary"`
+		BannerImage string  `json:"banner_image"`
+		Time        string  `json:"time_published"`
+		Source      string  `json:"source"`
+		Sentiment   string  `json:"overall_sentiment_label"`
+		Score       float64 `json:"overall_sentiment_score"`
 		TickerData  []struct {
 			Ticker string `json:"ticker"`
 		} `json:"ticker_sentiment"`
@@ -101,6 +102,8 @@ func GetNewsByTicker(ctx context.Context, apiKey string, ticker string) ([]model
 			Source:      item.Source,
 			Sentiment:   item.Sentiment,
 			Tickers:     tickers,
+
+			SentimentScore: item.Score,
 		})
 	}
 
@@ -6,8 +6,25 @@ import (
 	"github.com/akhlexe/stocknews-api/internal/models"
 )
 
+// Enricher adds derived data to fetched articles, such as sentiment for
+// providers that don't supply it.
+type Enricher interface {
+	Enrich(articles []models.Article) []models.Article
+}
+
+// Observer is told about every article set fetched for a ticker, after
+// enrichment.
+type Observer interface {
+	Observe(ticker string, articles []models.Article)
+}
+
 type MultiFetcher struct {
 	Providers []Provider
+
+	// Enrichers run in order over every fetched article set.
+	Enrichers []Enricher
+
+	Observers []Observer
 }
 
 func NewMultiFetcher(providers ...Provider) *MultiFetcher {
@@ -27,5 +44,13 @@ func (m *MultiFetcher) GetNewsByTicker(ctx context.Context, ticker string) ([]mo
 		allArticles = append(allArticles, articles...)
 	}
 
+	for _, enricher := range m.Enrichers {
+		allArticles = enricher.Enrich(allArticles)
+	}
+
+	for _, observer := range m.Observers {
+		observer.Observe(ticker, allArticles)
+	}
+
 	return allArticles, nil
 }
@@ -0,0 +1,153 @@
+package news
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CacheStatus says where a provider's articles were served from.
+type CacheStatus string
+
+const (
+	CacheMemory   CacheStatus = "memory"
+	CacheStorage  CacheStatus = "storage"
+	CacheUpstream CacheStatus = "upstream"
+
+	// CacheStale means the upstream fetch failed and expired articles were
+	// served instead.
+	CacheStale CacheStatus = "stale"
+)
+
+// cacheStatusRank orders statuses from freshest to most degraded.
+var cacheStatusRank = map[CacheStatus]int{
+	CacheMemory:   0,
+	CacheStorage:  1,
+	CacheUpstream: 2,
+	CacheStale:    3,
+}
+
+// Fetch is how one provider served one ticker.
+type Fetch struct {
+	Provider string
+	Status   CacheStatus
+
+	// FetchedAt is when the articles were fetched from upstream.
+	FetchedAt time.Time
+
+	// Expires is when the provider will fetch them again.
+	Expires time.Time
+}
+
+// FetchReport collects how providers served a request. Attach one to the
+// context with WithFetchReport; providers fill it in with RecordFetch.
+type FetchReport struct {
+	mu      sync.Mutex
+	fetches []Fetch
+}
+
+type fetchReportKey struct{}
+
+// WithFetchReport returns a context carrying a new, empty FetchReport.
+func WithFetchReport(ctx context.Context) (context.Context, *FetchReport) {
+	report := &FetchReport{}
+	return context.WithValue(ctx, fetchReportKey{}, report), report
+}
+
+// RecordFetch adds fetch to the context's FetchReport, if it has one.
+func RecordFetch(ctx context.Context, fetch Fetch) {
+	report, ok := ctx.Value(fetchReportKey{}).(*FetchReport)
+	if !ok {
+		return
+	}
+
+	report.mu.Lock()
+	defer report.mu.Unlock()
+	report.fetches = append(report.fetches, fetch)
+}
+
+// Providers lists the providers that contributed articles, sorted.
+func (r *FetchReport) Providers() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := make(map[string]bool)
+	providers := []string{}
+	for _, fetch := range r.fetches {
+		if !seen[fetch.Provider] {
+			seen[fetch.Provider] = true
+			providers = append(providers, fetch.Provider)
+		}
+	}
+	sort.Strings(providers)
+	return providers
+}
+
+// Status is the most degraded status across providers, so a response is only
+// reported as served from memory when every provider served it from memory.
+// It is CacheUpstream when no provider reported.
+func (r *FetchReport) Status() CacheStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.fetches) == 0 {
+		return CacheUpstream
+	}
+
+	status := CacheMemory
+	for _, fetch := range r.fetches {
+		if cacheStatusRank[fetch.Status] > cacheStatusRank[status] {
+			status = fetch.Status
+		}
+	}
+	return status
+}
+
+// FetchedAt is the oldest fetch time across providers, or zero when no
+// provider reported.
+func (r *FetchReport) FetchedAt() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var oldest time.Time
+	for _, fetch := range r.fetches {
+		if oldest.IsZero() || fetch.FetchedAt.Before(oldest) {
+			oldest = fetch.FetchedAt
+		}
+	}
+	return oldest
+}
+
+// LastFetchedAt is the newest fetch time across providers, or zero when no
+// provider reported.
+func (r *FetchReport) LastFetchedAt() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var newest time.Time
+	for _, fetch := range r.fetches {
+		if fetch.FetchedAt.After(newest) {
+			newest = fetch.FetchedAt
+		}
+	}
+	return newest
+}
+
+// Expires is the earliest expiry across providers, or zero when no provider
+// reported one.
+func (r *FetchReport) Expires() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var earliest time.Time
+	for _, fetch := range r.fetches {
+		if fetch.Expires.IsZero() {
+			continue
+		}
+		if earliest.IsZero() || fetch.Expires.Before(earliest) {
+			earliest = fetch.Expires
+		}
+	}
+	return earliest
+}
@@ -0,0 +1,160 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrInvalidLimit = errors.New("invalid rate limit")
+
+// Limit allows Requests per Window. A zero Limit allows everything.
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// Unlimited reports whether the limit allows everything.
+func (l Limit) Unlimited() bool {
+	return l.Requests <= 0 || l.Window <= 0
+}
+
+func (l Limit) String() string {
+	if l.Unlimited() {
+		return "off"
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, l.Window)
+}
+
+// ParseLimit parses "<requests>/<window>", e.g. "60/1m", or "off".
+func ParseLimit(value string) (Limit, error) {
+	value = strings.TrimSpace(value)
+	if strings.EqualFold(value, "off") {
+		return Limit{}, nil
+	}
+
+	requests, window, ok := strings.Cut(value, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("%w: %q", ErrInvalidLimit, value)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(requests))
+	if err != nil || n < 1 {
+		return Limit{}, fmt.Errorf("%w: %q", ErrInvalidLimit, value)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(window))
+	if err != nil || d < time.Second {
+		return Limit{}, fmt.Errorf("%w: %q", ErrInvalidLimit, value)
+	}
+	return Limit{Requests: n, Window: d}, nil
+}
+
+// Store counts requests per key in fixed windows.
+type Store interface {
+	// IncrementRateLimit adds one to the count of key in the window starting
+	// at window and returns the new count. The count may be dropped after
+	// expiresAt.
+	IncrementRateLimit(ctx context.Context, key string, window time.Time, expiresAt time.Time) (int64, error)
+}
+
+// Result describes the state of a key's window after a request.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// RetryAfter is how long until the window resets.
+func (r Result) RetryAfter(now time.Time) time.Duration {
+	return r.Reset.Sub(now)
+}
+
+// Limiter enforces limits with fixed windows aligned to the Unix epoch, so
+// every replica sharing a store agrees on when a window starts.
+type Limiter struct {
+	store Store
+}
+
+func NewLimiter(store Store) *Limiter {
+	return &Limiter{store: store}
+}
+
+// Allow counts a request for key against limit.
+func (l *Limiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	return l.allow(ctx, key, limit, time.Now())
+}
+
+func (l *Limiter) allow(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	if limit.Unlimited() {
+		return Result{Allowed: true}, nil
+	}
+
+	window := now.Truncate(limit.Window)
+	reset := window.Add(limit.Window)
+
+	count, err := l.store.IncrementRateLimit(ctx, key, window, reset)
+	if err != nil {
+		return Result{}, err
+	}
+
+	remaining := limit.Requests - int(count)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return Result{
+		Allowed:   count <= int64(limit.Requests),
+		Limit:     limit.Requests,
+		Remaining: remaining,
+		Reset:     reset,
+	}, nil
+}
+
+type counter struct {
+	window    time.Time
+	count     int64
+	expiresAt time.Time
+}
+
+// MemoryStore keeps counts in process. Limits then hold per replica.
+type MemoryStore struct {
+	mu        sync.Mutex
+	counters  map[string]*counter
+	lastPrune time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{counters: make(map[string]*counter)}
+}
+
+func (s *MemoryStore) IncrementRateLimit(ctx context.Context, key string, window time.Time, expiresAt time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(window)
+
+	c, ok := s.counters[key]
+	if !ok || !c.window.Equal(window) {
+		c = &counter{window: window}
+		s.counters[key] = c
+	}
+	c.count++
+	c.expiresAt = expiresAt
+	return c.count, nil
+}
+
+// prune drops expired counters at most once a minute. The caller holds mu.
+func (s *MemoryStore) prune(now time.Time) {
+	if now.Sub(s.lastPrune) < time.Minute {
+		return
+	}
+	s.lastPrune = now
+	for key, c := range s.counters {
+		if !c.expiresAt.After(now) {
+			delete(s.counters, key)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	limit, err := ParseLimit("60/1m")
+	require.NoError(t, err)
+	assert.Equal(t, Limit{Requests: 60, Window: time.Minute}, limit)
+
+	limit, err = ParseLimit("off")
+	require.NoError(t, err)
+	assert.True(t, limit.Unlimited())
+
+	for _, value := range []string{"", "60", "0/1m", "x/1m", "60/soon", "60/10ms"} {
+		_, err := ParseLimit(value)
+		assert.ErrorIs(t, err, ErrInvalidLimit, value)
+	}
+}
+
+func TestLimiterFixedWindow(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewLimiter(NewMemoryStore())
+	limit := Limit{Requests: 2, Window: time.Minute}
+	now := time.Date(2025, 1, 2, 12, 0, 15, 0, time.UTC)
+
+	result, err := limiter.allow(ctx, "news:ip:1.2.3.4", limit, now)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 1, Reset: now.Add(45 * time.Second)}, result)
+
+	result, err = limiter.allow(ctx, "news:ip:1.2.3.4", limit, now)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	result, err = limiter.allow(ctx, "news:ip:1.2.3.4", limit, now.Add(10*time.Second))
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 35*time.Second, result.RetryAfter(now.Add(10*time.Second)))
+
+	result, err = limiter.allow(ctx, "news:ip:5.6.7.8", limit, now)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed, "clients are counted separately")
+
+	result, err = limiter.allow(ctx, "news:ip:1.2.3.4", limit, now.Add(45*time.Second))
+	require.NoError(t, err)
+	assert.True(t, result.Allowed, "the next window starts over")
+}
+
+func TestLimiterUnlimited(t *testing.T) {
+	limiter := NewLimiter(failingStore{})
+
+	result, err := limiter.Allow(context.Background(), "news:ip:1.2.3.4", Limit{})
+
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+}
+
+type failingStore struct{}
+
+func (failingStore) IncrementRateLimit(ctx context.Context, key string, window time.Time, expiresAt time.Time) (int64, error) {
+	return 0, errors.New("unavailable")
+}
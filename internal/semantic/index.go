@@ -0,0 +1,162 @@
+package semantic
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+)
+
+// Match is an article found by a vector search. Similarity is the cosine
+// similarity to the query, from -1 to 1.
+type Match struct {
+	Article    models.Article
+	Similarity float64
+}
+
+type entry struct {
+	article models.Article
+	vector  []float32
+	added   time.Time
+}
+
+// Index is an in-process vector index searched by brute force, which is fast
+// enough for the tens of thousands of articles a single instance caches.
+type Index struct {
+	mu      sync.RWMutex
+	entries map[string]entry
+}
+
+func NewIndex() *Index {
+	return &Index{entries: make(map[string]entry)}
+}
+
+// Add stores an article under its ID, replacing any previous vector.
+func (i *Index) Add(article models.Article, vector []float32) {
+	i.add(article, vector, time.Now())
+}
+
+func (i *Index) add(article models.Article, vector []float32, added time.Time) {
+	normalized := normalize(vector)
+
+	i.mu.Lock()
+	i.entries[article.ID()] = entry{article: article, vector: normalized, added: added}
+	i.mu.Unlock()
+}
+
+// Prune drops the articles added before cutoff and returns how many it dropped.
+func (i *Index) Prune(cutoff time.Time) int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	pruned := 0
+	for id, e := range i.entries {
+		if e.added.Before(cutoff) {
+			delete(i.entries, id)
+			pruned++
+		}
+	}
+	return pruned
+}
+
+func (i *Index) Has(id string) bool {
+	i.mu.RLock()
+	_, ok := i.entries[id]
+	i.mu.RUnlock()
+	return ok
+}
+
+func (i *Index) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.entries)
+}
+
+// Search returns up to k articles closest to vector. When ticker is set only
+// articles tagged with it are considered.
+func (i *Index) Search(vector []float32, k int, ticker string) []Match {
+	query := normalize(vector)
+
+	i.mu.RLock()
+	matches := make([]Match, 0, len(i.entries))
+	for _, e := range i.entries {
+		if len(e.vector) != len(query) {
+			continue
+		}
+		if ticker != "" && !hasTicker(e.article, ticker) {
+			continue
+		}
+		matches = append(matches, Match{Article: e.article, Similarity: dot(query, e.vector)})
+	}
+	i.mu.RUnlock()
+
+	sort.Slice(matches, func(a, b int) bool {
+		if matches[a].Similarity != matches[b].Similarity {
+			return matches[a].Similarity > matches[b].Similarity
+		}
+		return matches[a].Article.ID() < matches[b].Article.ID()
+	})
+
+	if k > 0 && len(matches) > k {
+		matches = matches[:k]
+	}
+	return matches
+}
+
+func hasTicker(a models.Article, ticker string) bool {
+	for _, t := range a.Tickers {
+		if t == ticker {
+			return true
+		}
+	}
+	return false
+}
+
+func normalize(v []float32) []float32 {
+	norm := 0.0
+	for _, x := range v {
+		norm += float64(x) * float64(x)
+	}
+	norm = math.Sqrt(norm)
+
+	normalized := make([]float32, len(v))
+	if norm == 0 {
+		return normalized
+	}
+	for i, x := range v {
+		normalized[i] = float32(float64(x) / norm)
+	}
+	return normalized
+}
+
+func dot(a, b []float32) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+// encodeVector packs a vector as little-endian float32s for storage.
+func encodeVector(v []float32) []byte {
+	data := make([]byte, 4*len(v))
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(x))
+	}
+	return data
+}
+
+func decodeVector(data []byte) ([]float32, error) {
+	if len(data)%4 != 0 {
+		return nil, fmt.Errorf("invalid vector length %d", len(data))
+	}
+	v := make([]float32, len(data)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return v, nil
+}
@@ -0,0 +1,204 @@
+package semantic
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/ai"
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/akhlexe/stocknews-api/internal/storage"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultQueueSize bounds the number of articles waiting to be embedded.
+	DefaultQueueSize = 1000
+
+	// Retention is how long an embedded article stays searchable. Older news
+	// rarely answers a question, and without it the index and the stored
+	// embeddings would only grow.
+	Retention = 30 * 24 * time.Hour
+
+	pruneInterval = time.Hour
+)
+
+// Store persists embeddings so the index survives restarts.
+type Store interface {
+	SaveEmbedding(ctx context.Context, articleID string, model string, article []byte, vector []byte) error
+	GetEmbeddings(ctx context.Context, model string) ([]storage.Embedding, error)
+	DeleteEmbeddings(ctx context.Context, before time.Time) error
+}
+
+// Service embeds fetched articles in the background and answers semantic
+// queries from an in-process index.
+type Service struct {
+	embedder ai.Embedder
+	store    Store
+	index    *Index
+
+	queue     chan models.Article
+	mu        sync.Mutex
+	queued    map[string]bool
+	retention time.Duration
+}
+
+// NewService creates a Service. store may be nil to keep vectors in memory only.
+func NewService(embedder ai.Embedder, store Store, queueSize int) *Service {
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+
+	return &Service{
+		embedder:  embedder,
+		store:     store,
+		index:     NewIndex(),
+		queue:     make(chan models.Article, queueSize),
+		queued:    make(map[string]bool),
+		retention: Retention,
+	}
+}
+
+// Load fills the index with the vectors stored for the embedding model,
+// leaving out those past Retention.
+func (s *Service) Load(ctx context.Context) error {
+	if s.store == nil {
+		return nil
+	}
+
+	embeddings, err := s.store.GetEmbeddings(ctx, s.embedder.Model())
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-s.retention)
+	for _, e := range embeddings {
+		if e.CreatedAt.Before(cutoff) {
+			continue
+		}
+		var article models.Article
+		if err := json.Unmarshal(e.Article, &article); err != nil {
+			log.Warn().Err(err).Str("article_id", e.ArticleID).Msg("Skipping stored embedding with invalid article")
+			continue
+		}
+		vector, err := decodeVector(e.Vector)
+		if err != nil {
+			log.Warn().Err(err).Str("article_id", e.ArticleID).Msg("Skipping invalid stored embedding")
+			continue
+		}
+		s.index.add(article, vector, e.CreatedAt)
+	}
+
+	log.Info().Int("count", s.index.Len()).Str("model", s.embedder.Model()).Msg("Loaded article embeddings")
+	return nil
+}
+
+// Start embeds queued articles, and drops those past Retention every hour,
+// until ctx is done.
+func (s *Service) Start(ctx context.Context) {
+	go func() {
+		prune := time.NewTicker(pruneInterval)
+		defer prune.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-prune.C:
+				s.prune(ctx, time.Now())
+			case article := <-s.queue:
+				if err := s.Index(ctx, article); err != nil {
+					log.Warn().Err(err).Str("article_id", article.ID()).Msg("Failed to embed article")
+				}
+
+				s.mu.Lock()
+				delete(s.queued, article.ID())
+				s.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// Enrich queues articles that are not indexed yet and returns them unchanged,
+// so fetching never waits for the embedding model. Articles that don't fit in
+// the queue are picked up the next time they are fetched.
+func (s *Service) Enrich(articles []models.Article) []models.Article {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, a := range articles {
+		id := a.ID()
+		if s.queued[id] || s.index.Has(id) {
+			continue
+		}
+
+		select {
+		case s.queue <- a:
+			s.queued[id] = true
+		default:
+			log.Debug().Msg("Embedding queue is full")
+			return articles
+		}
+	}
+
+	return articles
+}
+
+// prune drops articles embedded before now minus the retention from the index
+// and the store. Articles still being fetched are embedded again.
+func (s *Service) prune(ctx context.Context, now time.Time) {
+	cutoff := now.Add(-s.retention)
+
+	if pruned := s.index.Prune(cutoff); pruned > 0 {
+		log.Info().Int("count", pruned).Msg("Pruned old article embeddings")
+	}
+
+	if s.store != nil {
+		if err := s.store.DeleteEmbeddings(ctx, cutoff); err != nil {
+			log.Error().Err(err).Msg("Failed to delete old embeddings from storage")
+		}
+	}
+}
+
+// Index embeds an article and adds it to the index and the store.
+func (s *Service) Index(ctx context.Context, article models.Article) error {
+	vector, err := s.embedder.Embed(ctx, ai.EmbeddingText(article))
+	if err != nil {
+		return err
+	}
+
+	s.index.Add(article, vector)
+
+	if s.store != nil {
+		data, err := json.Marshal(article)
+		if err != nil {
+			return err
+		}
+		if err := s.store.SaveEmbedding(ctx, article.ID(), s.embedder.Model(), data, encodeVector(vector)); err != nil {
+			log.Error().Err(err).Str("article_id", article.ID()).Msg("Error saving embedding to storage")
+		}
+	}
+
+	return nil
+}
+
+// Search returns up to k indexed articles closest in meaning to query, with
+// the cosine similarity in Score. ticker optionally restricts the results.
+func (s *Service) Search(ctx context.Context, query string, k int, ticker string) ([]models.Article, error) {
+	vector, err := s.embedder.Embed(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := s.index.Search(vector, k, ticker)
+	articles := make([]models.Article, 0, len(matches))
+	for _, m := range matches {
+		a := m.Article
+		a.Score = math.Round(m.Similarity*10000) / 10000
+		articles = append(articles, a)
+	}
+
+	return articles, nil
+}
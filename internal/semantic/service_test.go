@@ -0,0 +1,177 @@
+package semantic
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/akhlexe/stocknews-api/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// conceptEmbedder maps words onto a few hand-picked concepts, standing in for
+// a model that knows "shortage" and "supply chain" are related.
+type conceptEmbedder struct {
+	mu    sync.Mutex
+	calls int
+}
+
+var concepts = [][]string{
+	{"supply", "chain", "shortage", "shortages", "logistics", "bottleneck"},
+	{"chips", "chip", "semiconductor", "semiconductors", "foundry"},
+	{"earnings", "revenue", "profit", "quarter"},
+	{"car", "cars", "vehicle", "vehicles", "recall"},
+}
+
+func (e *conceptEmbedder) Model() string { return "concepts" }
+
+func (e *conceptEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	e.mu.Lock()
+	e.calls++
+	e.mu.Unlock()
+
+	vector := make([]float32, len(concepts))
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		for i, concept := range concepts {
+			for _, c := range concept {
+				if word == c {
+					vector[i]++
+				}
+			}
+		}
+	}
+	return vector, nil
+}
+
+type memoryStore struct {
+	mu         sync.Mutex
+	embeddings []storage.Embedding
+}
+
+func (s *memoryStore) SaveEmbedding(ctx context.Context, articleID string, model string, article []byte, vector []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.embeddings = append(s.embeddings, storage.Embedding{ArticleID: articleID, Article: article, Vector: vector, CreatedAt: time.Now()})
+	return nil
+}
+
+func (s *memoryStore) DeleteEmbeddings(ctx context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.embeddings[:0]
+	for _, e := range s.embeddings {
+		if !e.CreatedAt.Before(before) {
+			kept = append(kept, e)
+		}
+	}
+	s.embeddings = kept
+	return nil
+}
+
+func (s *memoryStore) GetEmbeddings(ctx context.Context, model string) ([]storage.Embedding, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.embeddings, nil
+}
+
+var testArticles = []models.Article{
+	{Title: "Foundry bottleneck", Summary: "Semiconductor shortages hit output", URL: "https://example.com/1", Tickers: []string{"TSM"}},
+	{Title: "Quarterly earnings", Summary: "Revenue and profit rose this quarter", URL: "https://example.com/2", Tickers: []string{"AAPL"}},
+	{Title: "Vehicle recall", Summary: "Cars recalled over brakes", URL: "https://example.com/3", Tickers: []string{"TSLA"}},
+}
+
+func TestSearchFindsRelatedArticles(t *testing.T) {
+	service := NewService(&conceptEmbedder{}, nil, 0)
+	for _, a := range testArticles {
+		require.NoError(t, service.Index(context.Background(), a))
+	}
+
+	results, err := service.Search(context.Background(), "supply chain problems in chips", 2, "")
+	require.NoError(t, err)
+
+	require.Len(t, results, 2)
+	assert.Equal(t, "Foundry bottleneck", results[0].Title)
+	assert.Greater(t, results[0].Score, 0.9)
+	assert.Equal(t, 0.0, results[1].Score)
+
+	results, err = service.Search(context.Background(), "supply chain problems in chips", 10, "TSLA")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Vehicle recall", results[0].Title)
+}
+
+func TestEnrichEmbedsInBackground(t *testing.T) {
+	embedder := &conceptEmbedder{}
+	store := &memoryStore{}
+	service := NewService(embedder, store, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	service.Start(ctx)
+
+	returned := service.Enrich(testArticles)
+	assert.Equal(t, testArticles, returned)
+
+	assert.Eventually(t, func() bool { return service.index.Len() == len(testArticles) }, time.Second, 5*time.Millisecond)
+
+	// Indexed articles are not embedded again.
+	service.Enrich(testArticles)
+	time.Sleep(20 * time.Millisecond)
+	embedder.mu.Lock()
+	assert.Equal(t, len(testArticles), embedder.calls)
+	embedder.mu.Unlock()
+
+	// A new service restores the index from the store.
+	restored := NewService(&conceptEmbedder{}, store, 0)
+	require.NoError(t, restored.Load(context.Background()))
+	assert.Equal(t, len(testArticles), restored.index.Len())
+
+	results, err := restored.Search(context.Background(), "car recall", 1, "")
+	require.NoError(t, err)
+	assert.Equal(t, testArticles[2].URL, results[0].URL)
+}
+
+func TestPruneDropsOldEmbeddings(t *testing.T) {
+	store := &memoryStore{}
+	service := NewService(&conceptEmbedder{}, store, 0)
+	for _, a := range testArticles {
+		require.NoError(t, service.Index(context.Background(), a))
+	}
+
+	service.prune(context.Background(), time.Now())
+	assert.Equal(t, len(testArticles), service.index.Len())
+
+	// An embedding stored before the retention is not loaded again.
+	store.embeddings[0].CreatedAt = time.Now().Add(-Retention - time.Hour)
+	restored := NewService(&conceptEmbedder{}, store, 0)
+	require.NoError(t, restored.Load(context.Background()))
+	assert.Equal(t, len(testArticles)-1, restored.index.Len())
+
+	service.prune(context.Background(), time.Now().Add(Retention+time.Hour))
+	assert.Equal(t, 0, service.index.Len())
+	assert.Empty(t, store.embeddings)
+}
+
+func TestEnrichDropsWhenQueueIsFull(t *testing.T) {
+	service := NewService(&conceptEmbedder{}, nil, 1)
+
+	service.Enrich(testArticles)
+
+	assert.Len(t, service.queue, 1)
+	assert.Len(t, service.queued, 1)
+}
+
+func TestVectorEncoding(t *testing.T) {
+	vector := []float32{0.25, -1.5, 3}
+
+	decoded, err := decodeVector(encodeVector(vector))
+	require.NoError(t, err)
+	assert.Equal(t, vector, decoded)
+
+	_, err = decodeVector([]byte{1, 2, 3})
+	assert.Error(t, err)
+}
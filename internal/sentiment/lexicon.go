@@ -0,0 +1,136 @@
+package sentiment
+
+// financeLexicon scores words as they read in market news, where "beat" and
+// "upgrade" are good and "miss" and "cut" are bad. Values range from -3 to 3.
+var financeLexicon = map[string]float64{
+	// Positive
+	"beat":                 2,
+	"beats":                2,
+	"better-than-expected": 2.5,
+	"boost":                1.5,
+	"boosts":               1.5,
+	"bullish":              2.5,
+	"buyback":              1.5,
+	"climb":                1.5,
+	"climbs":               1.5,
+	"exceed":               2,
+	"exceeded":             2,
+	"exceeds":              2,
+	"expand":               1,
+	"expands":              1,
+	"gain":                 1.5,
+	"gains":                1.5,
+	"growth":               1.5,
+	"higher":               1,
+	"jump":                 2,
+	"jumps":                2,
+	"outperform":           2,
+	"outperforms":          2,
+	"optimistic":           2,
+	"profit":               1,
+	"profitable":           1.5,
+	"rally":                2,
+	"rallies":              2,
+	"raise":                1,
+	"raises":               1,
+	"rebound":              1.5,
+	"record":               1.5,
+	"rise":                 1.5,
+	"rises":                1.5,
+	"soar":                 2.5,
+	"soars":                2.5,
+	"strong":               1.5,
+	"stronger":             1.5,
+	"surge":                2.5,
+	"surges":               2.5,
+	"surpass":              2,
+	"surpassed":            2,
+	"upbeat":               2,
+	"upgrade":              2,
+	"upgraded":             2,
+	"upgrades":             2,
+	"upside":               1.5,
+	"win":                  1.5,
+	"wins":                 1.5,
+
+	// Negative
+	"bankruptcy":          -3,
+	"bearish":             -2.5,
+	"concern":             -1.5,
+	"concerns":            -1.5,
+	"crash":               -3,
+	"cut":                 -1.5,
+	"cuts":                -1.5,
+	"decline":             -1.5,
+	"declines":            -1.5,
+	"default":             -2.5,
+	"delay":               -1,
+	"delays":              -1,
+	"downgrade":           -2,
+	"downgraded":          -2,
+	"downgrades":          -2,
+	"downside":            -1.5,
+	"drop":                -1.5,
+	"drops":               -1.5,
+	"fall":                -1.5,
+	"falls":               -1.5,
+	"fraud":               -3,
+	"investigation":       -2,
+	"lawsuit":             -2,
+	"layoffs":             -2,
+	"loss":                -1.5,
+	"lower":               -1,
+	"losses":              -1.5,
+	"miss":                -2,
+	"misses":              -2,
+	"plunge":              -2.5,
+	"plunges":             -2.5,
+	"probe":               -1.5,
+	"recall":              -1.5,
+	"recession":           -2,
+	"risk":                -1,
+	"risks":               -1,
+	"selloff":             -2,
+	"shortage":            -1.5,
+	"slump":               -2,
+	"slumps":              -2,
+	"tumble":              -2,
+	"tumbles":             -2,
+	"underperform":        -2,
+	"volatile":            -1,
+	"warning":             -1.5,
+	"weak":                -1.5,
+	"weaker":              -1.5,
+	"worse-than-expected": -2.5,
+	"writedown":           -2,
+}
+
+// negators flip the sentiment of the words that follow them.
+var negators = map[string]bool{
+	"no":      true,
+	"not":     true,
+	"never":   true,
+	"without": true,
+	"didn't":  true,
+	"doesn't": true,
+	"don't":   true,
+	"isn't":   true,
+	"wasn't":  true,
+	"won't":   true,
+	"fails":   true,
+	"failed":  true,
+}
+
+// intensifiers scale the sentiment word that follows them.
+var intensifiers = map[string]float64{
+	"sharply":       1.5,
+	"significantly": 1.5,
+	"strongly":      1.5,
+	"very":          1.3,
+	"massive":       1.5,
+	"huge":          1.5,
+	"record-high":   1.5,
+	"slightly":      0.5,
+	"modestly":      0.6,
+	"somewhat":      0.6,
+}
@@ -0,0 +1,142 @@
+package sentiment
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+)
+
+// Labels match AlphaVantage's overall_sentiment_label values.
+const (
+	LabelBearish         = "Bearish"
+	LabelSomewhatBearish = "Somewhat-Bearish"
+	LabelNeutral         = "Neutral"
+	LabelSomewhatBullish = "Somewhat-Bullish"
+	LabelBullish         = "Bullish"
+)
+
+const (
+	// negationWindow is how many following words a negator flips.
+	negationWindow = 3
+
+	// normalizeAlpha squashes the raw sum into (-1, 1); larger values need
+	// more evidence to reach the extremes.
+	normalizeAlpha = 25.0
+
+	// titleWeight counts headline words more than summary words.
+	titleWeight = 1.5
+)
+
+// Result is a sentiment score in [-1, 1] and its label.
+type Result struct {
+	Score float64
+	Label string
+}
+
+// Analyzer scores text against a lexicon. It holds no state beyond its word
+// lists, so results are deterministic and it is safe for concurrent use.
+type Analyzer struct {
+	lexicon      map[string]float64
+	negators     map[string]bool
+	intensifiers map[string]float64
+}
+
+// NewAnalyzer returns an Analyzer using the built-in finance lexicon.
+func NewAnalyzer() *Analyzer {
+	return &Analyzer{
+		lexicon:      financeLexicon,
+		negators:     negators,
+		intensifiers: intensifiers,
+	}
+}
+
+// Analyze scores a piece of text.
+func (a *Analyzer) Analyze(text string) Result {
+	return resultFor(a.sum(text, 1))
+}
+
+// AnalyzeArticle scores an article's title and summary together.
+func (a *Analyzer) AnalyzeArticle(article models.Article) Result {
+	return resultFor(a.sum(article.Title, titleWeight) + a.sum(article.Summary, 1))
+}
+
+// Enrich fills in Sentiment and SentimentScore for articles whose provider
+// gave no sentiment label. Articles that have one are left untouched.
+func (a *Analyzer) Enrich(articles []models.Article) []models.Article {
+	for i := range articles {
+		if articles[i].Sentiment != "" {
+			continue
+		}
+		result := a.AnalyzeArticle(articles[i])
+		articles[i].Sentiment = result.Label
+		articles[i].SentimentScore = result.Score
+	}
+	return articles
+}
+
+// sum adds up the lexicon values of the words in text. A negator flips the
+// next few sentiment words; an intensifier scales the word after it.
+func (a *Analyzer) sum(text string, weight float64) float64 {
+	words := tokenize(text)
+
+	total := 0.0
+	negateFor := 0
+	boost := 1.0
+
+	for _, word := range words {
+		if a.negators[word] {
+			negateFor = negationWindow
+			continue
+		}
+		if factor, ok := a.intensifiers[word]; ok {
+			boost *= factor
+			continue
+		}
+
+		if value, ok := a.lexicon[word]; ok {
+			value *= boost
+			if negateFor > 0 {
+				// Negated words are weaker than their opposites: "not bad" isn't "good".
+				value *= -0.5
+			}
+			total += value
+		}
+
+		boost = 1.0
+		if negateFor > 0 {
+			negateFor--
+		}
+	}
+
+	return total * weight
+}
+
+func resultFor(sum float64) Result {
+	score := sum / math.Sqrt(sum*sum+normalizeAlpha)
+	score = math.Round(score*1000) / 1000
+	return Result{Score: score, Label: Label(score)}
+}
+
+// Label maps a score onto AlphaVantage's label thresholds.
+func Label(score float64) string {
+	switch {
+	case score <= -0.35:
+		return LabelBearish
+	case score <= -0.15:
+		return LabelSomewhatBearish
+	case score < 0.15:
+		return LabelNeutral
+	case score < 0.35:
+		return LabelSomewhatBullish
+	default:
+		return LabelBullish
+	}
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '-' && r != '\''
+	})
+}
@@ -0,0 +1,88 @@
+package sentiment
+
+import (
+	"testing"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyze(t *testing.T) {
+	analyzer := NewAnalyzer()
+
+	testCases := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"No sentiment words", "Apple will hold its annual event in September.", LabelNeutral},
+		{"Positive", "Shares surge after earnings beat and a record buyback.", LabelBullish},
+		{"Negative", "Stock plunges on fraud investigation and guidance cut.", LabelBearish},
+		{"Mildly positive", "Revenue rises.", LabelSomewhatBullish},
+		{"Negated positive", "The company did not beat estimates.", LabelSomewhatBearish},
+		{"Negated negative flips sign", "Analysts see no recession risk.", LabelSomewhatBullish},
+		{"Failed to", "Nvidia failed to beat expectations.", LabelSomewhatBearish},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, analyzer.Analyze(tc.text).Label)
+		})
+	}
+}
+
+func TestAnalyzeIntensifiers(t *testing.T) {
+	analyzer := NewAnalyzer()
+
+	plain := analyzer.Analyze("Shares fall.").Score
+	sharp := analyzer.Analyze("Shares fall sharply.").Score
+	boosted := analyzer.Analyze("Shares sharply fall.").Score
+	slight := analyzer.Analyze("Shares slightly fall.").Score
+
+	assert.Less(t, plain, 0.0)
+	assert.Equal(t, plain, sharp, "intensifiers apply to the following word only")
+	assert.Less(t, boosted, plain)
+	assert.Greater(t, slight, plain)
+	assert.Less(t, slight, 0.0)
+}
+
+func TestAnalyzeIsDeterministic(t *testing.T) {
+	analyzer := NewAnalyzer()
+	text := "Microsoft shares jump as cloud growth beats estimates, but layoffs weigh."
+
+	first := analyzer.Analyze(text)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, first, analyzer.Analyze(text))
+	}
+}
+
+func TestAnalyzeArticleWeightsTitle(t *testing.T) {
+	analyzer := NewAnalyzer()
+
+	result := analyzer.AnalyzeArticle(models.Article{Title: "Shares tumble", Summary: "Revenue rises."})
+
+	assert.Less(t, result.Score, 0.0)
+}
+
+func TestLabel(t *testing.T) {
+	assert.Equal(t, LabelBearish, Label(-0.35))
+	assert.Equal(t, LabelSomewhatBearish, Label(-0.2))
+	assert.Equal(t, LabelNeutral, Label(-0.15+0.001))
+	assert.Equal(t, LabelNeutral, Label(0))
+	assert.Equal(t, LabelSomewhatBullish, Label(0.15))
+	assert.Equal(t, LabelBullish, Label(0.35))
+}
+
+func TestEnrich(t *testing.T) {
+	articles := []models.Article{
+		{Title: "Shares surge to a record", Sentiment: ""},
+		{Title: "Shares surge to a record", Sentiment: LabelBearish, SentimentScore: -0.5},
+	}
+
+	enriched := NewAnalyzer().Enrich(articles)
+
+	assert.Equal(t, LabelBullish, enriched[0].Sentiment)
+	assert.Greater(t, enriched[0].SentimentScore, 0.35)
+	assert.Equal(t, LabelBearish, enriched[1].Sentiment, "provider sentiment is kept")
+	assert.Equal(t, -0.5, enriched[1].SentimentScore)
+}
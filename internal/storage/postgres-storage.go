@@ -47,6 +47,98 @@ func (s *PostgresStorage) initialize() error {
 		PRIMARY KEY (ticker)
 	);
 	CREATE INDEX IF NOT EXISTS idx_expiration ON articles(expiration);
+
+	CREATE TABLE IF NOT EXISTS summaries (
+		key TEXT NOT NULL,
+		data BYTEA NOT NULL,
+		expiration TIMESTAMP NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (key)
+	);
+	CREATE INDEX IF NOT EXISTS idx_summaries_expiration ON summaries(expiration);
+
+	CREATE TABLE IF NOT EXISTS embeddings (
+		article_id TEXT NOT NULL,
+		model TEXT NOT NULL,
+		article BYTEA NOT NULL,
+		vector BYTEA NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (article_id, model)
+	);
+	CREATE INDEX IF NOT EXISTS idx_embeddings_created_at ON embeddings(created_at);
+
+	CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+		id TEXT NOT NULL,
+		data BYTEA NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (id)
+	);
+
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id TEXT NOT NULL,
+		subscription_id TEXT NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+		status TEXT NOT NULL,
+		payload BYTEA NOT NULL,
+		attempts INTEGER NOT NULL,
+		next_attempt_at TIMESTAMP NOT NULL,
+		last_error TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription ON webhook_deliveries(subscription_id, created_at);
+
+	CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+		delivery_id TEXT NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+		attempt INTEGER NOT NULL,
+		status_code INTEGER NOT NULL,
+		error TEXT NOT NULL,
+		duration_ms BIGINT NOT NULL,
+		attempted_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (delivery_id, attempt)
+	);
+
+	CREATE TABLE IF NOT EXISTS alert_rules (
+		id TEXT NOT NULL,
+		data BYTEA NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (id)
+	);
+
+	CREATE TABLE IF NOT EXISTS watchlists (
+		id TEXT NOT NULL,
+		data BYTEA NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (id)
+	);
+
+	CREATE TABLE IF NOT EXISTS api_keys (
+		id TEXT NOT NULL,
+		hash TEXT NOT NULL UNIQUE,
+		data BYTEA NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		revoked_at TIMESTAMP,
+		PRIMARY KEY (id)
+	);
+
+	CREATE TABLE IF NOT EXISTS api_key_usage (
+		key_id TEXT NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+		day DATE NOT NULL,
+		scope TEXT NOT NULL,
+		endpoint TEXT NOT NULL,
+		requests BIGINT NOT NULL,
+		PRIMARY KEY (key_id, day, scope, endpoint)
+	);
+
+	CREATE TABLE IF NOT EXISTS rate_limits (
+		key TEXT NOT NULL,
+		window_start TIMESTAMP NOT NULL,
+		count BIGINT NOT NULL,
+		expiration TIMESTAMP NOT NULL,
+		PRIMARY KEY (key, window_start)
+	);
+	CREATE INDEX IF NOT EXISTS idx_rate_limits_expiration ON rate_limits(expiration);
 	`
 
 	if _, err := s.db.Exec(query); err != nil {
@@ -79,7 +171,7 @@ func (s *PostgresStorage) GetArticles(ctx context.Context, ticker string) ([]byt
 	SELECT data, expiration FROM articles 
 	WHERE ticker = $1 AND expiration > $2
 	`
-	row := s.db.QueryRowContext(ctx, query, ticker)
+	row := s.db.QueryRowContext(ctx, query, ticker, time.Now())
 
 	var data []byte
 	var expiration time.Time
@@ -98,7 +190,602 @@ func (s *PostgresStorage) GetArticles(ctx context.Context, ticker string) ([]byt
 	return data, expiration, true, nil
 }
 
+func (s *PostgresStorage) SaveSummary(ctx context.Context, key string, summary []byte, expiration time.Time) error {
+	query := `
+	INSERT INTO summaries (key, data, expiration, created_at)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT(key)
+	DO UPDATE SET data = $2, expiration = $3, created_at = $4
+	`
+	_, err := s.db.ExecContext(ctx, query, key, summary, expiration, time.Now())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to save summary")
+		return err
+	}
+
+	log.Debug().Str("key", key).Msg("Saved summary to Postgres storage")
+	return nil
+}
+
+func (s *PostgresStorage) GetSummary(ctx context.Context, key string) ([]byte, time.Time, bool, error) {
+	query := `
+	SELECT data, expiration FROM summaries
+	WHERE key = $1 AND expiration > $2
+	`
+	row := s.db.QueryRowContext(ctx, query, key, time.Now())
+
+	var data []byte
+	var expiration time.Time
+
+	err := row.Scan(&data, &expiration)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, time.Time{}, false, nil
+		}
+		log.Error().Err(err).Msg("Failed to retrieve summary")
+		return nil, time.Time{}, false, err
+	}
+
+	return data, expiration, true, nil
+}
+
+func (s *PostgresStorage) SaveEmbedding(ctx context.Context, articleID string, model string, article []byte, vector []byte) error {
+	query := `
+	INSERT INTO embeddings (article_id, model, article, vector, created_at)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT(article_id, model)
+	DO UPDATE SET article = $3, vector = $4, created_at = $5
+	`
+	_, err := s.db.ExecContext(ctx, query, articleID, model, article, vector, time.Now())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to save embedding")
+		return err
+	}
+
+	return nil
+}
+
+func (s *PostgresStorage) GetEmbeddings(ctx context.Context, model string) ([]Embedding, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT article_id, article, vector, created_at FROM embeddings WHERE model = $1`, model)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to retrieve embeddings")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var embeddings []Embedding
+	for rows.Next() {
+		var e Embedding
+		if err := rows.Scan(&e.ArticleID, &e.Article, &e.Vector, &e.CreatedAt); err != nil {
+			log.Error().Err(err).Msg("Failed to scan embedding")
+			return nil, err
+		}
+		embeddings = append(embeddings, e)
+	}
+
+	return embeddings, rows.Err()
+}
+
+func (s *PostgresStorage) DeleteEmbeddings(ctx context.Context, before time.Time) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM embeddings WHERE created_at < $1`, before)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to delete old embeddings")
+		return err
+	}
+
+	if count, err := result.RowsAffected(); err == nil && count > 0 {
+		log.Info().Int64("count", count).Msg("Deleted old embeddings from Postgres storage")
+	}
+
+	return nil
+}
+
+func (s *PostgresStorage) SaveSubscription(ctx context.Context, id string, subscription []byte) error {
+	query := `
+	INSERT INTO webhook_subscriptions (id, data, created_at)
+	VALUES ($1, $2, $3)
+	ON CONFLICT(id)
+	DO UPDATE SET data = $2
+	`
+	_, err := s.db.ExecContext(ctx, query, id, subscription, time.Now())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to save webhook subscription")
+		return err
+	}
+
+	return nil
+}
+
+func (s *PostgresStorage) GetSubscriptions(ctx context.Context) ([][]byte, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT data FROM webhook_subscriptions ORDER BY created_at`)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to retrieve webhook subscriptions")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions [][]byte
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			log.Error().Err(err).Msg("Failed to scan webhook subscription")
+			return nil, err
+		}
+		subscriptions = append(subscriptions, data)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+func (s *PostgresStorage) DeleteSubscription(ctx context.Context, id string) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to delete webhook subscription")
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+const deliveryColumns = `id, subscription_id, status, payload, attempts, next_attempt_at, last_error, created_at, updated_at`
+
+// CreateDelivery stores a new delivery unless one with its ID exists, and
+// reports whether it did.
+func (s *PostgresStorage) CreateDelivery(ctx context.Context, d Delivery) (bool, error) {
+	query := `
+	INSERT INTO webhook_deliveries (` + deliveryColumns + `)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	ON CONFLICT(id) DO NOTHING
+	`
+	result, err := s.db.ExecContext(ctx, query, d.ID, d.SubscriptionID, d.Status, d.Payload, d.Attempts,
+		d.NextAttemptAt, d.LastError, d.CreatedAt, d.UpdatedAt)
+	if err != nil {
+		log.Error().Err(err).Str("delivery_id", d.ID).Msg("Failed to create webhook delivery")
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (s *PostgresStorage) SaveDelivery(ctx context.Context, d Delivery) error {
+	query := `
+	INSERT INTO webhook_deliveries (` + deliveryColumns + `)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	ON CONFLICT(id)
+	DO UPDATE SET status = $3, attempts = $5, next_attempt_at = $6, last_error = $7, updated_at = $9
+	`
+	_, err := s.db.ExecContext(ctx, query, d.ID, d.SubscriptionID, d.Status, d.Payload, d.Attempts,
+		d.NextAttemptAt, d.LastError, d.CreatedAt, d.UpdatedAt)
+	if err != nil {
+		log.Error().Err(err).Str("delivery_id", d.ID).Msg("Failed to save webhook delivery")
+		return err
+	}
+
+	return nil
+}
+
+func (s *PostgresStorage) GetDelivery(ctx context.Context, id string) (Delivery, bool, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE id = $1`, id)
+
+	d, err := scanDelivery(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return Delivery{}, false, nil
+		}
+		log.Error().Err(err).Msg("Failed to retrieve webhook delivery")
+		return Delivery{}, false, err
+	}
+
+	return d, true, nil
+}
+
+// ClaimDueDeliveries returns up to limit pending deliveries due at now and
+// moves their next attempt to leaseUntil, in one statement, so no other
+// process picks them up while they are attempted. A delivery whose attempt
+// never finishes is due again once the lease runs out.
+func (s *PostgresStorage) ClaimDueDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]Delivery, error) {
+	query := `
+	UPDATE webhook_deliveries SET next_attempt_at = $3
+	WHERE id IN (
+		SELECT id FROM webhook_deliveries
+		WHERE status = $1 AND next_attempt_at <= $2
+		ORDER BY next_attempt_at
+		LIMIT $4
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING ` + deliveryColumns
+	return s.queryDeliveries(ctx, query, DeliveryPending, now, leaseUntil, limit)
+}
+
+func (s *PostgresStorage) GetDeliveries(ctx context.Context, subscriptionID string, status string, limit int) ([]Delivery, error) {
+	query := `
+	SELECT ` + deliveryColumns + ` FROM webhook_deliveries
+	WHERE subscription_id = $1 AND ($2 = '' OR status = $2)
+	ORDER BY created_at DESC
+	LIMIT $3
+	`
+	return s.queryDeliveries(ctx, query, subscriptionID, status, limit)
+}
+
+func (s *PostgresStorage) queryDeliveries(ctx context.Context, query string, args ...interface{}) ([]Delivery, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to retrieve webhook deliveries")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []Delivery
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to scan webhook delivery")
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// rowScanner is a *sql.Row or *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanDelivery(row rowScanner) (Delivery, error) {
+	var d Delivery
+	err := row.Scan(&d.ID, &d.SubscriptionID, &d.Status, &d.Payload, &d.Attempts,
+		&d.NextAttemptAt, &d.LastError, &d.CreatedAt, &d.UpdatedAt)
+	return d, err
+}
+
+func (s *PostgresStorage) SaveDeliveryAttempt(ctx context.Context, a DeliveryAttempt) error {
+	query := `
+	INSERT INTO webhook_delivery_attempts (delivery_id, attempt, status_code, error, duration_ms, attempted_at)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT(delivery_id, attempt) DO NOTHING
+	`
+	_, err := s.db.ExecContext(ctx, query, a.DeliveryID, a.Attempt, a.StatusCode, a.Error,
+		a.Duration.Milliseconds(), a.AttemptedAt)
+	if err != nil {
+		log.Error().Err(err).Str("delivery_id", a.DeliveryID).Msg("Failed to save webhook delivery attempt")
+		return err
+	}
+
+	return nil
+}
+
+func (s *PostgresStorage) GetDeliveryAttempts(ctx context.Context, deliveryID string) ([]DeliveryAttempt, error) {
+	query := `
+	SELECT delivery_id, attempt, status_code, error, duration_ms, attempted_at
+	FROM webhook_delivery_attempts
+	WHERE delivery_id = $1
+	ORDER BY attempt
+	`
+	rows, err := s.db.QueryContext(ctx, query, deliveryID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to retrieve webhook delivery attempts")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attempts []DeliveryAttempt
+	for rows.Next() {
+		var a DeliveryAttempt
+		var durationMS int64
+		if err := rows.Scan(&a.DeliveryID, &a.Attempt, &a.StatusCode, &a.Error, &durationMS, &a.AttemptedAt); err != nil {
+			log.Error().Err(err).Msg("Failed to scan webhook delivery attempt")
+			return nil, err
+		}
+		a.Duration = time.Duration(durationMS) * time.Millisecond
+		attempts = append(attempts, a)
+	}
+
+	return attempts, rows.Err()
+}
+
+func (s *PostgresStorage) SaveAlertRule(ctx context.Context, id string, rule []byte) error {
+	query := `
+	INSERT INTO alert_rules (id, data, created_at)
+	VALUES ($1, $2, $3)
+	ON CONFLICT(id)
+	DO UPDATE SET data = $2
+	`
+	_, err := s.db.ExecContext(ctx, query, id, rule, time.Now())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to save alert rule")
+		return err
+	}
+
+	return nil
+}
+
+func (s *PostgresStorage) GetAlertRules(ctx context.Context) ([][]byte, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT data FROM alert_rules ORDER BY created_at`)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to retrieve alert rules")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules [][]byte
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			log.Error().Err(err).Msg("Failed to scan alert rule")
+			return nil, err
+		}
+		rules = append(rules, data)
+	}
+
+	return rules, rows.Err()
+}
+
+func (s *PostgresStorage) GetAlertRule(ctx context.Context, id string) ([]byte, bool, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx, `SELECT data FROM alert_rules WHERE id = $1`, id).Scan(&data)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, false, nil
+		}
+		log.Error().Err(err).Msg("Failed to retrieve alert rule")
+		return nil, false, err
+	}
+
+	return data, true, nil
+}
+
+func (s *PostgresStorage) DeleteAlertRule(ctx context.Context, id string) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM alert_rules WHERE id = $1`, id)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to delete alert rule")
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (s *PostgresStorage) SaveWatchlist(ctx context.Context, id string, watchlist []byte) error {
+	query := `
+	INSERT INTO watchlists (id, data, created_at)
+	VALUES ($1, $2, $3)
+	ON CONFLICT(id)
+	DO UPDATE SET data = $2
+	`
+	_, err := s.db.ExecContext(ctx, query, id, watchlist, time.Now())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to save watchlist")
+		return err
+	}
+
+	return nil
+}
+
+func (s *PostgresStorage) GetWatchlists(ctx context.Context) ([][]byte, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT data FROM watchlists ORDER BY created_at`)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to retrieve watchlists")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var watchlists [][]byte
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			log.Error().Err(err).Msg("Failed to scan watchlist")
+			return nil, err
+		}
+		watchlists = append(watchlists, data)
+	}
+
+	return watchlists, rows.Err()
+}
+
+func (s *PostgresStorage) GetWatchlist(ctx context.Context, id string) ([]byte, bool, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx, `SELECT data FROM watchlists WHERE id = $1`, id).Scan(&data)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, false, nil
+		}
+		log.Error().Err(err).Msg("Failed to retrieve watchlist")
+		return nil, false, err
+	}
+
+	return data, true, nil
+}
+
+func (s *PostgresStorage) DeleteWatchlist(ctx context.Context, id string) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM watchlists WHERE id = $1`, id)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to delete watchlist")
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+const apiKeyColumns = `id, hash, data, created_at, revoked_at`
+
+func (s *PostgresStorage) SaveAPIKey(ctx context.Context, key APIKey) error {
+	query := `
+	INSERT INTO api_keys (` + apiKeyColumns + `)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT(id)
+	DO UPDATE SET data = $3, revoked_at = $5
+	`
+	_, err := s.db.ExecContext(ctx, query, key.ID, key.Hash, key.Data, key.CreatedAt, key.RevokedAt)
+	if err != nil {
+		log.Error().Err(err).Str("key_id", key.ID).Msg("Failed to save API key")
+		return err
+	}
+
+	return nil
+}
+
+func (s *PostgresStorage) GetAPIKeyByHash(ctx context.Context, hash string) (APIKey, bool, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE hash = $1`, hash)
+
+	key, err := scanAPIKey(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return APIKey{}, false, nil
+		}
+		log.Error().Err(err).Msg("Failed to retrieve API key")
+		return APIKey{}, false, err
+	}
+
+	return key, true, nil
+}
+
+func (s *PostgresStorage) GetAPIKeys(ctx context.Context) ([]APIKey, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY created_at`)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to retrieve API keys")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to scan API key")
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (s *PostgresStorage) RevokeAPIKey(ctx context.Context, id string, at time.Time) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `UPDATE api_keys SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL`, id, at)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to revoke API key")
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func scanAPIKey(row rowScanner) (APIKey, error) {
+	var key APIKey
+	var revokedAt sql.NullTime
+	if err := row.Scan(&key.ID, &key.Hash, &key.Data, &key.CreatedAt, &revokedAt); err != nil {
+		return APIKey{}, err
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return key, nil
+}
+
+func (s *PostgresStorage) AddAPIKeyUsage(ctx context.Context, usage []APIKeyUsage) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to begin API key usage transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+	INSERT INTO api_key_usage (key_id, day, scope, endpoint, requests)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT(key_id, day, scope, endpoint)
+	DO UPDATE SET requests = api_key_usage.requests + EXCLUDED.requests
+	`
+	for _, u := range usage {
+		if _, err := tx.ExecContext(ctx, query, u.KeyID, u.Day, u.Scope, u.Endpoint, u.Requests); err != nil {
+			log.Error().Err(err).Str("key_id", u.KeyID).Msg("Failed to save API key usage")
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *PostgresStorage) GetAPIKeyUsage(ctx context.Context, keyID string, since time.Time) ([]APIKeyUsage, error) {
+	query := `
+	SELECT key_id, day, scope, endpoint, requests FROM api_key_usage
+	WHERE key_id = $1 AND day >= $2
+	ORDER BY day, scope, endpoint
+	`
+	rows, err := s.db.QueryContext(ctx, query, keyID, since)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to retrieve API key usage")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usage []APIKeyUsage
+	for rows.Next() {
+		var u APIKeyUsage
+		if err := rows.Scan(&u.KeyID, &u.Day, &u.Scope, &u.Endpoint, &u.Requests); err != nil {
+			log.Error().Err(err).Msg("Failed to scan API key usage")
+			return nil, err
+		}
+		usage = append(usage, u)
+	}
+
+	return usage, rows.Err()
+}
+
+func (s *PostgresStorage) IncrementRateLimit(ctx context.Context, key string, window time.Time, expiresAt time.Time) (int64, error) {
+	query := `
+	INSERT INTO rate_limits (key, window_start, count, expiration)
+	VALUES ($1, $2, 1, $3)
+	ON CONFLICT(key, window_start)
+	DO UPDATE SET count = rate_limits.count + 1
+	RETURNING count
+	`
+	var count int64
+	if err := s.db.QueryRowContext(ctx, query, key, window.UTC(), expiresAt.UTC()).Scan(&count); err != nil {
+		log.Error().Err(err).Msg("Failed to increment rate limit")
+		return 0, err
+	}
+
+	return count, nil
+}
+
 func (s *PostgresStorage) DeleteExpired(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM summaries WHERE expiration <= $1`, time.Now()); err != nil {
+		log.Error().Err(err).Msg("Failed to delete expired summaries")
+		return err
+	}
+
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM rate_limits WHERE expiration <= $1`, time.Now().UTC()); err != nil {
+		log.Error().Err(err).Msg("Failed to delete expired rate limit windows")
+		return err
+	}
+
 	query := `DELETE FROM articles WHERE expiration <= $1`
 
 	result, err := s.db.ExecContext(ctx, query, time.Now())
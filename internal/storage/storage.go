@@ -13,9 +13,151 @@ type Storage interface {
 	// GetArticles retrieves news articles for a ticker if not expired
 	GetArticles(ctx context.Context, ticker string) ([]byte, time.Time, bool, error)
 
-	// DeleteExpired removes expired articles from storage
+	// SaveSummary stores a generated summary under its fingerprint with expiration time
+	SaveSummary(ctx context.Context, key string, summary []byte, expiration time.Time) error
+
+	// GetSummary retrieves a summary by fingerprint if not expired
+	GetSummary(ctx context.Context, key string) ([]byte, time.Time, bool, error)
+
+	// SaveEmbedding stores an article and its embedding vector for a model
+	SaveEmbedding(ctx context.Context, articleID string, model string, article []byte, vector []byte) error
+
+	// GetEmbeddings retrieves every stored embedding for a model
+	GetEmbeddings(ctx context.Context, model string) ([]Embedding, error)
+
+	// DeleteEmbeddings removes the embeddings stored before a time, for every model
+	DeleteEmbeddings(ctx context.Context, before time.Time) error
+
+	// SaveSubscription stores a webhook subscription, replacing any with the same ID
+	SaveSubscription(ctx context.Context, id string, subscription []byte) error
+
+	// GetSubscriptions retrieves every webhook subscription
+	GetSubscriptions(ctx context.Context) ([][]byte, error)
+
+	// DeleteSubscription removes a webhook subscription and its deliveries
+	DeleteSubscription(ctx context.Context, id string) (bool, error)
+
+	// CreateDelivery stores a new webhook delivery unless its ID exists, reporting whether it did
+	CreateDelivery(ctx context.Context, delivery Delivery) (bool, error)
+
+	// SaveDelivery creates or updates a webhook delivery
+	SaveDelivery(ctx context.Context, delivery Delivery) error
+
+	// GetDelivery retrieves a webhook delivery by ID
+	GetDelivery(ctx context.Context, id string) (Delivery, bool, error)
+
+	// ClaimDueDeliveries leases pending deliveries whose next attempt is due, oldest first, until leaseUntil
+	ClaimDueDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]Delivery, error)
+
+	// GetDeliveries retrieves a subscription's most recent deliveries, optionally with one status
+	GetDeliveries(ctx context.Context, subscriptionID string, status string, limit int) ([]Delivery, error)
+
+	// SaveDeliveryAttempt logs one attempt at a webhook delivery
+	SaveDeliveryAttempt(ctx context.Context, attempt DeliveryAttempt) error
+
+	// GetDeliveryAttempts retrieves the attempts logged for a delivery, oldest first
+	GetDeliveryAttempts(ctx context.Context, deliveryID string) ([]DeliveryAttempt, error)
+
+	// SaveAlertRule stores an alert rule, replacing any with the same ID
+	SaveAlertRule(ctx context.Context, id string, rule []byte) error
+
+	// GetAlertRules retrieves every alert rule
+	GetAlertRules(ctx context.Context) ([][]byte, error)
+
+	// DeleteAlertRule removes an alert rule
+	DeleteAlertRule(ctx context.Context, id string) (bool, error)
+
+	// SaveWatchlist stores a watchlist, replacing any with the same ID
+	SaveWatchlist(ctx context.Context, id string, watchlist []byte) error
+
+	// GetWatchlists retrieves every watchlist
+	GetWatchlists(ctx context.Context) ([][]byte, error)
+
+	// DeleteWatchlist removes a watchlist
+	DeleteWatchlist(ctx context.Context, id string) (bool, error)
+
+	// SaveAPIKey stores an API key, replacing any with the same ID
+	SaveAPIKey(ctx context.Context, key APIKey) error
+
+	// GetAPIKeyByHash retrieves an API key by the hash of its secret
+	GetAPIKeyByHash(ctx context.Context, hash string) (APIKey, bool, error)
+
+	// GetAPIKeys retrieves every API key, revoked ones included, oldest first
+	GetAPIKeys(ctx context.Context) ([]APIKey, error)
+
+	// RevokeAPIKey marks an API key as revoked
+	RevokeAPIKey(ctx context.Context, id string, at time.Time) (bool, error)
+
+	// AddAPIKeyUsage adds request counts to the stored usage counters
+	AddAPIKeyUsage(ctx context.Context, usage []APIKeyUsage) error
+
+	// GetAPIKeyUsage retrieves a key's usage counters from a day on
+	GetAPIKeyUsage(ctx context.Context, keyID string, since time.Time) ([]APIKeyUsage, error)
+
+	// IncrementRateLimit counts a request in a rate limit window and returns the window's count
+	IncrementRateLimit(ctx context.Context, key string, window time.Time, expiresAt time.Time) (int64, error)
+
+	// DeleteExpired removes expired articles, summaries and rate limit windows from storage
 	DeleteExpired(ctx context.Context) error
 
 	// Close closes the storage connection
 	Close() error
 }
+
+// Embedding is a stored article together with its encoded embedding vector.
+type Embedding struct {
+	ArticleID string
+	Article   []byte
+	Vector    []byte
+	CreatedAt time.Time
+}
+
+// Webhook delivery statuses.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// Delivery is a webhook payload and its delivery state.
+type Delivery struct {
+	ID             string
+	SubscriptionID string
+	Status         string
+	Payload        []byte
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastError      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// DeliveryAttempt is one logged attempt at a delivery. StatusCode is 0 when no
+// response was received.
+type DeliveryAttempt struct {
+	DeliveryID  string
+	Attempt     int
+	StatusCode  int
+	Error       string
+	Duration    time.Duration
+	AttemptedAt time.Time
+}
+
+// APIKey is a stored API key. Only the SHA-256 hash of its secret is kept;
+// Data holds the rest of the key as JSON. RevokedAt is nil for active keys.
+type APIKey struct {
+	ID        string
+	Hash      string
+	Data      []byte
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
+
+// APIKeyUsage counts the requests a key made to one endpoint on one day.
+type APIKeyUsage struct {
+	KeyID    string
+	Day      time.Time
+	Scope    string
+	Endpoint string
+	Requests int64
+}
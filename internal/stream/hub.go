@@ -0,0 +1,362 @@
+package stream
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/akhlexe/stocknews-api/internal/news"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultHistory is the number of recent events kept for resuming.
+	DefaultHistory = 1000
+
+	// DefaultBuffer is the number of events a subscriber may fall behind
+	// before it is disconnected.
+	DefaultBuffer = 64
+
+	// DefaultRefreshInterval is how often subscribed tickers are refetched.
+	// Upstream is only called when the article cache has expired.
+	DefaultRefreshInterval = time.Minute
+
+	// DefaultSeenTTL is how long an article that is no longer fetched is
+	// remembered. It outlasts any provider's news window, so articles are
+	// forgotten only once they can't come back.
+	DefaultSeenTTL = 7 * 24 * time.Hour
+
+	refreshTimeout = 10 * time.Second
+)
+
+// Event announces an article seen for the first time. IDs increase by one
+// per event and are used as SSE event IDs.
+type Event struct {
+	ID      uint64         `json:"id"`
+	Ticker  string         `json:"ticker"`
+	Article models.Article `json:"article"`
+}
+
+// Subscription receives events for a set of tickers, or for every ticker when
+// the set is empty. Events is closed when the
+// subscriber unsubscribes or falls more than the buffer size behind; see
+// Hub.Dropped.
+type Subscription struct {
+	Events  <-chan Event
+	events  chan Event
+	tickers map[string]bool
+	dropped bool
+}
+
+// Options configures a Hub. Zero values use the defaults.
+type Options struct {
+	History         int
+	Buffer          int
+	RefreshInterval time.Duration
+	SeenTTL         time.Duration
+}
+
+// Hub detects new articles in fetched article sets and fans them out to
+// subscribers. It implements news.Observer.
+type Hub struct {
+	opts Options
+
+	mu          sync.Mutex
+	nextID      uint64
+	seen        map[string]*seenArticles
+	history     []Event
+	subscribers map[*Subscription]bool
+	tracked     map[string][]string
+	wake        chan string
+}
+
+// seenArticles holds when each article of a ticker was last fetched.
+type seenArticles struct {
+	observed time.Time
+	articles map[string]time.Time
+}
+
+func NewHub(opts Options) *Hub {
+	if opts.History <= 0 {
+		opts.History = DefaultHistory
+	}
+	if opts.Buffer <= 0 {
+		opts.Buffer = DefaultBuffer
+	}
+	if opts.RefreshInterval <= 0 {
+		opts.RefreshInterval = DefaultRefreshInterval
+	}
+	if opts.SeenTTL <= 0 {
+		opts.SeenTTL = DefaultSeenTTL
+	}
+
+	return &Hub{
+		opts:        opts,
+		nextID:      1,
+		seen:        make(map[string]*seenArticles),
+		subscribers: make(map[*Subscription]bool),
+		tracked:     make(map[string][]string),
+		wake:        make(chan string, 16),
+	}
+}
+
+// Observe records the articles fetched for ticker and publishes those not
+// seen before. The first article set seen for a ticker is the baseline and
+// publishes nothing. Articles not fetched for SeenTTL are forgotten.
+func (h *Hub) Observe(ticker string, articles []models.Article) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	seen, known := h.seen[ticker]
+	if !known {
+		seen = &seenArticles{articles: make(map[string]time.Time, len(articles))}
+		h.seen[ticker] = seen
+	}
+	seen.observed = now
+
+	for _, a := range articles {
+		id := a.ID()
+		_, old := seen.articles[id]
+		seen.articles[id] = now
+		if !old && known {
+			h.publish(Event{Ticker: ticker, Article: a})
+		}
+	}
+
+	cutoff := now.Add(-h.opts.SeenTTL)
+	for id, last := range seen.articles {
+		if last.Before(cutoff) {
+			delete(seen.articles, id)
+		}
+	}
+}
+
+// forgetIdle drops the seen articles of tickers not fetched for SeenTTL. Their
+// next fetch is a new baseline.
+func (h *Hub) forgetIdle(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	cutoff := now.Add(-h.opts.SeenTTL)
+	for ticker, seen := range h.seen {
+		if seen.observed.Before(cutoff) {
+			delete(h.seen, ticker)
+		}
+	}
+}
+
+// publish assigns the next ID, stores the event and delivers it. The caller
+// holds mu.
+func (h *Hub) publish(event Event) {
+	event.ID = h.nextID
+	h.nextID++
+
+	h.history = append(h.history, event)
+	if len(h.history) > h.opts.History {
+		h.history = h.history[len(h.history)-h.opts.History:]
+	}
+
+	for sub := range h.subscribers {
+		if !sub.matches(event.Ticker) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			// A slow client must not hold up the others; it can reconnect
+			// with Last-Event-ID and catch up from history.
+			sub.dropped = true
+			h.remove(sub)
+			log.Warn().Str("ticker", event.Ticker).Msg("Dropped slow stream subscriber")
+		}
+	}
+}
+
+// Subscribe registers for events on tickers. When lastEventID is non-zero,
+// newer events still in history are delivered first.
+func (h *Hub) Subscribe(tickers []string, lastEventID uint64) *Subscription {
+	sub := &Subscription{
+		events:  make(chan Event, h.opts.Buffer),
+		tickers: make(map[string]bool, len(tickers)),
+	}
+	sub.Events = sub.events
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, t := range tickers {
+		sub.tickers[t] = true
+	}
+
+	if lastEventID > 0 {
+		var missed []Event
+		for _, event := range h.history {
+			if event.ID > lastEventID && sub.matches(event.Ticker) {
+				missed = append(missed, event)
+			}
+		}
+		// Replay at most a buffer's worth, keeping the most recent events.
+		if len(missed) > h.opts.Buffer {
+			missed = missed[len(missed)-h.opts.Buffer:]
+		}
+		for _, event := range missed {
+			sub.events <- event
+		}
+	}
+
+	h.subscribers[sub] = true
+	h.wakeUnknown(tickers)
+
+	return sub
+}
+
+func (s *Subscription) matches(ticker string) bool {
+	return len(s.tickers) == 0 || s.tickers[ticker]
+}
+
+// Track replaces the tickers refreshed on behalf of owner, for consumers that
+// subscribe to every ticker but only care about some of them. A ticker listed
+// more than once is refreshed ahead of less wanted ones.
+func (h *Hub) Track(owner string, tickers []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(tickers) == 0 {
+		delete(h.tracked, owner)
+		return
+	}
+	h.tracked[owner] = append([]string(nil), tickers...)
+	h.wakeUnknown(tickers)
+}
+
+// wakeUnknown asks Run to fetch tickers without a baseline yet. The caller
+// holds mu.
+func (h *Hub) wakeUnknown(tickers []string) {
+	for _, t := range tickers {
+		if _, known := h.seen[t]; !known {
+			select {
+			case h.wake <- t:
+			default:
+			}
+		}
+	}
+}
+
+// Dropped reports whether sub was closed for falling behind.
+func (h *Hub) Dropped(sub *Subscription) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return sub.dropped
+}
+
+// Unsubscribe stops delivery and closes the subscription's channel.
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(sub)
+}
+
+func (h *Hub) remove(sub *Subscription) {
+	if h.subscribers[sub] {
+		delete(h.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// Consume calls handle for every event of sub until ctx is done, then
+// unsubscribes. When sub is dropped for falling behind, Consume resubscribes to
+// the same tickers and resumes after the last event handled.
+func (h *Hub) Consume(ctx context.Context, sub *Subscription, handle func(Event)) {
+	var lastID uint64
+	for {
+		select {
+		case <-ctx.Done():
+			h.Unsubscribe(sub)
+			return
+		case event, open := <-sub.Events:
+			if open {
+				lastID = event.ID
+				handle(event)
+				continue
+			}
+			if !h.Dropped(sub) {
+				return
+			}
+			log.Warn().Uint64("last_event_id", lastID).Msg("Stream consumer fell behind; resuming")
+			tickers := make([]string, 0, len(sub.tickers))
+			for t := range sub.tickers {
+				tickers = append(tickers, t)
+			}
+			sub = h.Subscribe(tickers, lastID)
+		}
+	}
+}
+
+// LastEventID is the ID of the most recent event, or 0.
+func (h *Hub) LastEventID() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.nextID - 1
+}
+
+// Run refetches every subscribed and tracked ticker each refresh interval, and
+// new tickers as soon as they are subscribed, until ctx is done. Fetching through
+// fetcher is what calls Observe.
+func (h *Hub) Run(ctx context.Context, fetcher news.Provider) {
+	ticker := time.NewTicker(h.opts.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case symbol := <-h.wake:
+			h.refresh(ctx, fetcher, symbol)
+		case <-ticker.C:
+			for _, symbol := range h.subscribedTickers() {
+				h.refresh(ctx, fetcher, symbol)
+			}
+			h.forgetIdle(time.Now())
+		}
+	}
+}
+
+func (h *Hub) refresh(ctx context.Context, fetcher news.Provider, ticker string) {
+	ctx, cancel := context.WithTimeout(ctx, refreshTimeout)
+	defer cancel()
+
+	if _, err := fetcher.GetNewsByTicker(ctx, ticker); err != nil {
+		log.Warn().Err(err).Str("ticker", ticker).Msg("Failed to refresh streamed ticker")
+	}
+}
+
+// subscribedTickers returns the tickers to refresh, most wanted first: each
+// subscription and each tracked listing of a ticker counts once.
+func (h *Hub) subscribedTickers() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	demand := make(map[string]int)
+	var tickers []string
+	add := func(t string) {
+		if demand[t] == 0 {
+			tickers = append(tickers, t)
+		}
+		demand[t]++
+	}
+	for sub := range h.subscribers {
+		for t := range sub.tickers {
+			add(t)
+		}
+	}
+	for _, owned := range h.tracked {
+		for _, t := range owned {
+			add(t)
+		}
+	}
+	sort.SliceStable(tickers, func(i, j int) bool { return demand[tickers[i]] > demand[tickers[j]] })
+	return tickers
+}
@@ -0,0 +1,246 @@
+package stream
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func article(url string) models.Article {
+	return models.Article{Title: url, URL: "https://example.com/" + url}
+}
+
+func receive(t *testing.T, sub *Subscription) Event {
+	t.Helper()
+	select {
+	case event, open := <-sub.Events:
+		require.True(t, open, "subscription closed")
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return Event{}
+	}
+}
+
+func assertNoEvent(t *testing.T, sub *Subscription) {
+	t.Helper()
+	select {
+	case event := <-sub.Events:
+		t.Fatalf("unexpected event %+v", event)
+	default:
+	}
+}
+
+func TestHubPublishesOnlyNewArticles(t *testing.T) {
+	hub := NewHub(Options{})
+	sub := hub.Subscribe([]string{"AAPL"}, 0)
+	defer hub.Unsubscribe(sub)
+
+	// The first fetch is the baseline.
+	hub.Observe("AAPL", []models.Article{article("a"), article("b")})
+	assertNoEvent(t, sub)
+
+	hub.Observe("AAPL", []models.Article{article("c"), article("a"), article("b")})
+	event := receive(t, sub)
+	assert.Equal(t, uint64(1), event.ID)
+	assert.Equal(t, "AAPL", event.Ticker)
+	assert.Equal(t, "https://example.com/c", event.Article.URL)
+	assertNoEvent(t, sub)
+
+	// Other tickers aren't delivered.
+	hub.Observe("TSLA", []models.Article{article("x")})
+	hub.Observe("TSLA", []models.Article{article("y")})
+	assertNoEvent(t, sub)
+	assert.Equal(t, uint64(2), hub.LastEventID())
+}
+
+func TestHubForgetsArticlesNoLongerFetched(t *testing.T) {
+	hub := NewHub(Options{SeenTTL: 20 * time.Millisecond})
+
+	hub.Observe("AAPL", []models.Article{article("a")})
+	time.Sleep(30 * time.Millisecond)
+	hub.Observe("AAPL", []models.Article{article("b")})
+
+	hub.mu.Lock()
+	assert.Len(t, hub.seen["AAPL"].articles, 1, "a is past its TTL")
+	hub.mu.Unlock()
+
+	hub.forgetIdle(time.Now().Add(time.Hour))
+	hub.mu.Lock()
+	assert.Empty(t, hub.seen)
+	hub.mu.Unlock()
+
+	// A forgotten ticker starts over with a new baseline.
+	sub := hub.Subscribe([]string{"AAPL"}, 0)
+	defer hub.Unsubscribe(sub)
+	hub.Observe("AAPL", []models.Article{article("c")})
+	assertNoEvent(t, sub)
+}
+
+func TestHubResumesFromLastEventID(t *testing.T) {
+	hub := NewHub(Options{})
+	hub.Observe("AAPL", nil)
+	hub.Observe("TSLA", nil)
+	hub.Observe("AAPL", []models.Article{article("a")})
+	hub.Observe("TSLA", []models.Article{article("t")})
+	hub.Observe("AAPL", []models.Article{article("b")})
+
+	sub := hub.Subscribe([]string{"AAPL"}, 1)
+	defer hub.Unsubscribe(sub)
+
+	event := receive(t, sub)
+	assert.Equal(t, uint64(3), event.ID)
+	assert.Equal(t, "https://example.com/b", event.Article.URL)
+	assertNoEvent(t, sub)
+}
+
+func TestHubReplayKeepsMostRecentEvents(t *testing.T) {
+	hub := NewHub(Options{Buffer: 2})
+	hub.Observe("AAPL", nil)
+	for _, url := range []string{"a", "b", "c", "d"} {
+		hub.Observe("AAPL", []models.Article{article(url)})
+	}
+
+	// Events 2-4 were missed but only two fit the buffer.
+	sub := hub.Subscribe([]string{"AAPL"}, 1)
+	defer hub.Unsubscribe(sub)
+	assert.Equal(t, uint64(3), receive(t, sub).ID)
+	assert.Equal(t, uint64(4), receive(t, sub).ID)
+	assertNoEvent(t, sub)
+}
+
+func TestHubDropsSlowSubscriber(t *testing.T) {
+	hub := NewHub(Options{Buffer: 1})
+	hub.Observe("AAPL", nil)
+
+	slow := hub.Subscribe([]string{"AAPL"}, 0)
+	fast := hub.Subscribe([]string{"AAPL"}, 0)
+	defer hub.Unsubscribe(fast)
+
+	hub.Observe("AAPL", []models.Article{article("a")})
+	receive(t, fast)
+	hub.Observe("AAPL", []models.Article{article("b")})
+	receive(t, fast)
+
+	// The slow subscriber's buffered event is still readable, then it closes.
+	assert.Equal(t, uint64(1), receive(t, slow).ID)
+	_, open := <-slow.Events
+	assert.False(t, open)
+	assert.True(t, hub.Dropped(slow))
+	assert.False(t, hub.Dropped(fast))
+
+	// Unsubscribing after a drop is safe.
+	hub.Unsubscribe(slow)
+}
+
+type fakeFetcher struct {
+	mu      sync.Mutex
+	hub     *Hub
+	fetched []string
+}
+
+func (f *fakeFetcher) GetNewsByTicker(ctx context.Context, ticker string) ([]models.Article, error) {
+	f.mu.Lock()
+	f.fetched = append(f.fetched, ticker)
+	n := len(f.fetched)
+	f.mu.Unlock()
+
+	articles := []models.Article{article(ticker + strconv.Itoa(n))}
+	f.hub.Observe(ticker, articles)
+	return articles, nil
+}
+
+func (f *fakeFetcher) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.fetched)
+}
+
+func TestHubRunRefreshesSubscribedTickers(t *testing.T) {
+	hub := NewHub(Options{RefreshInterval: 20 * time.Millisecond})
+	fetcher := &fakeFetcher{hub: hub}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hub.Run(ctx, fetcher)
+
+	sub := hub.Subscribe([]string{"AAPL"}, 0)
+	defer hub.Unsubscribe(sub)
+
+	// The wake-up fetch sets the baseline; the next refresh finds a new article.
+	event := receive(t, sub)
+	assert.Equal(t, "AAPL", event.Ticker)
+	assert.GreaterOrEqual(t, fetcher.count(), 2)
+}
+
+func TestHubSubscribeToEveryTicker(t *testing.T) {
+	hub := NewHub(Options{})
+	sub := hub.Subscribe(nil, 0)
+	defer hub.Unsubscribe(sub)
+
+	hub.Observe("AAPL", nil)
+	hub.Observe("TSLA", nil)
+	hub.Observe("AAPL", []models.Article{article("a")})
+	hub.Observe("TSLA", []models.Article{article("t")})
+
+	assert.Equal(t, "AAPL", receive(t, sub).Ticker)
+	assert.Equal(t, "TSLA", receive(t, sub).Ticker)
+}
+
+func TestHubTrackRefreshesTickers(t *testing.T) {
+	hub := NewHub(Options{RefreshInterval: time.Hour})
+	fetcher := &fakeFetcher{hub: hub}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hub.Run(ctx, fetcher)
+
+	hub.Track("webhooks", []string{"MSFT"})
+	assert.Eventually(t, func() bool { return fetcher.count() == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"MSFT"}, hub.subscribedTickers())
+
+	hub.Track("webhooks", nil)
+	assert.Empty(t, hub.subscribedTickers())
+}
+
+func TestHubRefreshesMostWantedTickersFirst(t *testing.T) {
+	hub := NewHub(Options{})
+	sub := hub.Subscribe([]string{"TSLA"}, 0)
+	defer hub.Unsubscribe(sub)
+
+	hub.Track("watchlists", []string{"AAPL", "MSFT", "TSLA", "MSFT"})
+	hub.Track("alerts", []string{"MSFT"})
+
+	assert.Equal(t, []string{"MSFT", "TSLA", "AAPL"}, hub.subscribedTickers())
+}
+
+func TestHubConsumeResumesAfterDrop(t *testing.T) {
+	hub := NewHub(Options{Buffer: 1})
+	hub.Observe("AAPL", nil)
+	sub := hub.Subscribe([]string{"AAPL"}, 0)
+
+	// Overflow the buffer before consuming so the subscription is dropped.
+	hub.Observe("AAPL", []models.Article{article("a")})
+	hub.Observe("AAPL", []models.Article{article("b")})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	received := make(chan uint64, 10)
+	go hub.Consume(ctx, sub, func(event Event) { received <- event.ID })
+
+	for _, want := range []uint64{1, 2} {
+		select {
+		case id := <-received:
+			assert.Equal(t, want, id)
+		case <-time.After(time.Second):
+			t.Fatalf("event %d not consumed", want)
+		}
+	}
+}
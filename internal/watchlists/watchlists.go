@@ -0,0 +1,339 @@
+package watchlists
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/stream"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// MaxTickers caps the number of tickers in one watchlist.
+	MaxTickers = 100
+
+	// MaxNameLength caps the length of a watchlist name.
+	MaxNameLength = 100
+
+	// ReloadInterval is how often the watchlists are reread from the store,
+	// to pick up changes made by other processes.
+	ReloadInterval = time.Minute
+
+	// trackerName identifies the watched tickers to the stream hub.
+	trackerName = "watchlists"
+)
+
+var (
+	// ErrInvalidWatchlist is returned for a watchlist that can't be saved.
+	ErrInvalidWatchlist = errors.New("invalid watchlist")
+
+	// ErrNotFound is returned for an unknown watchlist ID.
+	ErrNotFound = errors.New("watchlist not found")
+)
+
+// Store persists watchlists.
+type Store interface {
+	SaveWatchlist(ctx context.Context, id string, watchlist []byte) error
+	GetWatchlists(ctx context.Context) ([][]byte, error)
+	GetWatchlist(ctx context.Context, id string) ([]byte, bool, error)
+	DeleteWatchlist(ctx context.Context, id string) (bool, error)
+}
+
+// Watchlist is a named set of tickers, kept in the order they were added.
+// KeyID is the API key that created it, empty without authentication.
+type Watchlist struct {
+	ID        string    `json:"id"`
+	KeyID     string    `json:"key_id,omitempty"`
+	Name      string    `json:"name"`
+	Tickers   []string  `json:"tickers"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Service manages watchlists and keeps their tickers refreshed by the stream
+// hub, so their news is fresh when it is asked for.
+type Service struct {
+	store Store
+	hub   *stream.Hub
+
+	mu    sync.RWMutex
+	lists map[string]Watchlist
+}
+
+func NewService(store Store, hub *stream.Hub) *Service {
+	return &Service{
+		store: store,
+		hub:   hub,
+		lists: make(map[string]Watchlist),
+	}
+}
+
+// Load reads the stored watchlists.
+func (s *Service) Load(ctx context.Context) error {
+	count, err := s.reload(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Int("count", count).Msg("Loaded watchlists")
+	return nil
+}
+
+// Run rereads the stored watchlists every ReloadInterval until ctx is done.
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.reload(ctx); err != nil {
+				log.Error().Err(err).Msg("Error reloading watchlists")
+			}
+		}
+	}
+}
+
+// reload replaces the watchlists with the stored ones and returns how many
+// there are.
+func (s *Service) reload(ctx context.Context) (int, error) {
+	stored, err := s.store.GetWatchlists(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	lists := make(map[string]Watchlist, len(stored))
+	for _, data := range stored {
+		var w Watchlist
+		if err := json.Unmarshal(data, &w); err != nil {
+			log.Warn().Err(err).Msg("Skipping stored watchlist")
+			continue
+		}
+		lists[w.ID] = w
+	}
+
+	s.mu.Lock()
+	s.lists = lists
+	s.mu.Unlock()
+
+	s.track()
+	return len(lists), nil
+}
+
+// Create validates and stores a new watchlist belonging to keyID.
+func (s *Service) Create(ctx context.Context, keyID, name string, tickers []string) (Watchlist, error) {
+	w := Watchlist{KeyID: keyID, Name: strings.TrimSpace(name), Tickers: uniqueTickers(tickers)}
+	if err := validate(w); err != nil {
+		return Watchlist{}, err
+	}
+
+	id, err := randomID()
+	if err != nil {
+		return Watchlist{}, err
+	}
+	w.ID = id
+	w.CreatedAt = time.Now().UTC()
+	w.UpdatedAt = w.CreatedAt
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.save(ctx, w); err != nil {
+		return Watchlist{}, err
+	}
+
+	log.Info().Str("watchlist_id", w.ID).Strs("tickers", w.Tickers).Msg("Watchlist created")
+	return w, nil
+}
+
+// List returns every watchlist, oldest first. Watchlists created or deleted
+// by other processes are listed from the next reload.
+func (s *Service) List() []Watchlist {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	lists := make([]Watchlist, 0, len(s.lists))
+	for _, w := range s.lists {
+		lists = append(lists, w)
+	}
+	sort.Slice(lists, func(i, j int) bool { return lists[i].CreatedAt.Before(lists[j].CreatedAt) })
+	return lists
+}
+
+// Get reads a watchlist from the store, as another process may have created,
+// changed or deleted it.
+func (s *Service) Get(ctx context.Context, id string) (Watchlist, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lookup(ctx, id)
+}
+
+// Replace overwrites the name and tickers of a watchlist.
+func (s *Service) Replace(ctx context.Context, id, name string, tickers []string) (Watchlist, error) {
+	return s.update(ctx, id, func(w *Watchlist) {
+		w.Name = strings.TrimSpace(name)
+		w.Tickers = uniqueTickers(tickers)
+	})
+}
+
+// AddTickers appends tickers the watchlist doesn't have yet.
+func (s *Service) AddTickers(ctx context.Context, id string, tickers []string) (Watchlist, error) {
+	return s.update(ctx, id, func(w *Watchlist) {
+		w.Tickers = uniqueTickers(append(w.Tickers, tickers...))
+	})
+}
+
+// RemoveTicker takes a ticker off the watchlist. Removing a ticker that isn't
+// on it is not an error.
+func (s *Service) RemoveTicker(ctx context.Context, id, ticker string) (Watchlist, error) {
+	return s.update(ctx, id, func(w *Watchlist) {
+		kept := make([]string, 0, len(w.Tickers))
+		for _, t := range w.Tickers {
+			if t != ticker {
+				kept = append(kept, t)
+			}
+		}
+		w.Tickers = kept
+	})
+}
+
+func (s *Service) Delete(ctx context.Context, id string) (bool, error) {
+	deleted, err := s.store.DeleteWatchlist(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	_, known := s.lists[id]
+	delete(s.lists, id)
+	s.mu.Unlock()
+	s.track()
+
+	return deleted || known, nil
+}
+
+// update applies change to a copy of the watchlist and stores the result if
+// it is still valid.
+func (s *Service) update(ctx context.Context, id string, change func(*Watchlist)) (Watchlist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, ok, err := s.lookup(ctx, id)
+	if err != nil {
+		return Watchlist{}, err
+	}
+	if !ok {
+		return Watchlist{}, ErrNotFound
+	}
+	w.Tickers = append([]string(nil), w.Tickers...)
+	change(&w)
+	if err := validate(w); err != nil {
+		return Watchlist{}, err
+	}
+	w.UpdatedAt = time.Now().UTC()
+
+	if err := s.save(ctx, w); err != nil {
+		return Watchlist{}, err
+	}
+	return w, nil
+}
+
+// lookup reads a watchlist from the store and updates the loaded copy, so
+// the hub tracks what is stored. The caller holds mu.
+func (s *Service) lookup(ctx context.Context, id string) (Watchlist, bool, error) {
+	data, ok, err := s.store.GetWatchlist(ctx, id)
+	if err != nil {
+		return Watchlist{}, false, err
+	}
+
+	var w Watchlist
+	if ok {
+		if err := json.Unmarshal(data, &w); err != nil {
+			return Watchlist{}, false, err
+		}
+		s.lists[id] = w
+	} else {
+		delete(s.lists, id)
+	}
+	s.hub.Track(trackerName, s.watchedTickers())
+	return w, ok, nil
+}
+
+// save stores a watchlist and retracks the hub. The caller holds mu.
+func (s *Service) save(ctx context.Context, w Watchlist) error {
+	data, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+	if err := s.store.SaveWatchlist(ctx, w.ID, data); err != nil {
+		return err
+	}
+
+	s.lists[w.ID] = w
+	s.hub.Track(trackerName, s.watchedTickers())
+	return nil
+}
+
+// track tells the hub which tickers to keep refreshing for the watchlists.
+func (s *Service) track() {
+	s.mu.RLock()
+	tickers := s.watchedTickers()
+	s.mu.RUnlock()
+
+	s.hub.Track(trackerName, tickers)
+}
+
+// watchedTickers lists every watchlist's tickers, so a ticker on several
+// watchlists is listed once per watchlist and refreshed first. The caller
+// holds mu.
+func (s *Service) watchedTickers() []string {
+	var tickers []string
+	for _, w := range s.lists {
+		tickers = append(tickers, w.Tickers...)
+	}
+	return tickers
+}
+
+func validate(w Watchlist) error {
+	if w.Name == "" {
+		return fmt.Errorf("%w: no name", ErrInvalidWatchlist)
+	}
+	if len(w.Name) > MaxNameLength {
+		return fmt.Errorf("%w: name is longer than %d characters", ErrInvalidWatchlist, MaxNameLength)
+	}
+	if len(w.Tickers) > MaxTickers {
+		return fmt.Errorf("%w: more than %d tickers", ErrInvalidWatchlist, MaxTickers)
+	}
+	return nil
+}
+
+// uniqueTickers drops repeated tickers, keeping the first of each. It never
+// returns nil so an empty watchlist lists its tickers as [].
+func uniqueTickers(tickers []string) []string {
+	set := make(map[string]bool, len(tickers))
+	unique := make([]string, 0, len(tickers))
+	for _, t := range tickers {
+		if !set[t] {
+			set[t] = true
+			unique = append(unique, t)
+		}
+	}
+	return unique
+}
+
+func randomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,181 @@
+package watchlists
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/akhlexe/stocknews-api/internal/stream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	mu    sync.Mutex
+	lists map[string][]byte
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{lists: make(map[string][]byte)}
+}
+
+func (s *memoryStore) SaveWatchlist(ctx context.Context, id string, watchlist []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lists[id] = watchlist
+	return nil
+}
+
+func (s *memoryStore) GetWatchlists(ctx context.Context) ([][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var lists [][]byte
+	for _, data := range s.lists {
+		lists = append(lists, data)
+	}
+	return lists, nil
+}
+
+func (s *memoryStore) GetWatchlist(ctx context.Context, id string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.lists[id]
+	return data, ok, nil
+}
+
+func (s *memoryStore) DeleteWatchlist(ctx context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.lists[id]
+	delete(s.lists, id)
+	return ok, nil
+}
+
+func TestServiceLifecycle(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	service := NewService(store, stream.NewHub(stream.Options{}))
+
+	created, err := service.Create(ctx, "", " Tech ", []string{"AAPL", "MSFT", "AAPL"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, "Tech", created.Name)
+	assert.Equal(t, []string{"AAPL", "MSFT"}, created.Tickers)
+
+	updated, err := service.AddTickers(ctx, created.ID, []string{"MSFT", "NVDA"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AAPL", "MSFT", "NVDA"}, updated.Tickers)
+
+	updated, err = service.RemoveTicker(ctx, created.ID, "AAPL")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"MSFT", "NVDA"}, updated.Tickers)
+
+	updated, err = service.Replace(ctx, created.ID, "Chips", []string{"NVDA", "AMD"})
+	require.NoError(t, err)
+	assert.Equal(t, "Chips", updated.Name)
+	assert.Equal(t, []string{"NVDA", "AMD"}, updated.Tickers)
+	assert.Equal(t, created.CreatedAt, updated.CreatedAt)
+
+	reloaded := NewService(store, stream.NewHub(stream.Options{}))
+	require.NoError(t, reloaded.Load(ctx))
+	got, ok, err := reloaded.Get(ctx, created.ID)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, updated.Tickers, got.Tickers)
+
+	deleted, err := service.Delete(ctx, created.ID)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	assert.Empty(t, service.List())
+
+	deleted, err = service.Delete(ctx, created.ID)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	_, err = service.AddTickers(ctx, created.ID, []string{"TSLA"})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestServiceSeesWatchlistsChangedByReplicas(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	replica := NewService(store, stream.NewHub(stream.Options{}))
+	require.NoError(t, replica.Load(ctx))
+
+	// Created on another process after the replica loaded.
+	other := NewService(store, stream.NewHub(stream.Options{}))
+	created, err := other.Create(ctx, "", "Tech", []string{"AAPL"})
+	require.NoError(t, err)
+
+	got, ok, err := replica.Get(ctx, created.ID)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, []string{"AAPL"}, got.Tickers)
+
+	_, err = other.AddTickers(ctx, created.ID, []string{"MSFT"})
+	require.NoError(t, err)
+	updated, err := replica.AddTickers(ctx, created.ID, []string{"NVDA"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AAPL", "MSFT", "NVDA"}, updated.Tickers, "updates start from the stored watchlist")
+
+	_, err = other.Delete(ctx, created.ID)
+	require.NoError(t, err)
+	_, ok, err = replica.Get(ctx, created.ID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Empty(t, replica.List())
+
+	// Lists pick up other processes' watchlists on reload.
+	_, err = other.Create(ctx, "", "Dividends", []string{"KO"})
+	require.NoError(t, err)
+	count, err := replica.reload(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Len(t, replica.List(), 1)
+}
+
+func TestServiceRejectsInvalidWatchlists(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newMemoryStore(), stream.NewHub(stream.Options{}))
+
+	_, err := service.Create(ctx, "", "  ", []string{"AAPL"})
+	assert.ErrorIs(t, err, ErrInvalidWatchlist)
+
+	_, err = service.Create(ctx, "", strings.Repeat("x", MaxNameLength+1), nil)
+	assert.ErrorIs(t, err, ErrInvalidWatchlist)
+
+	created, err := service.Create(ctx, "", "Empty", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, created.Tickers)
+
+	tickers := make([]string, MaxTickers+1)
+	for i := range tickers {
+		tickers[i] = fmt.Sprintf("T%d", i)
+	}
+	_, err = service.AddTickers(ctx, created.ID, tickers)
+	assert.ErrorIs(t, err, ErrInvalidWatchlist)
+
+	got, _, err := service.Get(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.Tickers, "a rejected change must not be kept")
+}
+
+func TestServiceListsTickersOncePerWatchlist(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newMemoryStore(), stream.NewHub(stream.Options{}))
+
+	_, err := service.Create(ctx, "", "Tech", []string{"AAPL", "MSFT"})
+	require.NoError(t, err)
+	_, err = service.Create(ctx, "", "Dividends", []string{"MSFT", "KO"})
+	require.NoError(t, err)
+
+	service.mu.RLock()
+	tickers := service.watchedTickers()
+	service.mu.RUnlock()
+	sort.Strings(tickers)
+
+	assert.Equal(t, []string{"AAPL", "KO", "MSFT", "MSFT"}, tickers)
+}
@@ -0,0 +1,578 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/safehttp"
+	"github.com/akhlexe/stocknews-api/internal/storage"
+	"github.com/akhlexe/stocknews-api/internal/stream"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultWorkers        = 4
+	DefaultMaxAttempts    = 8
+	DefaultBaseBackoff    = 30 * time.Second
+	DefaultMaxBackoff     = time.Hour
+	DefaultTimeout        = 10 * time.Second
+	DefaultPollInterval   = 5 * time.Second
+	DefaultReloadInterval = time.Minute
+
+	// dueBatchSize bounds the deliveries attempted per poll.
+	dueBatchSize = 100
+
+	// claimMargin is added to the timeout to lease a claimed delivery, for
+	// the time spent around the request.
+	claimMargin = 30 * time.Second
+
+	// trackerName identifies the webhook tickers to the stream hub.
+	trackerName = "webhooks"
+
+	// maxDrainBody is how much of a response is read so the connection can
+	// be reused.
+	maxDrainBody = 512
+)
+
+// Store persists subscriptions, deliveries and the delivery log.
+type Store interface {
+	SaveSubscription(ctx context.Context, id string, subscription []byte) error
+	GetSubscriptions(ctx context.Context) ([][]byte, error)
+	DeleteSubscription(ctx context.Context, id string) (bool, error)
+	CreateDelivery(ctx context.Context, delivery storage.Delivery) (bool, error)
+	SaveDelivery(ctx context.Context, delivery storage.Delivery) error
+	ClaimDueDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]storage.Delivery, error)
+	GetDeliveries(ctx context.Context, subscriptionID string, status string, limit int) ([]storage.Delivery, error)
+	SaveDeliveryAttempt(ctx context.Context, attempt storage.DeliveryAttempt) error
+	GetDeliveryAttempts(ctx context.Context, deliveryID string) ([]storage.DeliveryAttempt, error)
+}
+
+// Options tunes delivery. Zero values use the defaults.
+type Options struct {
+	// Workers is the number of deliveries sent concurrently.
+	Workers int
+
+	// MaxAttempts is the number of attempts before a delivery is dead-lettered.
+	MaxAttempts int
+
+	// BaseBackoff is the wait after the first failed attempt; it doubles with
+	// every further failure up to MaxBackoff.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+
+	// Timeout bounds a single attempt.
+	Timeout time.Duration
+
+	// Client sends deliveries. The default refuses to connect to private
+	// addresses; see safehttp.
+	Client *http.Client
+
+	// PollInterval is how often due retries are looked for.
+	PollInterval time.Duration
+
+	// ReloadInterval is how often subscriptions are reread from the store,
+	// to pick up those created or deleted by other processes.
+	ReloadInterval time.Duration
+}
+
+// DeliveryLog is a delivery and its attempts, as shown to clients.
+type DeliveryLog struct {
+	ID            string          `json:"id"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at,omitempty"`
+	LastError     string          `json:"last_error,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	Payload       json.RawMessage `json:"payload"`
+	Log           []AttemptLog    `json:"log"`
+}
+
+// AttemptLog is one attempt at a delivery. StatusCode is 0 when no response
+// was received.
+type AttemptLog struct {
+	Attempt     int       `json:"attempt"`
+	StatusCode  int       `json:"status_code"`
+	Error       string    `json:"error,omitempty"`
+	DurationMS  int64     `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+// Service keeps webhook subscriptions, turns new articles from the stream hub
+// into deliveries, and sends them with retries.
+type Service struct {
+	store  Store
+	hub    *stream.Hub
+	opts   Options
+	client *http.Client
+
+	// events is subscribed on creation so nothing published before Run is
+	// missed.
+	events *stream.Subscription
+
+	mu            sync.RWMutex
+	subscriptions map[string]Subscription
+	wake          chan struct{}
+}
+
+func NewService(store Store, hub *stream.Hub, opts Options) *Service {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultWorkers
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.BaseBackoff <= 0 {
+		opts.BaseBackoff = DefaultBaseBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = DefaultMaxBackoff
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	if opts.ReloadInterval <= 0 {
+		opts.ReloadInterval = DefaultReloadInterval
+	}
+	if opts.Client == nil {
+		opts.Client = safehttp.NewClient(opts.Timeout)
+	}
+
+	return &Service{
+		store:         store,
+		hub:           hub,
+		opts:          opts,
+		client:        opts.Client,
+		events:        hub.Subscribe(nil, 0),
+		subscriptions: make(map[string]Subscription),
+		wake:          make(chan struct{}, 1),
+	}
+}
+
+// Load reads the stored subscriptions.
+func (s *Service) Load(ctx context.Context) error {
+	count, err := s.reload(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Int("count", count).Msg("Loaded webhook subscriptions")
+	return nil
+}
+
+// reload replaces the subscriptions with the stored ones, which other
+// processes may have changed, and returns how many there are.
+func (s *Service) reload(ctx context.Context) (int, error) {
+	stored, err := s.store.GetSubscriptions(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	subscriptions := make(map[string]Subscription, len(stored))
+	for _, data := range stored {
+		var sub Subscription
+		if err := json.Unmarshal(data, &sub); err != nil {
+			log.Warn().Err(err).Msg("Skipping stored webhook subscription")
+			continue
+		}
+		subscriptions[sub.ID] = sub
+	}
+
+	s.mu.Lock()
+	s.subscriptions = subscriptions
+	s.mu.Unlock()
+
+	s.track()
+	return len(subscriptions), nil
+}
+
+// Create validates and stores a subscription, generating its ID and, unless
+// the client chose one, its secret.
+func (s *Service) Create(ctx context.Context, sub Subscription) (Subscription, error) {
+	if err := ValidateURL(sub.URL); err != nil {
+		return Subscription{}, err
+	}
+	if len(sub.Tickers) == 0 {
+		return Subscription{}, fmt.Errorf("%w: no tickers", ErrInvalidSubscription)
+	}
+
+	var err error
+	if sub.Secret == "" {
+		if sub.Secret, err = randomID(32); err != nil {
+			return Subscription{}, err
+		}
+	} else if len(sub.Secret) < MinSecretLength {
+		return Subscription{}, fmt.Errorf("%w: secret must be at least %d characters", ErrInvalidSubscription, MinSecretLength)
+	}
+
+	if sub.ID, err = randomID(16); err != nil {
+		return Subscription{}, err
+	}
+	sub.CreatedAt = time.Now().UTC()
+
+	data, err := json.Marshal(sub)
+	if err != nil {
+		return Subscription{}, err
+	}
+	if err := s.store.SaveSubscription(ctx, sub.ID, data); err != nil {
+		return Subscription{}, err
+	}
+
+	s.mu.Lock()
+	s.subscriptions[sub.ID] = sub
+	s.mu.Unlock()
+	s.track()
+
+	log.Info().Str("subscription_id", sub.ID).Strs("tickers", sub.Tickers).Msg("Webhook subscription created")
+	return sub, nil
+}
+
+// List returns every subscription, oldest first.
+func (s *Service) List() []Subscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	subs := make([]Subscription, 0, len(s.subscriptions))
+	for _, sub := range s.subscriptions {
+		subs = append(subs, sub)
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].CreatedAt.Before(subs[j].CreatedAt) })
+	return subs
+}
+
+func (s *Service) Get(id string) (Subscription, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sub, ok := s.subscriptions[id]
+	return sub, ok
+}
+
+// Delete removes a subscription along with its deliveries.
+func (s *Service) Delete(ctx context.Context, id string) (bool, error) {
+	deleted, err := s.store.DeleteSubscription(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	_, known := s.subscriptions[id]
+	delete(s.subscriptions, id)
+	s.mu.Unlock()
+	s.track()
+
+	return deleted || known, nil
+}
+
+// Deliveries returns a subscription's most recent deliveries with their
+// attempts. status optionally restricts them, e.g. to storage.DeliveryDead.
+func (s *Service) Deliveries(ctx context.Context, subscriptionID string, status string, limit int) ([]DeliveryLog, error) {
+	deliveries, err := s.store.GetDeliveries(ctx, subscriptionID, status, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	logs := make([]DeliveryLog, 0, len(deliveries))
+	for _, d := range deliveries {
+		attempts, err := s.store.GetDeliveryAttempts(ctx, d.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := DeliveryLog{
+			ID:        d.ID,
+			Status:    d.Status,
+			Attempts:  d.Attempts,
+			LastError: d.LastError,
+			CreatedAt: d.CreatedAt,
+			UpdatedAt: d.UpdatedAt,
+			Payload:   d.Payload,
+			Log:       make([]AttemptLog, 0, len(attempts)),
+		}
+		if d.Status == storage.DeliveryPending {
+			next := d.NextAttemptAt
+			entry.NextAttemptAt = &next
+		}
+		for _, a := range attempts {
+			entry.Log = append(entry.Log, AttemptLog{
+				Attempt:     a.Attempt,
+				StatusCode:  a.StatusCode,
+				Error:       a.Error,
+				DurationMS:  a.Duration.Milliseconds(),
+				AttemptedAt: a.AttemptedAt,
+			})
+		}
+		logs = append(logs, entry)
+	}
+
+	return logs, nil
+}
+
+// Run queues a delivery for every new article matching a subscription and
+// sends due deliveries until ctx is done.
+func (s *Service) Run(ctx context.Context) {
+	go s.hub.Consume(ctx, s.events, func(event stream.Event) { s.enqueue(ctx, event) })
+
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+	reloadTicker := time.NewTicker(s.opts.ReloadInterval)
+	defer reloadTicker.Stop()
+
+	for {
+		s.deliverDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		case <-reloadTicker.C:
+			if _, err := s.reload(ctx); err != nil {
+				log.Error().Err(err).Msg("Error reloading webhook subscriptions")
+			}
+		}
+	}
+}
+
+// enqueue stores a delivery of event to every matching subscription. Every
+// process sees the same events, so the delivery ID is derived from the
+// subscription and article and only the first process to store it queues it.
+func (s *Service) enqueue(ctx context.Context, event stream.Event) {
+	s.mu.RLock()
+	var matching []Subscription
+	for _, sub := range s.subscriptions {
+		if sub.Matches(event.Ticker, event.Article) {
+			matching = append(matching, sub)
+		}
+	}
+	s.mu.RUnlock()
+
+	queued := false
+	for _, sub := range matching {
+		id := deliveryID(sub.ID, event.Article.ID())
+		now := time.Now().UTC()
+		payload, err := json.Marshal(Payload{
+			ID:             id,
+			Event:          EventArticleCreated,
+			SubscriptionID: sub.ID,
+			Ticker:         event.Ticker,
+			Article:        event.Article,
+			CreatedAt:      now,
+		})
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to encode webhook payload")
+			continue
+		}
+
+		delivery := storage.Delivery{
+			ID:             id,
+			SubscriptionID: sub.ID,
+			Status:         storage.DeliveryPending,
+			Payload:        payload,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		created, err := s.store.CreateDelivery(ctx, delivery)
+		if err != nil {
+			log.Error().Err(err).Str("delivery_id", id).Msg("Error saving webhook delivery")
+			continue
+		}
+		queued = queued || created
+	}
+
+	if queued {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// deliverDue attempts due deliveries, Workers at a time, up to dueBatchSize.
+// Each batch is claimed before it is sent, so a delivery is never sent by two
+// processes, or twice by one, at once.
+func (s *Service) deliverDue(ctx context.Context) {
+	for attempted := 0; attempted < dueBatchSize; {
+		now := time.Now().UTC()
+		due, err := s.store.ClaimDueDeliveries(ctx, now, now.Add(s.opts.Timeout+claimMargin), s.opts.Workers)
+		if err != nil || len(due) == 0 {
+			return
+		}
+
+		var wg sync.WaitGroup
+		for _, d := range due {
+			wg.Add(1)
+			go func(d storage.Delivery) {
+				defer wg.Done()
+				s.attempt(ctx, d)
+			}(d)
+		}
+		wg.Wait()
+
+		attempted += len(due)
+		if len(due) < s.opts.Workers {
+			return
+		}
+	}
+}
+
+// attempt sends a delivery once, logs the attempt and schedules the next one
+// or dead-letters the delivery.
+func (s *Service) attempt(ctx context.Context, d storage.Delivery) {
+	deliveryLog := log.With().Str("delivery_id", d.ID).Str("subscription_id", d.SubscriptionID).Logger()
+
+	sub, ok := s.Get(d.SubscriptionID)
+	if !ok {
+		// The subscription may have been created by another process since the
+		// last reload. If the store can't be read the claim lapses and the
+		// delivery is retried.
+		if _, err := s.reload(ctx); err != nil {
+			deliveryLog.Error().Err(err).Msg("Error reloading webhook subscriptions")
+			return
+		}
+		sub, ok = s.Get(d.SubscriptionID)
+	}
+	if !ok {
+		// The subscription was deleted after the delivery was queued.
+		d.Status = storage.DeliveryDead
+		d.LastError = "subscription deleted"
+		d.UpdatedAt = time.Now().UTC()
+		s.saveDelivery(ctx, deliveryLog, d)
+		return
+	}
+
+	d.Attempts++
+	started := time.Now()
+	statusCode, sendErr := s.send(ctx, sub, d)
+	now := time.Now().UTC()
+
+	attempt := storage.DeliveryAttempt{
+		DeliveryID:  d.ID,
+		Attempt:     d.Attempts,
+		StatusCode:  statusCode,
+		Duration:    now.Sub(started),
+		AttemptedAt: now,
+	}
+	if sendErr != nil {
+		attempt.Error = sendErr.Error()
+	}
+
+	d.UpdatedAt = now
+	switch {
+	case sendErr == nil:
+		d.Status = storage.DeliveryDelivered
+		d.LastError = ""
+		deliveryLog.Info().Int("attempt", d.Attempts).Msg("Webhook delivered")
+	case d.Attempts >= s.opts.MaxAttempts:
+		d.Status = storage.DeliveryDead
+		d.LastError = attempt.Error
+		deliveryLog.Warn().Err(sendErr).Int("attempt", d.Attempts).Msg("Webhook delivery dead-lettered")
+	default:
+		d.LastError = attempt.Error
+		d.NextAttemptAt = now.Add(s.backoff(d.Attempts))
+		deliveryLog.Warn().Err(sendErr).Int("attempt", d.Attempts).Time("next_attempt_at", d.NextAttemptAt).Msg("Webhook delivery failed")
+	}
+
+	// The attempt is logged before the state moves on, so a crash between the
+	// two can only repeat a logged attempt.
+	if err := s.store.SaveDeliveryAttempt(ctx, attempt); err != nil {
+		deliveryLog.Error().Err(err).Int("attempt", attempt.Attempt).Msg("Error saving webhook delivery attempt")
+	}
+	s.saveDelivery(ctx, deliveryLog, d)
+}
+
+func (s *Service) saveDelivery(ctx context.Context, deliveryLog zerolog.Logger, d storage.Delivery) {
+	if err := s.store.SaveDelivery(ctx, d); err != nil {
+		deliveryLog.Error().Err(err).Str("status", d.Status).Msg("Error saving webhook delivery")
+	}
+}
+
+// send posts the payload and treats any 2xx response as success. Response
+// bodies are never kept: the delivery log is shown to clients, and the URL may
+// be one they shouldn't read.
+func (s *Service) send(ctx context.Context, sub Subscription, d storage.Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "stocknews-api-webhooks")
+	req.Header.Set(HeaderID, d.ID)
+	req.Header.Set(HeaderEvent, EventArticleCreated)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, timestamp, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBody))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// backoff is the wait after the given failed attempt.
+func (s *Service) backoff(attempt int) time.Duration {
+	wait := s.opts.BaseBackoff
+	for i := 1; i < attempt && wait < s.opts.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > s.opts.MaxBackoff {
+		wait = s.opts.MaxBackoff
+	}
+	return wait
+}
+
+// track tells the hub which tickers to keep refreshing for subscriptions.
+func (s *Service) track() {
+	s.mu.RLock()
+	set := make(map[string]bool)
+	var tickers []string
+	for _, sub := range s.subscriptions {
+		for _, t := range sub.Tickers {
+			if !set[t] {
+				set[t] = true
+				tickers = append(tickers, t)
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	s.hub.Track(trackerName, tickers)
+}
+
+// deliveryID identifies the delivery of an article to a subscription.
+func deliveryID(subscriptionID, articleID string) string {
+	sum := sha256.Sum256([]byte(subscriptionID + "/" + articleID))
+	return hex.EncodeToString(sum[:16])
+}
+
+func randomID(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
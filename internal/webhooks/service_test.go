@@ -0,0 +1,435 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/akhlexe/stocknews-api/internal/storage"
+	"github.com/akhlexe/stocknews-api/internal/stream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	mu            sync.Mutex
+	subscriptions map[string][]byte
+	deliveries    map[string]storage.Delivery
+	attempts      map[string][]storage.DeliveryAttempt
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		subscriptions: make(map[string][]byte),
+		deliveries:    make(map[string]storage.Delivery),
+		attempts:      make(map[string][]storage.DeliveryAttempt),
+	}
+}
+
+func (s *memoryStore) SaveSubscription(ctx context.Context, id string, subscription []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscriptions[id] = subscription
+	return nil
+}
+
+func (s *memoryStore) GetSubscriptions(ctx context.Context) ([][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var subs [][]byte
+	for _, data := range s.subscriptions {
+		subs = append(subs, data)
+	}
+	return subs, nil
+}
+
+func (s *memoryStore) DeleteSubscription(ctx context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.subscriptions[id]
+	delete(s.subscriptions, id)
+	return ok, nil
+}
+
+func (s *memoryStore) CreateDelivery(ctx context.Context, delivery storage.Delivery) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.deliveries[delivery.ID]; ok {
+		return false, nil
+	}
+	s.deliveries[delivery.ID] = delivery
+	return true, nil
+}
+
+func (s *memoryStore) SaveDelivery(ctx context.Context, delivery storage.Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+func (s *memoryStore) ClaimDueDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]storage.Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []storage.Delivery
+	for id, d := range s.deliveries {
+		if d.Status == storage.DeliveryPending && !d.NextAttemptAt.After(now) && len(due) < limit {
+			d.NextAttemptAt = leaseUntil
+			s.deliveries[id] = d
+			due = append(due, d)
+		}
+	}
+	return due, nil
+}
+
+func (s *memoryStore) GetDeliveries(ctx context.Context, subscriptionID string, status string, limit int) ([]storage.Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var deliveries []storage.Delivery
+	for _, d := range s.deliveries {
+		if d.SubscriptionID == subscriptionID && (status == "" || d.Status == status) {
+			deliveries = append(deliveries, d)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt) })
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+func (s *memoryStore) SaveDeliveryAttempt(ctx context.Context, attempt storage.DeliveryAttempt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts[attempt.DeliveryID] = append(s.attempts[attempt.DeliveryID], attempt)
+	return nil
+}
+
+func (s *memoryStore) GetDeliveryAttempts(ctx context.Context, deliveryID string) ([]storage.DeliveryAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts[deliveryID], nil
+}
+
+// receiver records webhook requests and fails the first failures of them.
+type receiver struct {
+	mu       sync.Mutex
+	failures int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	if len(r.requests) <= r.failures {
+		http.Error(w, "try later", http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+func testOptions() Options {
+	return Options{BaseBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond, PollInterval: 5 * time.Millisecond}
+}
+
+// testHookURL is the subscription URL of the tests; clientFor routes it to a
+// test server, as private addresses can't be subscribed to.
+const testHookURL = "http://hooks.example.com/news"
+
+// clientFor returns a client connecting to server whatever the URL.
+func clientFor(server *httptest.Server) *http.Client {
+	addr := server.Listener.Addr().String()
+	return &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}}
+}
+
+// startService creates a subscription to AAPL on a running service delivering
+// to server and sets the AAPL baseline, so the next observed article is
+// delivered.
+func startService(t *testing.T, server *httptest.Server, opts Options) (*Service, *stream.Hub, *memoryStore, Subscription) {
+	t.Helper()
+
+	hub := stream.NewHub(stream.Options{})
+	store := newMemoryStore()
+	opts.Client = clientFor(server)
+	service := NewService(store, hub, opts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go service.Run(ctx)
+
+	sub, err := service.Create(context.Background(), Subscription{URL: testHookURL, Tickers: []string{"AAPL"}})
+	require.NoError(t, err)
+
+	hub.Observe("AAPL", nil)
+	return service, hub, store, sub
+}
+
+func waitForStatus(t *testing.T, service *Service, sub Subscription, status string) DeliveryLog {
+	t.Helper()
+	var found DeliveryLog
+	require.Eventually(t, func() bool {
+		logs, err := service.Deliveries(context.Background(), sub.ID, status, 10)
+		if err != nil || len(logs) == 0 {
+			return false
+		}
+		found = logs[0]
+		return true
+	}, 2*time.Second, 5*time.Millisecond)
+	return found
+}
+
+func TestServiceDeliversSignedPayload(t *testing.T) {
+	recv := &receiver{}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	service, hub, _, sub := startService(t, server, testOptions())
+	assert.Len(t, sub.Secret, 64)
+
+	article := models.Article{Title: "Apple launches", URL: "https://example.com/launch"}
+	hub.Observe("AAPL", []models.Article{article})
+	hub.Observe("MSFT", nil)
+	hub.Observe("MSFT", []models.Article{{Title: "Not subscribed", URL: "https://example.com/msft"}})
+
+	delivery := waitForStatus(t, service, sub, storage.DeliveryDelivered)
+	assert.Equal(t, 1, delivery.Attempts)
+	require.Len(t, delivery.Log, 1)
+	assert.Equal(t, http.StatusNoContent, delivery.Log[0].StatusCode)
+	require.Equal(t, 1, recv.count())
+
+	req, body := recv.requests[0], recv.bodies[0]
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, delivery.ID, req.Header.Get(HeaderID))
+	assert.Equal(t, EventArticleCreated, req.Header.Get(HeaderEvent))
+
+	timestamp, err := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+	require.NoError(t, err)
+	assert.True(t, Verify(sub.Secret, timestamp, body, req.Header.Get(HeaderSignature)))
+
+	var payload Payload
+	require.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, delivery.ID, payload.ID)
+	assert.Equal(t, sub.ID, payload.SubscriptionID)
+	assert.Equal(t, "AAPL", payload.Ticker)
+	assert.Equal(t, article.URL, payload.Article.URL)
+}
+
+func TestServiceRetriesWithBackoff(t *testing.T) {
+	recv := &receiver{failures: 2}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	service, hub, _, sub := startService(t, server, testOptions())
+	hub.Observe("AAPL", []models.Article{{Title: "Apple", URL: "https://example.com/a"}})
+
+	delivery := waitForStatus(t, service, sub, storage.DeliveryDelivered)
+	assert.Equal(t, 3, delivery.Attempts)
+	require.Len(t, delivery.Log, 3)
+	assert.Equal(t, http.StatusServiceUnavailable, delivery.Log[0].StatusCode)
+	assert.Equal(t, "unexpected status 503", delivery.Log[0].Error, "response bodies are not kept")
+	assert.Equal(t, http.StatusNoContent, delivery.Log[2].StatusCode)
+	assert.Empty(t, delivery.LastError)
+
+	// Every attempt carries the same delivery ID.
+	for _, req := range recv.requests {
+		assert.Equal(t, delivery.ID, req.Header.Get(HeaderID))
+	}
+}
+
+func TestServiceDeadLettersAfterMaxAttempts(t *testing.T) {
+	recv := &receiver{failures: 100}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	opts := testOptions()
+	opts.MaxAttempts = 3
+	service, hub, _, sub := startService(t, server, opts)
+	hub.Observe("AAPL", []models.Article{{Title: "Apple", URL: "https://example.com/a"}})
+
+	delivery := waitForStatus(t, service, sub, storage.DeliveryDead)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Len(t, delivery.Log, 3)
+	assert.Contains(t, delivery.LastError, "unexpected status 503")
+	assert.Nil(t, delivery.NextAttemptAt)
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, 3, recv.count())
+}
+
+func TestServiceBackoff(t *testing.T) {
+	service := NewService(newMemoryStore(), stream.NewHub(stream.Options{}), Options{BaseBackoff: time.Second, MaxBackoff: 5 * time.Second})
+
+	assert.Equal(t, time.Second, service.backoff(1))
+	assert.Equal(t, 2*time.Second, service.backoff(2))
+	assert.Equal(t, 4*time.Second, service.backoff(3))
+	assert.Equal(t, 5*time.Second, service.backoff(4))
+	assert.Equal(t, 5*time.Second, service.backoff(40))
+}
+
+func TestServiceCreateLoadDelete(t *testing.T) {
+	store := newMemoryStore()
+	hub := stream.NewHub(stream.Options{})
+	service := NewService(store, hub, Options{})
+
+	_, err := service.Create(context.Background(), Subscription{URL: "not a url", Tickers: []string{"AAPL"}})
+	assert.ErrorIs(t, err, ErrInvalidSubscription)
+	_, err = service.Create(context.Background(), Subscription{URL: "https://example.com", Tickers: []string{"AAPL"}, Secret: "short"})
+	assert.ErrorIs(t, err, ErrInvalidSubscription)
+
+	sub, err := service.Create(context.Background(), Subscription{
+		URL:     "https://example.com/hook",
+		Tickers: []string{"AAPL"},
+		Secret:  "a-client-chosen-secret",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "a-client-chosen-secret", sub.Secret)
+
+	// A restarted service loads the stored subscription.
+	restarted := NewService(store, hub, Options{})
+	require.NoError(t, restarted.Load(context.Background()))
+	loaded, ok := restarted.Get(sub.ID)
+	require.True(t, ok)
+	assert.Equal(t, sub.URL, loaded.URL)
+	assert.Equal(t, sub.Secret, loaded.Secret)
+
+	deleted, err := restarted.Delete(context.Background(), sub.ID)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	assert.Empty(t, restarted.List())
+
+	deleted, err = restarted.Delete(context.Background(), sub.ID)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+}
+
+func TestServiceRefusesPrivateAddresses(t *testing.T) {
+	recv := &receiver{}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	// A stored subscription to a loopback address, which Create would refuse.
+	store := newMemoryStore()
+	data, err := json.Marshal(Subscription{ID: "internal", URL: server.URL, Tickers: []string{"AAPL"}, Secret: "a-client-chosen-secret"})
+	require.NoError(t, err)
+	store.subscriptions["internal"] = data
+
+	hub := stream.NewHub(stream.Options{})
+	opts := testOptions()
+	opts.MaxAttempts = 1
+	service := NewService(store, hub, opts)
+	require.NoError(t, service.Load(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go service.Run(ctx)
+
+	hub.Observe("AAPL", nil)
+	hub.Observe("AAPL", []models.Article{{Title: "Apple", URL: "https://example.com/a"}})
+
+	sub, _ := service.Get("internal")
+	delivery := waitForStatus(t, service, sub, storage.DeliveryDead)
+	assert.Contains(t, delivery.LastError, "address is not public")
+	assert.Zero(t, recv.count())
+}
+
+func TestServiceReplicasDeliverOnce(t *testing.T) {
+	recv := &receiver{}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	// Two replicas share the store and both see every article.
+	service, hub, store, sub := startService(t, server, testOptions())
+	replicaHub := stream.NewHub(stream.Options{})
+	opts := testOptions()
+	opts.Client = clientFor(server)
+	replica := NewService(store, replicaHub, opts)
+	require.NoError(t, replica.Load(context.Background()))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go replica.Run(ctx)
+
+	replicaHub.Observe("AAPL", nil)
+	article := models.Article{Title: "Apple", URL: "https://example.com/a"}
+	hub.Observe("AAPL", []models.Article{article})
+	replicaHub.Observe("AAPL", []models.Article{article})
+
+	delivery := waitForStatus(t, service, sub, storage.DeliveryDelivered)
+	assert.Equal(t, deliveryID(sub.ID, article.ID()), delivery.ID)
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1, recv.count())
+	logs, err := service.Deliveries(context.Background(), sub.ID, "", 10)
+	require.NoError(t, err)
+	assert.Len(t, logs, 1)
+}
+
+func TestServiceSeesSubscriptionsChangedByReplicas(t *testing.T) {
+	recv := &receiver{}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	// The replica loads before the subscription is created elsewhere, and so
+	// only learns of it from the store.
+	store := newMemoryStore()
+	opts := testOptions()
+	opts.Client = clientFor(server)
+	opts.ReloadInterval = 10 * time.Millisecond
+	replica := NewService(store, stream.NewHub(stream.Options{}), opts)
+	require.NoError(t, replica.Load(context.Background()))
+
+	other := NewService(store, stream.NewHub(stream.Options{}), testOptions())
+	sub, err := other.Create(context.Background(), Subscription{URL: testHookURL, Tickers: []string{"AAPL"}})
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	_, err = store.CreateDelivery(context.Background(), storage.Delivery{
+		ID:             "created-elsewhere",
+		SubscriptionID: sub.ID,
+		Status:         storage.DeliveryPending,
+		Payload:        []byte(`{}`),
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go replica.Run(ctx)
+
+	delivery := waitForStatus(t, replica, sub, storage.DeliveryDelivered)
+	assert.Equal(t, "created-elsewhere", delivery.ID)
+	assert.Equal(t, 1, recv.count())
+
+	// A deletion elsewhere is picked up on reload.
+	_, err = other.Delete(context.Background(), sub.ID)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		_, ok := replica.Get(sub.ID)
+		return !ok
+	}, 2*time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,141 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/akhlexe/stocknews-api/internal/safehttp"
+)
+
+// EventArticleCreated is the event of every delivery: an article matching the
+// subscription was seen for the first time.
+const EventArticleCreated = "article.created"
+
+// Headers set on every delivery. The signature is "sha256=" followed by the
+// hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the subscription secret.
+const (
+	HeaderID        = "X-Webhook-ID"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// MinSecretLength is the shortest secret a client may choose.
+const MinSecretLength = 16
+
+// ErrInvalidSubscription is returned for a subscription that can't be saved.
+var ErrInvalidSubscription = errors.New("invalid webhook subscription")
+
+// Subscription asks for articles on its tickers that pass its filters to be
+// posted to URL. Secret is only shown to the client when it is created.
+// KeyID is the API key that created it, empty without authentication.
+type Subscription struct {
+	ID        string    `json:"id"`
+	KeyID     string    `json:"key_id,omitempty"`
+	URL       string    `json:"url"`
+	Tickers   []string  `json:"tickers"`
+	Filters   Filters   `json:"filters"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Filters narrow down the articles delivered. Each non-empty filter must
+// match; matching is case-insensitive.
+type Filters struct {
+	// Sentiment lists the sentiment labels to deliver.
+	Sentiment []string `json:"sentiment,omitempty"`
+
+	// Sources lists the publishers to deliver.
+	Sources []string `json:"sources,omitempty"`
+
+	// Keywords delivers articles whose title or summary contains any of them.
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+// Payload is the JSON body of a delivery. ID is the delivery ID; it is the
+// same for an article and subscription across retries and server processes,
+// so receivers can drop duplicates.
+type Payload struct {
+	ID             string         `json:"id"`
+	Event          string         `json:"event"`
+	SubscriptionID string         `json:"subscription_id"`
+	Ticker         string         `json:"ticker"`
+	Article        models.Article `json:"article"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+// Redacted returns the subscription without its secret.
+func (s Subscription) Redacted() Subscription {
+	s.Secret = ""
+	return s
+}
+
+// Matches reports whether an article seen for ticker should be delivered.
+func (s Subscription) Matches(ticker string, a models.Article) bool {
+	if !containsFold(s.Tickers, ticker) {
+		return false
+	}
+	if len(s.Filters.Sentiment) > 0 && !containsFold(s.Filters.Sentiment, a.Sentiment) {
+		return false
+	}
+	if len(s.Filters.Sources) > 0 && !containsFold(s.Filters.Sources, a.Source) {
+		return false
+	}
+	if len(s.Filters.Keywords) > 0 {
+		text := strings.ToLower(a.Title + " " + a.Summary)
+		for _, k := range s.Filters.Keywords {
+			if strings.Contains(text, strings.ToLower(k)) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// ValidateURL checks that a webhook URL is an absolute http or https URL
+// that doesn't name a local or private address outright. Hostnames are
+// checked again when delivering, once they are resolved.
+func ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSubscription, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidSubscription)
+	}
+	if err := safehttp.CheckHost(u.Hostname()); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSubscription, err)
+	}
+	return nil
+}
+
+// Sign returns the signature header value for a delivery body.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is valid for body, for receivers written
+// in Go.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package webhooks
+
+import (
+	"testing"
+
+	"github.com/akhlexe/stocknews-api/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscriptionMatches(t *testing.T) {
+	article := models.Article{
+		Title:     "Apple recalls chargers",
+		Summary:   "A safety recall covers millions of units.",
+		Source:    "Reuters",
+		Sentiment: "Somewhat-Bearish",
+	}
+
+	tests := []struct {
+		name    string
+		filters Filters
+		ticker  string
+		want    bool
+	}{
+		{"ticker only", Filters{}, "AAPL", true},
+		{"other ticker", Filters{}, "TSLA", false},
+		{"sentiment", Filters{Sentiment: []string{"somewhat-bearish", "Bearish"}}, "AAPL", true},
+		{"sentiment mismatch", Filters{Sentiment: []string{"Bullish"}}, "AAPL", false},
+		{"source", Filters{Sources: []string{"reuters"}}, "AAPL", true},
+		{"source mismatch", Filters{Sources: []string{"Bloomberg"}}, "AAPL", false},
+		{"keyword in summary", Filters{Keywords: []string{"earnings", "Safety"}}, "AAPL", true},
+		{"keyword mismatch", Filters{Keywords: []string{"earnings"}}, "AAPL", false},
+		{"all filters", Filters{Sentiment: []string{"Somewhat-Bearish"}, Sources: []string{"Reuters"}, Keywords: []string{"recall"}}, "AAPL", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := Subscription{Tickers: []string{"AAPL", "MSFT"}, Filters: tt.filters}
+			assert.Equal(t, tt.want, sub.Matches(tt.ticker, article))
+		})
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	assert.NoError(t, ValidateURL("https://hooks.example.com/news"))
+	assert.NoError(t, ValidateURL("http://93.184.216.34:9000/hook"))
+
+	for _, raw := range []string{
+		"", "example.com/hook", "ftp://example.com", "https://", "::",
+		"http://localhost:9000/hook", "http://api.localhost/hook", "http://127.0.0.1/hook",
+		"http://10.0.0.5/hook", "http://169.254.169.254/latest/meta-data", "http://[::1]/hook",
+	} {
+		assert.ErrorIs(t, ValidateURL(raw), ErrInvalidSubscription, raw)
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"id":"abc"}`)
+	signature := Sign("secret", 1700000000, body)
+
+	assert.Regexp(t, `^sha256=[0-9a-f]{64}$`, signature)
+	assert.True(t, Verify("secret", 1700000000, body, signature))
+	assert.False(t, Verify("other", 1700000000, body, signature))
+	assert.False(t, Verify("secret", 1700000001, body, signature))
+	assert.False(t, Verify("secret", 1700000000, []byte(`{"id":"abd"}`), signature))
+}
+
+func TestRedacted(t *testing.T) {
+	sub := Subscription{ID: "1", Secret: "s3cr3t"}
+	assert.Empty(t, sub.Redacted().Secret)
+	assert.Equal(t, "s3cr3t", sub.Secret)
+}